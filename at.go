@@ -2,14 +2,20 @@ package at
 
 import (
 	"bufio"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/xlab/at/calls"
 	"github.com/xlab/at/pdu"
 	"github.com/xlab/at/sms"
+	"github.com/xlab/at/util"
 )
 
 // DefaultTimeout to close the connection in case of modem is being not responsive at all.
@@ -23,13 +29,17 @@ const Sub = "\x1A"
 
 // Common errors.
 var (
-	ErrTimeout         = errors.New("at: timeout")
-	ErrUnknownEncoding = errors.New("at: unsupported encoding")
-	ErrClosed          = errors.New("at: device ports are closed")
-	ErrNotInitialized  = errors.New("at: not initialized")
-	ErrWriteFailed     = errors.New("at: write failed")
-	ErrParseReport     = errors.New("at: error while parsing report")
-	ErrUnknownReport   = errors.New("at: got unknown report")
+	ErrTimeout           = errors.New("at: timeout")
+	ErrUnknownEncoding   = errors.New("at: unsupported encoding")
+	ErrClosed            = errors.New("at: device ports are closed")
+	ErrNotInitialized    = errors.New("at: not initialized")
+	ErrWriteFailed       = errors.New("at: write failed")
+	ErrParseReport       = errors.New("at: error while parsing report")
+	ErrUnknownReport     = errors.New("at: got unknown report")
+	ErrUnsupported       = errors.New("at: not supported by the device profile")
+	ErrDeviceLost        = errors.New("at: device lost")
+	ErrUnsupportedOption = errors.New("at: option is not supported by the device")
+	ErrTruncated         = errors.New("at: PDU is shorter than its declared length")
 )
 
 // Encoding is an encoding option to use.
@@ -55,18 +65,155 @@ type Device struct {
 	State *DeviceState
 	// Commands is a profile that provides implementation of Init and the other commands.
 	Commands DeviceProfile
-	// Timeout to override the default timeout (1m)
+	// Timeout to override the default timeout (1m), the profile may define
+	// different timeouts for particular commands.
 	Timeout time.Duration
+	// Dial is used to open the ports by their names, defaults to DialSerial,
+	// or the SerialConfig dialer if the BaudRate or FlowControl is set.
+	Dial Dialer
+	// BaudRate of the serial ports, if not set the ports are used as configured by the OS.
+	// AutoBaud makes Open detect the rate of the command port, see DetectBaudRate.
+	BaudRate int
+	// FlowControl enables the RTS/CTS hardware flow control of the serial ports.
+	FlowControl bool
+	// Watchdog enables the automatic recovery of the device, if set.
+	Watchdog *Watchdog
+	// FailurePolicy invokes a callback on repeated failures, if set.
+	FailurePolicy *FailurePolicy
+	// CommandDelay is the minimum delay between the commands, for the modules
+	// that drop the commands sent back-to-back.
+	CommandDelay time.Duration
+	// Retry enables the retries of the commands failed with ERROR, if set.
+	Retry *RetryPolicy
+	// UnsupportedLimit blacklists the commands replied with COMMAND NOT SUPPORT or
+	// +CME ERROR: 4 this many times in a row, then they fail with ErrUnsupported without
+	// being sent until ClearBlacklist. Disabled if zero.
+	UnsupportedLimit int
+	// Protected blocks the destructive commands with ErrBlocked, except the operations
+	// listed in Allowed. It guards the monitoring deployments from the accidental data loss.
+	Protected bool
+	Allowed   []StringOpt
+	// Routes constrain the sending of the messages by their destination: the quiet hours
+	// and the daily limits, the deferred messages fail with a DeferredError.
+	Routes []SendRoute
+	// ReassemblyTimeout limits the wait for the missing parts of a concatenated message,
+	// then the received parts are delivered separately. DefaultReassemblyTimeout if zero,
+	// negative disables the reassembly.
+	ReassemblyTimeout time.Duration
+	// DuplicateWindow drops the incoming messages identical to one received within the window,
+	// i.e. re-delivered after an unacknowledged +CMT or a modem restart. Disabled if zero.
+	DuplicateWindow time.Duration
+	// MessageStore persists the incoming messages until they are marked as processed
+	// with MarkProcessed, the messages are kept in memory only if nil.
+	MessageStore MessageStore
+	// Classifier decides whether the incoming messages are delivered, tagged, diverted or
+	// dropped, i.e. to filter the spam. ClassifyAsync runs it in the background.
+	Classifier    Classifier
+	ClassifyAsync bool
+	// Hold holds the messages while the modem has no service and sends them when
+	// it returns, the messages fail right away if nil.
+	Hold *HoldPolicy
+	// IndicationPolicy controls the fetching of the messages indicated with +CMTI,
+	// IndicationPolicies.Fetch if empty. IndicationDelay is the delay of the batch fetch,
+	// DefaultIndicationDelay if zero.
+	IndicationPolicy StringOpt
+	IndicationDelay  time.Duration
+	// NotifyTee receives a copy of the raw stream of the notification port, i.e. to archive
+	// the vendor reports that are not handled. Its write errors are ignored.
+	NotifyTee io.Writer
 
-	cmdPort    *os.File
-	notifyPort *os.File
+	cmdPort    port
+	cmdReader  *bufio.Reader
+	cmdMux     cmdQueue
+	notifyPort port
+	// lastCommand is the time when the previous command has completed.
+	lastCommand time.Time
+	// terminators are registered by the profile, see Terminator.
+	terminators []Terminator
+	// dataSession is set while the command port is owned by a DataConn, the reports
+	// that need the command port are queued in dataReports until it's closed.
+	dataSession bool
+	dataReports []string
+	dataMux     sync.Mutex
 
 	incomingCallerIDs chan *calls.CallerID
 	messages          chan *sms.Message
 	ussd              chan Ussd
 	updated           chan struct{}
+	reconnected       chan struct{}
+	failures          chan error
+	deliveries        chan Delivery
+	indications       chan MessageIndication
+	recordings        chan Recording
+	lostEvents        chan DeviceLost
+	jamming           chan Jamming
+	broadcasts        chan *sms.CellBroadcast
+	holdEvents        chan HoldEvent
+	voicemail         chan VoicemailWaiting
+	diverted          chan DivertedMessage
+	lifecycleEvents   chan LifecycleEvent
 	closed            chan struct{}
+	sessionCtx        context.Context
+	sessionCancel     context.CancelFunc
 
+	subs    []subscription
+	subsMux sync.Mutex
+
+	awake    chan struct{}
+	powerMux sync.Mutex
+	wakeMux  sync.Mutex
+
+	lastUnlock time.Time
+	unlockMux  sync.Mutex
+
+	submissions  map[byte]submission
+	latencies    map[string]*latencySamples
+	deliverySubs []chan Delivery
+	deliveryMux  sync.Mutex
+
+	recording *Recording
+	stopping  *Recording
+	recordMux sync.Mutex
+
+	batch    *time.Timer
+	batchMux sync.Mutex
+
+	partials map[partsKey]*partial
+	partsMux sync.Mutex
+
+	received    map[duplicateKey]time.Time
+	receivedMux sync.Mutex
+
+	persisted    map[*sms.Message]string
+	persistedMux sync.Mutex
+
+	classified     map[*sms.Message]Classification
+	classifiedRing []*sms.Message
+	classifiedNext int
+	classifiedMux  sync.Mutex
+
+	lifecycle    StringOpt
+	lifecycleMux sync.Mutex
+
+	unsupported  map[string]int
+	blacklistMux sync.Mutex
+
+	held     []*heldMessage
+	holdMux  sync.Mutex
+	flushMux sync.Mutex
+
+	sentToday   map[sms.PhoneNumber]dailyCount
+	sentPruned  time.Time
+	scheduleMux sync.Mutex
+
+	// concatRef is the reference number of the last concatenated message.
+	concatRef uint32
+	// syncSeq is the sequence number of the last resync command.
+	syncSeq uint32
+	// indicators are the names of the +CIEV indicators, see indicatorName.
+	indicators []string
+
+	lost   int32
 	active bool
 }
 
@@ -90,37 +237,23 @@ func (d *Device) StateUpdate() <-chan struct{} {
 	return d.updated
 }
 
+// Reconnected fires when the device was reopened and initialized again by the Watchdog.
+func (d *Device) Reconnected() <-chan struct{} {
+	return d.reconnected
+}
+
 // Closed fires when the connection was closed.
 func (d *Device) Closed() <-chan struct{} {
 	return d.closed
 }
 
-// sendInteractive is a special case of Send, but this one is used whether
-// a prompt should be received first (i.e. when sending SMS, the PDU should be
-// entered after the device replied with '>') and then the second part of payload
-// should be sent (the second payload will be sent using Send).
-func (d *Device) sendInteractive(part1, part2 string, prompt byte) (reply string, err error) {
-
-	err = d.withTimeout(func() error {
-		_, err := d.cmdPort.Write([]byte(part1 + Sep))
-		if err != nil {
-			return err
-		}
-
-		// finally: send control character to exit interactive mode
-		defer d.cmdPort.Write([]byte{pdu.Esc})
-
-		buf := bufio.NewReader(d.cmdPort)
-		reply, err = buf.ReadString(prompt)
-		if err != nil {
-			return err
-		}
-
-		reply, err = d.Send(part2 + Sub)
-		return err
-	})
-
-	return reply, err
+// session returns the context of the device lifetime, it's cancelled by Close.
+// The background work that outlives a command, i.e. the expired parts, runs within it.
+func (d *Device) session() context.Context {
+	if d.sessionCtx == nil {
+		return context.Background()
+	}
+	return d.sessionCtx
 }
 
 // sanityCheck checks whether ports are opened and (if requested) that the initialization
@@ -137,6 +270,9 @@ func (d *Device) sanityCheck(initialized bool) error {
 			return ErrNotInitialized
 		}
 	}
+	if atomic.LoadInt32(&d.lost) != 0 {
+		return ErrDeviceLost
+	}
 	return nil
 }
 
@@ -144,65 +280,118 @@ func (d *Device) sanityCheck(initialized bool) error {
 // Result will not contain any FinalReply since they're used to detect error status.
 // Multiple lines will be joined with '\n'.
 func (d *Device) Send(req string) (reply string, err error) {
-	if err = d.sanityCheck(true); err != nil {
+	return d.SendPriority(req, PriorityNormal)
+}
+
+// SendPriority is like Send, but the command is sent before the pending commands
+// of the lower priority.
+func (d *Device) SendPriority(req string, prio Priority) (reply string, err error) {
+	if err = d.guard(req); err != nil {
 		return
 	}
-
-	err = d.withTimeout(func() error {
-		_, err := d.cmdPort.Write([]byte(req + Sep))
-		if err != nil {
-			return err
+	for retry := 0; ; retry++ {
+		if err = d.sanityCheck(true); err != nil {
+			return
+		}
+		if err = d.cmdMux.acquire(prio, req, ""); err != nil {
+			return
+		}
+		d.pace()
+		reply, err = d.send(req)
+		d.lastCommand = time.Now()
+		d.cmdMux.Unlock()
+		if d.Retry == nil || retry >= d.Retry.Attempts || !errors.Is(err, errFinalError) {
+			return
 		}
+		// the port is released while waiting, so the other commands may pass
+		time.Sleep(d.Retry.delay(retry))
+	}
+}
 
-		var line string
-		buf := bufio.NewReader(d.cmdPort)
+// send is the implementation of Send, the caller must hold the command port lock.
+// The blacklisted commands are not sent, see UnsupportedLimit.
+func (d *Device) send(req string) (reply string, err error) {
+	if err = d.blacklisted(req); err != nil {
+		return
+	}
+	err = d.withTimeout(d.commandTimeout(req), func() (err error) {
+		reply, err = d.exchange(req)
+		return
+	})
+	d.supported(req, err)
+	return
+}
+
+// exchange writes the request and reads the reply until the final result,
+// the caller must hold the command port lock and set its deadline.
+func (d *Device) exchange(req string) (reply string, err error) {
+	_, err = d.cmdPort.Write([]byte(req + Sep))
+	if err != nil {
+		return "", d.portError(err)
+	}
+
+	var line string
+	var echoed, done bool
+	buf := d.cmdReader
+	for !done {
 		if line, err = buf.ReadString('\r'); err != nil {
-			return err
+			err = d.portError(err)
+			break
 		}
 		text := strings.TrimSpace(line)
-		if !strings.HasPrefix(req, text) {
-			return err
+		if len(text) < 1 {
+			continue
 		}
-
-		var done bool
-		for !done {
-			if line, err = buf.ReadString('\r'); err != nil {
-				break
-			}
-			text := strings.TrimSpace(line)
-			if len(text) < 1 {
+		if !echoed && len(reply) == 0 && strings.HasPrefix(req, text) {
+			// skip the echo of the command, if enabled on the device
+			echoed = true
+			continue
+		}
+		if t, ok := d.terminator(text); ok {
+			err, done = t.Err, true
+			continue
+		}
+		switch opt := FinalResults.Resolve(text); opt {
+		case FinalResults.Ok, FinalResults.Noop:
+			done = true
+		default:
+			if err = resultError(opt, text); err != nil {
+				done = true
 				continue
 			}
-			switch opt := FinalResults.Resolve(text); opt {
-			case FinalResults.Ok, FinalResults.Noop:
-				done = true
-			case FinalResults.Timeout:
-				err = ErrTimeout
-				done = true
-			case FinalResults.CmeError, FinalResults.CmsError:
-				err = errors.New(text)
-				done = true
-			case FinalResults.Error, FinalResults.NotSupported,
-				FinalResults.TooManyParameters, FinalResults.NoCarrier:
-				err = errors.New(opt.Description)
-				done = true
-			default:
-				if len(reply) > 0 {
-					reply += "\n"
-				}
-				reply += text
+			if len(reply) > 0 {
+				reply += "\n"
 			}
+			reply += text
 		}
-
-		return err
-	})
+	}
 
 	return
 }
 
-// runs the passed method with a timeout set on the cmdPort
-func (d *Device) withTimeout(f func() error) error {
-	timeout := d.Timeout
+// resultError returns the error of the final result code resolved from the reply line,
+// it's nil if the line is not an error result.
+func resultError(opt StringOpt, text string) error {
+	switch opt {
+	case FinalResults.Timeout:
+		return ErrTimeout
+	case FinalResults.CmeError, FinalResults.CmsError:
+		return errors.New(text)
+	case FinalResults.Error:
+		return errFinalError
+	case FinalResults.NotSupported,
+		FinalResults.TooManyParameters, FinalResults.NoCarrier:
+		return errors.New(opt.Description)
+	}
+	return nil
+}
+
+// runs the passed method with a timeout set on the cmdPort, the zero timeout
+// means the device's default one.
+func (d *Device) withTimeout(timeout time.Duration, f func() error) error {
+	if timeout == 0 {
+		timeout = d.Timeout
+	}
 	if timeout == 0 {
 		timeout = DefaultTimeout
 	}
@@ -224,36 +413,150 @@ func (d *Device) withTimeout(f func() error) error {
 }
 
 // Watch starts a monitoring process that will wait for events
-// from the device's notification port.
+// from the device's notification port. If the port fails, the device is closed,
+// unless the Watchdog is set, then the device will be reopened and initialized again.
+// Watch keeps waiting while the device sleeps, see Sleep.
 func (d *Device) Watch() error {
-	if d.notifyPort == nil {
-		return errors.New("at: notification port not initialized")
+	for {
+		d.cmdMux.Lock()
+		notify := d.notifyPort
+		d.cmdMux.Unlock()
+		if notify == nil {
+			return errors.New("at: notification port not initialized")
+		}
+
+		err := d.watch(notify)
+		if err == nil {
+			return nil
+		}
+		d.deviceLost(err)
+		d.cmdMux.Lock()
+		reopened := d.notifyPort != notify
+		d.cmdMux.Unlock()
+		if reopened {
+			// the device was woken up meanwhile
+			continue
+		}
+		if awake := d.asleep(); awake != nil {
+			// the ports were closed by Sleep
+			select {
+			case <-awake:
+				continue
+			case <-d.closed:
+				return nil
+			}
+		}
+		d.transition(LifecycleStates.Degraded, err)
+		if d.Watchdog == nil || !d.recover() {
+			d.Close()
+			return nil
+		}
+	}
+}
+
+// watch handles the reports from the notification port until the device gets closed
+// or the connection fails, the error is returned in the latter case.
+func (d *Device) watch(notify port) error {
+	lines := make(chan string)
+	errs := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	var r io.Reader = notify
+	if d.NotifyTee != nil {
+		r = &teeReader{r: notify, w: d.NotifyTee}
+	}
+	go func(buf *bufio.Reader) {
+		for {
+			line, err := buf.ReadString(byte('\r'))
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case lines <- line:
+			case <-done:
+				return
+			}
+		}
+	}(bufio.NewReader(r))
+
+	// pduHeader is the header of a report followed by the PDU on the next line
+	var pduHeader string
+	var silence *time.Timer
+	var silenceC <-chan time.Time
+	if d.Watchdog != nil && d.Watchdog.Silence > 0 {
+		silence = time.NewTimer(d.Watchdog.Silence)
+		defer silence.Stop()
+		silenceC = silence.C
 	}
-	go func() {
-		<-d.closed
-		d.notifyPort.Write([]byte(KillCmd + Sep))
-	}()
 
-	buf := bufio.NewReader(d.notifyPort)
 	for {
 		select {
 		case <-d.closed:
 			return nil
-		default:
-			line, err := buf.ReadString(byte('\r'))
-			if err != nil {
-				d.Close()
+		case err := <-errs:
+			select {
+			case <-d.closed:
 				return nil
+			default:
+				return err
+			}
+		case err := <-d.failures:
+			return err
+		case <-silenceC:
+			// the device has been quiet for too long, make sure it's still alive,
+			// unless the command port is busy with a data session
+			if !d.inDataSession() {
+				_, err := d.Send(NoopCmd)
+				d.FailurePolicy.result(d, err)
+				if err != nil {
+					return err
+				}
+			}
+			silence.Reset(d.Watchdog.Silence)
+		case line := <-lines:
+			if silence != nil {
+				if !silence.Stop() {
+					<-silence.C
+				}
+				silence.Reset(d.Watchdog.Silence)
 			}
 			text := strings.TrimSpace(line)
 			if len(text) < 1 {
 				continue
 			}
+			if len(pduHeader) > 0 {
+				text, pduHeader = pduHeader+"\n"+text, ""
+			} else if r := Reports.Resolve(text); r == Reports.StatusReportPDU || r == Reports.MessagePDU ||
+				r == Reports.BroadcastPDU {
+				pduHeader = text
+				continue
+			}
+			d.publish(text)
+			if d.deferReport(text) {
+				continue
+			}
 			d.handleReport(text) // ignore errors
 		}
 	}
 }
 
+// teeReader copies the data read from r to w like io.TeeReader, but the write errors
+// are ignored, so the copy doesn't break the port.
+type teeReader struct {
+	r io.Reader
+	w io.Writer
+}
+
+func (t *teeReader) Read(b []byte) (n int, err error) {
+	n, err = t.r.Read(b)
+	if n > 0 {
+		t.w.Write(b[:n])
+	}
+	return
+}
+
 // handleReport detects and parses a report from the notification port represented
 // as a string. The parsed values may change the inner state or be sent over out channels.
 func (d *Device) handleReport(str string) (err error) {
@@ -268,24 +571,68 @@ func (d *Device) handleReport(str string) (err error) {
 
 		callerID := report.GetCallerID()
 		d.incomingCallerIDs <- callerID
-	case Reports.Message:
-		var report messageReport
-		if err = report.Parse(str); err != nil {
+	case Reports.Message, Reports.StatusReport:
+		var indication MessageIndication
+		if err = indication.Parse(str); err != nil {
+			return
+		}
+		if report == Reports.Message && d.deferIndication(indication) {
 			return
 		}
+		// the InboxPolicy is applied as by FetchInbox, the message is deleted only once
+		// it's received, so it's not lost if that fails; it's marked read by AT+CMGR
+		policy := InboxPolicies.Delete
+		if p, ok := d.Commands.(inboxPolicer); ok {
+			if policy, err = p.inboxPolicy(); err != nil {
+				return
+			}
+		}
+		var remove func() error
+		if policy == InboxPolicies.Delete {
+			remove = func() error {
+				return d.Commands.CMGD(indication.Index, DeleteOptions.Index)
+			}
+		}
+		if d.textMode() {
+			var msg *sms.Message
+			if msg, err = d.Commands.(textReader).readText(indication.Index); err != nil {
+				return
+			}
+			return d.receiveMessage(context.Background(), msg, remove)
+		}
 		var octets []byte
-		octets, err = d.Commands.CMGR(report.Index)
+		octets, err = d.Commands.CMGR(indication.Index)
 		if err != nil {
 			return
 		}
-		if err = d.Commands.CMGD(report.Index, DeleteOptions.Index); err != nil {
-			return
+		return d.receive(context.Background(), octets, remove)
+	case Reports.MessagePDU, Reports.StatusReportPDU:
+		if report == Reports.MessagePDU && d.textMode() {
+			var msg *sms.Message
+			if msg, err = parseTextDelivery(str); err != nil {
+				return
+			}
+			ackErr := d.acknowledge()
+			if err = d.receiveMessage(context.Background(), msg, nil); err != nil {
+				return
+			}
+			return ackErr
+		}
+		// +CDS: <length>\n<pdu> or +CMT: [<alpha>],<length>\n<pdu>, the message is not stored
+		lines := strings.SplitN(str, "\n", 2)
+		if len(lines) < 2 {
+			return ErrParseReport
+		}
+		var octets []byte
+		if octets, err = util.Bytes(strings.TrimSpace(lines[1])); err != nil {
+			return ErrParseReport
 		}
-		var msg sms.Message
-		if _, err = msg.ReadFrom(octets); err != nil {
+		// acknowledged before decoding, a malformed PDU would be re-delivered otherwise
+		ackErr := d.acknowledge()
+		if err = d.receive(context.Background(), octets, nil); err != nil {
 			return
 		}
-		d.messages <- &msg
+		return ackErr
 	case Reports.Ussd:
 		var ussd ussdReport
 		if err = ussd.Parse(str); err != nil {
@@ -340,6 +687,7 @@ func (d *Device) handleReport(str string) (err error) {
 		if d.State.ServiceState != Opt(report) {
 			d.State.ServiceState = Opt(report)
 			d.updated <- struct{}{}
+			d.serviceChanged()
 		}
 	case Reports.SimState:
 		var report simStateReport
@@ -350,11 +698,84 @@ func (d *Device) handleReport(str string) (err error) {
 			d.State.SimState = Opt(report)
 			d.updated <- struct{}{}
 		}
+	case Reports.NetworkStatus:
+		var report zpasReport
+		if err = report.Parse(str); err != nil {
+			return
+		}
+		var updated bool
+		for _, f := range []struct {
+			field *Opt
+			value Opt
+		}{
+			{&d.State.ServiceState, report.ServiceState},
+			{&d.State.ServiceDomain, report.ServiceDomain},
+			{&d.State.SystemMode, report.SystemMode},
+			{&d.State.SystemSubmode, report.SystemSubmode},
+		} {
+			if *f.field != f.value {
+				*f.field = f.value
+				updated = true
+			}
+		}
+		if updated {
+			d.updated <- struct{}{}
+			d.serviceChanged()
+		}
+	case Reports.QuerySimStatus:
+		var report qssReport
+		if err = report.Parse(str); err != nil {
+			return
+		}
+		if d.State.SimState != Opt(report) {
+			d.State.SimState = Opt(report)
+			d.updated <- struct{}{}
+		}
+	case Reports.Recording:
+		if str == "crec stop" {
+			d.recordingStopped()
+		}
+	case Reports.Jamming, Reports.QuectelJamming:
+		var jammed jammingReport
+		if err = jammed.Parse(str); err != nil {
+			return
+		}
+		select {
+		case d.jamming <- Jamming{Jammed: bool(jammed), Time: time.Now()}:
+		default:
+		}
+	case Reports.BroadcastPDU:
+		// +CBM: <length>\n<pdu>, the broadcasts are not acknowledged
+		lines := strings.SplitN(str, "\n", 2)
+		if len(lines) < 2 {
+			return ErrParseReport
+		}
+		var octets []byte
+		if octets, err = util.Bytes(strings.TrimSpace(lines[1])); err != nil {
+			return ErrParseReport
+		}
+		page := new(sms.CellBroadcast)
+		if _, err = page.ReadFrom(octets); err != nil {
+			return
+		}
+		select {
+		case d.broadcasts <- page:
+		default:
+		}
+	case Reports.Indicator:
+		var report indicatorReport
+		if err = report.Parse(str); err != nil {
+			return
+		}
+		return d.indicator(report)
 	case Reports.BootHandshake:
 		var token bootHandshakeReport
 		if err = token.Parse(str); err != nil {
 			return
 		}
+		if h, ok := d.Commands.(bootHandshaker); ok && !h.bootHandshake() {
+			return
+		}
 		if err = d.Commands.BOOT(uint64(token)); err != nil {
 			return
 		}
@@ -373,17 +794,61 @@ func (d *Device) handleReport(str string) (err error) {
 
 // Open is used to open serial ports of the device. This should be used first.
 // The method returns error if open was not succeed, i.e. if device is absent.
+// The ports are opened using the Dial function of the device.
 func (d *Device) Open() (err error) {
-	if d.cmdPort, err = os.OpenFile(d.CommandPort, os.O_RDWR, 0); err != nil {
+	d.transition(LifecycleStates.Opening, nil)
+	// the device may be another one, or its firmware may be updated
+	d.ClearBlacklist()
+	if err = d.open(); err != nil {
+		d.transition(LifecycleStates.Closed, err)
+	}
+	return
+}
+
+// open is the implementation of Open, the lifecycle state is left to the caller.
+func (d *Device) open() (err error) {
+	dial := d.Dial
+	if dial == nil {
+		dial = DialSerial
+		if d.BaudRate == AutoBaud {
+			if d.BaudRate, err = DetectBaudRate(d.CommandPort); err != nil {
+				return
+			}
+		}
+		if d.BaudRate != 0 || d.FlowControl {
+			dial = SerialConfig{
+				BaudRate:    d.BaudRate,
+				FlowControl: d.FlowControl,
+			}.Dialer()
+		}
+	}
+	cmd, err := dial(d.CommandPort)
+	if err != nil {
 		return
 	}
+	var notify io.ReadWriteCloser
 	if d.NotifyPort != "" && d.NotifyPort != d.CommandPort {
-		if d.notifyPort, err = os.OpenFile(d.NotifyPort, os.O_RDWR, 0); err != nil {
-			d.cmdPort.Close()
+		if notify, err = dial(d.NotifyPort); err != nil {
+			cmd.Close()
 			return
 		}
 	}
-	return
+	return d.Attach(cmd, notify)
+}
+
+// Attach makes the device operate over the given streams instead of opening the ports
+// by itself, this may be used in place of Open. Both streams will be closed by Close.
+func (d *Device) Attach(cmd, notify io.ReadWriteCloser) error {
+	if cmd == nil {
+		return ErrClosed
+	}
+	d.cmdPort = newPort(cmd)
+	d.cmdReader = bufio.NewReader(d.cmdPort)
+	atomic.StoreInt32(&d.lost, 0)
+	if notify != nil {
+		d.notifyPort = newPort(notify)
+	}
+	return nil
 }
 
 // Init checks whether device is opened, initializes event channels
@@ -394,12 +859,24 @@ func (d *Device) Init(profile DeviceProfile) error {
 	}
 	d.active = true
 	d.closed = make(chan struct{})
+	d.sessionCtx, d.sessionCancel = context.WithCancel(context.Background())
 	d.incomingCallerIDs = make(chan *calls.CallerID, 100)
 	d.messages = make(chan *sms.Message, 100)
 	d.ussd = make(chan Ussd, 100)
 	d.updated = make(chan struct{}, 100)
+	d.reconnected = make(chan struct{}, 100)
+	d.failures = make(chan error, 1)
+	d.deliveries = make(chan Delivery, 100)
+	d.indications = make(chan MessageIndication, 100)
+	d.recordings = make(chan Recording, 100)
+	d.lostEvents = make(chan DeviceLost, 100)
+	d.jamming = make(chan Jamming, 100)
+	d.broadcasts = make(chan *sms.CellBroadcast, 100)
+	d.holdEvents = make(chan HoldEvent, 100)
+	d.voicemail = make(chan VoicemailWaiting, 100)
+	d.diverted = make(chan DivertedMessage, 100)
 	d.Commands = profile
-	return profile.Init(d)
+	return d.initProfile(profile)
 }
 
 // Close closes all the event channels and also closes
@@ -408,10 +885,18 @@ func (d *Device) Init(profile DeviceProfile) error {
 //
 // Close is a no-op if already closed.
 func (d *Device) Close() (err error) {
+	d.transition(LifecycleStates.Closing, nil)
+	defer d.transition(LifecycleStates.Closed, nil)
 	if d.active {
 		d.active = false
 		close(d.closed)
+		d.sessionCancel()
 	}
+	return d.closePorts()
+}
+
+// closePorts closes both command and notification ports.
+func (d *Device) closePorts() (err error) {
 	if d.cmdPort != nil {
 		err = d.cmdPort.Close()
 	}
@@ -429,27 +914,209 @@ func (d *Device) SendUSSD(req string) (err error) {
 	return
 }
 
-// SendSMS sends an SMS message with given text to the given address,
-// the encoding and other parameters are default.
-func (d *Device) SendSMS(text string, address sms.PhoneNumber) (err error) {
-	msg := sms.Message{
-		Text:     text,
-		Type:     sms.MessageTypes.Submit,
-		Encoding: sms.Encodings.Gsm7Bit,
-		Address:  address,
-		VPFormat: sms.ValidityPeriodFormats.Relative,
-		VP:       sms.ValidityPeriod(24 * time.Hour * 4),
+// DefaultValidityPeriod is the validity period of the sent messages unless specified otherwise.
+const DefaultValidityPeriod = 4 * 24 * time.Hour
+
+// SendOptions holds the per-message parameters of the sent SMS, the zero value
+// gives a message with the default parameters.
+type SendOptions struct {
+	// UCS2 forces UCS2 encoding, otherwise it's used only if the text is not 7-bit encodable.
+	UCS2 bool
+	// NationalLanguage allows the national language shift tables (i.e. Turkish) for the texts
+	// that are not 7-bit encodable with the default alphabet, instead of UCS2.
+	NationalLanguage bool
+	// Transliterate converts the texts that are not 7-bit encodable to the nearest 7-bit
+	// characters (i.e. "ą" to "a") instead of UCS2, the rest are replaced with "?".
+	Transliterate bool
+	// Class sets the message class, i.e. Flash for a class 0 message.
+	Class sms.MessageClass
+	// ValidityPeriod is the relative validity period, DefaultValidityPeriod if zero.
+	ValidityPeriod time.Duration
+	// ValidUntil sets the absolute validity period instead, if not zero.
+	ValidUntil time.Time
+	// StatusReport requests a status report for the message.
+	StatusReport bool
+	// ServiceCenter overrides the SMSC address stored in the SIM.
+	ServiceCenter sms.PhoneNumber
+	// ProtocolIdentifier sets the TP-PID, i.e. Type0 for a silent message or Replace1
+	// for a message replacing the previous one of the same type.
+	ProtocolIdentifier sms.ProtocolIdentifier
+	// Priority of the message in the command queue, i.e. PriorityHigh for OTP codes
+	// and PriorityLow for the bulk messages.
+	Priority Priority
+	// CorrelationID traces the message through the pending commands, the deferrals
+	// and the deliveries. It's generated with GenerateID if empty.
+	CorrelationID string
+	// DestinationPort and SourcePort address the message to an application, the ports
+	// header is added if the DestinationPort is set.
+	DestinationPort int
+	SourcePort      int
+}
+
+// prioritySender is implemented by profiles that are able to queue the messages
+// with a priority and a correlation ID.
+type prioritySender interface {
+	CMGSPriority(length int, octets []byte, prio Priority, id string) (byte, error)
+}
+
+// bootHandshaker is implemented by profiles that may leave the ^BOOT handshake
+// to the application.
+type bootHandshaker interface {
+	bootHandshake() bool
+}
+
+// acknowledger is implemented by profiles that acknowledge the messages routed
+// directly to the terminal.
+type acknowledger interface {
+	acknowledge() error
+}
+
+// acknowledge acknowledges the +CMT or +CDS message if the profile requires it.
+func (d *Device) acknowledge() error {
+	if a, ok := d.Commands.(acknowledger); ok {
+		return a.acknowledge()
 	}
+	return nil
+}
 
-	if !pdu.Is7BitEncodable(text) {
+// Message constructs an SMS-SUBMIT message with the given text and address.
+func (o SendOptions) Message(text string, address sms.PhoneNumber) sms.Message {
+	msg := sms.Message{
+		Text:                 text,
+		Type:                 sms.MessageTypes.Submit,
+		Encoding:             sms.Encodings.Gsm7Bit,
+		Class:                o.Class,
+		Address:              address,
+		ServiceCenterAddress: o.ServiceCenter,
+		VPFormat:             sms.ValidityPeriodFormats.Relative,
+		VP:                   sms.ValidityPeriod(DefaultValidityPeriod),
+		StatusReportRequest:  o.StatusReport,
+		ProtocolIdentifier:   o.ProtocolIdentifier,
+	}
+	if o.DestinationPort != 0 {
+		msg.UserDataStartsWithHeader = true
+		msg.UserDataHeader.DestinationPort = o.DestinationPort
+		msg.UserDataHeader.SourcePort = o.SourcePort
+	}
+	if o.ValidityPeriod > 0 {
+		msg.VP = sms.ValidityPeriod(o.ValidityPeriod)
+	}
+	if !o.ValidUntil.IsZero() {
+		msg.VPFormat = sms.ValidityPeriodFormats.Absolute
+		msg.ValidUntil = sms.Timestamp(o.ValidUntil)
+	}
+	switch {
+	case o.UCS2:
+		msg.Encoding = sms.Encodings.UCS2
+	case pdu.Is7BitEncodable(text):
+	default:
+		if tables, ok := pdu.SelectTables(text); ok && o.NationalLanguage {
+			msg.UserDataStartsWithHeader = true
+			msg.UserDataHeader.SetTables(tables)
+			break
+		}
+		if o.Transliterate {
+			msg.Text, _ = pdu.Convert7Bit(text, pdu.Lossy)
+			break
+		}
 		msg.Encoding = sms.Encodings.UCS2
 	}
+	return msg
+}
 
-	n, octets, err := msg.PDU()
+// SendSMS sends an SMS message with given text to the given address,
+// the encoding and other parameters are default unless the options are given.
+// The message fails with ErrHeld if it's held until the service returns, see Hold.
+func (d *Device) SendSMS(text string, address sms.PhoneNumber, opts ...SendOptions) error {
+	o := firstOptions(opts)
+	return d.submitSMS(o.Message(text, address), false, o)
+}
+
+// SendSMSRef is like SendSMS, but it also returns the message reference assigned by
+// the modem (+CMGS: <mr>), i.e. to match the status report of the message.
+func (d *Device) SendSMSRef(text string, address sms.PhoneNumber, opts ...SendOptions) (ref byte, err error) {
+	o := firstOptions(opts)
+	refs, err := d.submitParts([]sms.Message{o.Message(text, address)}, o)
 	if err != nil {
+		return 0, err
+	}
+	return refs[0], nil
+}
+
+// SendLongSMS is like SendSMS, but the text that doesn't fit into a single message
+// (160 GSM 7-bit or 70 UCS2 characters) is sent as a concatenated message.
+// The parts are sent sequentially and share the correlation ID.
+func (d *Device) SendLongSMS(text string, address sms.PhoneNumber, opts ...SendOptions) error {
+	o := firstOptions(opts)
+	return d.submitSMS(o.Message(text, address), true, o)
+}
+
+// SendData sends an 8-bit binary message with the given data to the given address,
+// usually addressed to an application port with the DestinationPort option (i.e. for the
+// OTA provisioning). The data that doesn't fit into a single message (140 octets without
+// the header) is sent as a concatenated message like by SendLongSMS.
+func (d *Device) SendData(data []byte, address sms.PhoneNumber, opts ...SendOptions) error {
+	o := firstOptions(opts)
+	msg := o.Message("", address)
+	msg.Encoding, msg.Data = sms.Encodings.Data8Bit, data
+	return d.submitSMS(msg, true, o)
+}
+
+// firstOptions returns the given options or the default ones.
+func firstOptions(opts []SendOptions) SendOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return SendOptions{}
+}
+
+// split splits the message into the parts of a concatenated message, if requested,
+// the parts share a new reference number.
+func (d *Device) split(msg sms.Message, split bool) ([]sms.Message, error) {
+	if !split {
+		return []sms.Message{msg}, nil
+	}
+	return msg.Split(byte(atomic.AddUint32(&d.concatRef, 1)))
+}
+
+// sendParts sends the parts of a message and returns the references of the parts sent
+// before an error, if any.
+func (d *Device) sendParts(parts []sms.Message, o SendOptions) (refs []byte, err error) {
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	address := parts[0].Address
+	if o.CorrelationID == "" {
+		o.CorrelationID = GenerateID()
+	}
+	if err = d.schedule(address, o.CorrelationID, time.Now()); err != nil {
+		// the deferred messages are not the device failures
 		return
 	}
+	defer func() { d.FailurePolicy.result(d, err) }()
 
-	_, err = d.Commands.CMGS(n, octets)
+	for _, msg := range parts {
+		n, octets, err := msg.PDU()
+		if err != nil {
+			return refs, err
+		}
+		var ref byte
+		if p, ok := d.Commands.(prioritySender); ok {
+			ref, err = p.CMGSPriority(n, octets, o.Priority, o.CorrelationID)
+		} else {
+			ref, err = d.Commands.CMGS(n, octets)
+		}
+		if err != nil {
+			if udh := msg.UserDataHeader; udh.TotalNumber > 1 {
+				return refs, fmt.Errorf("at: part %d of %d: %w", udh.Sequence, udh.TotalNumber, err)
+			}
+			return refs, err
+		}
+		refs = append(refs, ref)
+		if o.StatusReport {
+			d.submitted(ref, address, o.CorrelationID)
+		}
+	}
+	d.sent(address, time.Now())
 	return
 }
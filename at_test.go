@@ -0,0 +1,297 @@
+package at
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+)
+
+// serveModem emulates a modem with echo enabled on the given stream. Each received command
+// is answered with the matching reply, or with ERROR if the command is not known.
+// The echo could be toggled with ATE0 and ATE1.
+func serveModem(conn io.ReadWriter, replies map[string]string) {
+	buf := bufio.NewReader(conn)
+	echo := true
+	for {
+		line, err := buf.ReadString('\r')
+		if err != nil {
+			return
+		}
+		req := strings.TrimSpace(line)
+		if len(req) < 1 {
+			continue
+		}
+		reply, ok := replies[req]
+		switch {
+		case ok:
+		case req == NoopCmd || req == "ATE0" || req == "ATE1":
+			reply = "OK"
+		default:
+			reply = "ERROR"
+		}
+		if !strings.HasSuffix(reply, "> ") {
+			// prompts are not followed by a line break
+			reply += Sep
+		}
+		if echo {
+			reply = req + Sep + reply
+		}
+		if _, err = io.WriteString(conn, reply); err != nil {
+			return
+		}
+		echo = req != "ATE0" && (echo || req == "ATE1")
+	}
+}
+
+// newTestDevice returns a device attached to an emulated modem and initialized
+// with the noopProfile, it also returns the modem's end of the notification port.
+func newTestDevice(t *testing.T, replies map[string]string) (*Device, net.Conn) {
+	t.Helper()
+	return newProfileDevice(t, &noopProfile{}, replies)
+}
+
+// newProfileDevice is like newTestDevice, but the device is initialized with the profile.
+func newProfileDevice(t *testing.T, profile DeviceProfile, replies map[string]string) (*Device, net.Conn) {
+	t.Helper()
+	dev, urc := attachTestDevice(t, func(modem, urc net.Conn) {
+		serveModem(modem, replies)
+	})
+	require.NoError(t, dev.Init(profile))
+	return dev, urc
+}
+
+// attachTestDevice returns a device attached to the modem emulated by serve, the device
+// is not initialized. It also returns the modem's end of the notification port.
+func attachTestDevice(t *testing.T, serve func(modem, urc net.Conn)) (*Device, net.Conn) {
+	t.Helper()
+	cmd, modem := net.Pipe()
+	notify, urc := net.Pipe()
+	go serve(modem, urc)
+
+	dev := &Device{Timeout: time.Second}
+	require.NoError(t, dev.Attach(cmd, notify))
+	t.Cleanup(func() {
+		dev.Close()
+		modem.Close()
+		urc.Close()
+	})
+	return dev, urc
+}
+
+// testDialer opens emulated ports: the "cmd" port is served by serveModem, the
+// modem's ends of the other ports are collected in urcs.
+type testDialer struct {
+	replies map[string]string
+
+	mux  sync.Mutex
+	urcs []net.Conn
+}
+
+func (td *testDialer) Dial(name string) (io.ReadWriteCloser, error) {
+	local, remote := net.Pipe()
+	td.mux.Lock()
+	defer td.mux.Unlock()
+	if name == "cmd" {
+		go serveModem(remote, td.replies)
+	} else {
+		td.urcs = append(td.urcs, remote)
+	}
+	return local, nil
+}
+
+// urc returns the modem's end of the n-th opened notification port.
+func (td *testDialer) urc(n int) net.Conn {
+	td.mux.Lock()
+	defer td.mux.Unlock()
+	return td.urcs[n]
+}
+
+// noopProfile is the DefaultProfile that doesn't configure the device on Init.
+type noopProfile struct {
+	DefaultProfile
+}
+
+func (p *noopProfile) Init(d *Device) error {
+	p.dev = d
+	_, err := d.Send(NoopCmd)
+	return err
+}
+
+func TestDeviceSend(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+GMM": "E173" + Sep + Sep + "OK",
+		"AT+GSN": "ERROR",
+	})
+
+	reply, err := dev.Send(`AT+GMM`)
+	require.NoError(t, err)
+	assert.Equal(t, "E173", reply)
+
+	_, err = dev.Send(`AT+GSN`)
+	assert.Error(t, err)
+
+	_, err = dev.Send(`ATE0`)
+	require.NoError(t, err)
+	reply, err = dev.Send(`AT+GMM`)
+	require.NoError(t, err)
+	assert.Equal(t, "E173", reply)
+}
+
+func TestSendOptions(t *testing.T) {
+	t.Parallel()
+
+	msg := SendOptions{}.Message("hello", "+79269965690")
+	assert.Equal(t, sms.Encodings.Gsm7Bit, msg.Encoding)
+	assert.Equal(t, sms.ValidityPeriod(DefaultValidityPeriod), msg.VP)
+
+	msg = SendOptions{
+		Class:              sms.MessageClasses.Flash,
+		ValidityPeriod:     time.Hour,
+		StatusReport:       true,
+		ProtocolIdentifier: sms.ProtocolIdentifiers.Type0,
+	}.Message("привет", "+79269965690")
+	assert.Equal(t, sms.Encodings.UCS2, msg.Encoding)
+	assert.True(t, msg.StatusReportRequest)
+	_, octets, err := msg.PDU()
+	require.NoError(t, err)
+	// SMSC length, first octet, MR, 8 octets of the address, then PID and DCS
+	assert.Equal(t, []byte{0x40, 0x18}, octets[11:13])
+
+	msg = SendOptions{Class: sms.MessageClasses.SIM}.Message("hello", "+79269965690")
+	_, octets, err = msg.PDU()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x12}, octets[11:13])
+
+	msg = SendOptions{}.Message("Şifreniz 1234", "+79269965690")
+	assert.Equal(t, sms.Encodings.UCS2, msg.Encoding)
+	msg = SendOptions{NationalLanguage: true}.Message("Şifreniz 1234", "+79269965690")
+	assert.Equal(t, sms.Encodings.Gsm7Bit, msg.Encoding)
+	assert.True(t, msg.UserDataStartsWithHeader)
+	assert.EqualValues(t, 0x01, msg.UserDataHeader.LockingShift)
+
+	msg = SendOptions{Transliterate: true}.Message("Zażółć gęślą jaźń", "+79269965690")
+	assert.Equal(t, sms.Encodings.Gsm7Bit, msg.Encoding)
+	assert.Equal(t, "Zazolc gesla jazn", msg.Text)
+}
+
+func TestSendSMSRef(t *testing.T) {
+	t.Parallel()
+
+	msg := SendOptions{}.Message("hello", "+79269965690")
+	n, octets, err := msg.PDU()
+	require.NoError(t, err)
+	dev, _ := newTestDevice(t, map[string]string{
+		fmt.Sprintf("AT+CMGS=%d", n):      "> ",
+		fmt.Sprintf("%02X", octets) + Sub: "+CMGS: 42" + Sep + Sep + "OK",
+	})
+	ref, err := dev.SendSMSRef("hello", "+79269965690")
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, ref)
+}
+
+func TestSendLongSMS(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("long text ", 20)
+	opts := SendOptions{StatusReport: true}
+	parts, err := opts.Message(text, "+79269965690").Split(1)
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	replies := make(map[string]string)
+	for i, msg := range parts {
+		n, octets, err := msg.PDU()
+		require.NoError(t, err)
+		replies[fmt.Sprintf("AT+CMGS=%d", n)] = "> "
+		replies[fmt.Sprintf("%02X", octets)+Sub] = fmt.Sprintf("+CMGS: %d", 10+i) + Sep + Sep + "OK"
+	}
+	dev, _ := newTestDevice(t, replies)
+
+	require.NoError(t, dev.SendLongSMS(text, "+79269965690", opts))
+	assert.Len(t, dev.submissions, 2)
+}
+
+func TestSendData(t *testing.T) {
+	t.Parallel()
+
+	data := []byte{0x01, 0x06, 0x03, 0xAE, 0x81, 0xEA, 0x02, 0x05, 0x6A, 0x00}
+	opts := SendOptions{DestinationPort: 2948, SourcePort: 9200}
+	msg := opts.Message("", "+79269965690")
+	msg.Encoding, msg.Data = sms.Encodings.Data8Bit, data
+	n, octets, err := msg.PDU()
+	require.NoError(t, err)
+	dev, _ := newTestDevice(t, map[string]string{
+		fmt.Sprintf("AT+CMGS=%d", n):      "> ",
+		fmt.Sprintf("%02X", octets) + Sub: "+CMGS: 3" + Sep + Sep + "OK",
+	})
+	require.NoError(t, dev.SendData(data, "+79269965690", opts))
+
+	var read sms.Message
+	_, err = read.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, data, read.Data)
+	assert.Equal(t, 2948, read.UserDataHeader.DestinationPort)
+	assert.Equal(t, 9200, read.UserDataHeader.SourcePort)
+}
+
+func TestNotifyTee(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := newTestDevice(t, nil)
+	r, w := io.Pipe()
+	dev.NotifyTee = w
+	go dev.Watch()
+
+	report := Sep + "^VENDOR: 1,2" + Sep
+	go io.WriteString(urc, report)
+	buf := make([]byte, len(report))
+	_, err := io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, report, string(buf))
+}
+
+func TestDeadlinePort(t *testing.T) {
+	t.Parallel()
+
+	r, w := io.Pipe()
+	p := newPort(struct {
+		io.Reader
+		io.WriteCloser
+	}{r, w})
+	defer p.Close()
+
+	require.NoError(t, p.SetDeadline(time.Now().Add(10*time.Millisecond)))
+	_, err := p.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	go w.Write([]byte("OK"))
+	require.NoError(t, p.SetDeadline(time.Time{}))
+	buf := make([]byte, 2)
+	n, err := io.ReadFull(p, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", string(buf[:n]))
+}
+
+func TestBootHandshake(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT^BOOT=20952548,0": "OK",
+	})
+	assert.NoError(t, dev.handleReport("^BOOT:20952548,0,0,0,72"))
+	assert.Error(t, dev.handleReport("^BOOT:12345678,0,0,0,72"), "AT^BOOT would fail")
+
+	dev.Commands.(*noopProfile).DisableBootHandshake = true
+	assert.NoError(t, dev.handleReport("^BOOT:12345678,0,0,0,72"))
+}
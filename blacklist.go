@@ -0,0 +1,88 @@
+package at
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// commandName returns the name of the command without its arguments and the read
+// and test forms, i.e. "AT+CSMS" for "AT+CSMS=1" and "AT+CSMS?".
+func commandName(req string) string {
+	name := strings.ToUpper(strings.TrimSpace(req))
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, "?")
+}
+
+// notSupported reports whether the command failed with COMMAND NOT SUPPORT
+// or +CME ERROR: 4 (operation not supported).
+func notSupported(err error) bool {
+	if err == nil {
+		return false
+	}
+	text := err.Error()
+	if text == FinalResults.NotSupported.Description {
+		return true
+	}
+	if !strings.HasPrefix(text, FinalResults.CmeError.ID) {
+		return false
+	}
+	code := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, FinalResults.CmeError.ID)))
+	return code == "4" || code == "operation not supported"
+}
+
+// blacklisted returns ErrUnsupported if the command has been blacklisted, see UnsupportedLimit.
+func (d *Device) blacklisted(req string) error {
+	if d.UnsupportedLimit <= 0 {
+		return nil
+	}
+	name := commandName(req)
+	d.blacklistMux.Lock()
+	defer d.blacklistMux.Unlock()
+	if d.unsupported[name] >= d.UnsupportedLimit {
+		return fmt.Errorf("%w: %s is not supported by the firmware", ErrUnsupported, name)
+	}
+	return nil
+}
+
+// supported counts the replies of the command, the command is blacklisted once it's
+// replied as not supported UnsupportedLimit times in a row.
+func (d *Device) supported(req string, err error) {
+	if d.UnsupportedLimit <= 0 {
+		return
+	}
+	name := commandName(req)
+	d.blacklistMux.Lock()
+	defer d.blacklistMux.Unlock()
+	if !notSupported(err) {
+		delete(d.unsupported, name)
+		return
+	}
+	if d.unsupported == nil {
+		d.unsupported = make(map[string]int)
+	}
+	d.unsupported[name]++
+}
+
+// Blacklisted returns the names of the commands that fail with ErrUnsupported without
+// being sent, see UnsupportedLimit.
+func (d *Device) Blacklisted() (names []string) {
+	d.blacklistMux.Lock()
+	defer d.blacklistMux.Unlock()
+	for name, n := range d.unsupported {
+		if n >= d.UnsupportedLimit {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+// ClearBlacklist lets the blacklisted commands be sent again, i.e. after a firmware update.
+func (d *Device) ClearBlacklist() {
+	d.blacklistMux.Lock()
+	defer d.blacklistMux.Unlock()
+	d.unsupported = nil
+}
@@ -0,0 +1,69 @@
+package at
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlacklist(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT^SYSCFG?":   "COMMAND NOT SUPPORT",
+		"AT+CSMS=1":    "+CME ERROR: 4",
+		"AT+CSMS=0":    "+CME ERROR: operation not supported",
+		"AT+CGMI":      "+CME ERROR: 4",
+		"AT+CGMI=?":    "OK",
+		"AT+CIND=1,99": "+CME ERROR: 3",
+	})
+	dev.UnsupportedLimit = 2
+
+	for i := 0; i < 2; i++ {
+		_, err := dev.Send("AT^SYSCFG?")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrUnsupported)
+	}
+	_, err := dev.Send("AT^SYSCFG=2,2,3FFFFFFF,1,2")
+	assert.ErrorIs(t, err, ErrUnsupported, "the command is not sent")
+
+	dev.Send("AT+CSMS=1")
+	dev.Send("AT+CSMS=0")
+	dev.Send("AT+CGMI")
+	dev.Send("AT+CGMI=?")
+	dev.Send("AT+CGMI")
+	dev.Send("AT+CIND=1,99")
+	dev.Send("AT+CIND=1,99")
+	assert.Equal(t, []string{"AT+CSMS", "AT^SYSCFG"}, dev.Blacklisted(), "only the replies in a row count")
+
+	dev.ClearBlacklist()
+	assert.Empty(t, dev.Blacklisted())
+	_, err = dev.Send("AT^SYSCFG?")
+	assert.NotErrorIs(t, err, ErrUnsupported)
+}
+
+func TestBlacklistReopen(t *testing.T) {
+	t.Parallel()
+
+	td := &testDialer{replies: map[string]string{
+		"AT^SYSCFG?": "COMMAND NOT SUPPORT",
+	}}
+	dev := &Device{
+		CommandPort:      "cmd",
+		NotifyPort:       "notify",
+		Dial:             td.Dial,
+		Timeout:          time.Second,
+		UnsupportedLimit: 1,
+	}
+	require.NoError(t, dev.Open())
+	require.NoError(t, dev.Init(&noopProfile{}))
+	dev.Send("AT^SYSCFG?")
+	assert.Equal(t, []string{"AT^SYSCFG"}, dev.Blacklisted())
+	require.NoError(t, dev.Close())
+
+	require.NoError(t, dev.Open())
+	defer dev.Close()
+	assert.Empty(t, dev.Blacklisted(), "the device may be another one")
+}
@@ -0,0 +1,84 @@
+package at
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xlab/at/sms"
+)
+
+// broadcastListener is implemented by profiles that are able to receive the cell broadcasts.
+type broadcastListener interface {
+	CSCB(accept bool, channels []int) (err error)
+	routeBroadcasts(enable bool) (err error)
+}
+
+// CSCB sends AT+CSCB to the device, that selects the cell broadcast channels (message IDs):
+// the listed ones are either accepted or rejected. No channels are accepted if the accepted
+// list is empty, and all of them if the rejected one is.
+func (p *DefaultProfile) CSCB(accept bool, channels []int) (err error) {
+	mode := 1
+	if accept {
+		mode = 0
+	}
+	ids := make([]string, len(channels))
+	for i, id := range channels {
+		ids[i] = strconv.Itoa(id)
+	}
+	req := fmt.Sprintf(`AT+CSCB=%d,"%s"`, mode, strings.Join(ids, ","))
+	_, err = p.dev.Send(req)
+	return
+}
+
+// routeBroadcasts routes the cell broadcasts to the terminal as +CBM, keeping the other
+// message notifications selected on Init.
+func (p *DefaultProfile) routeBroadcasts(enable bool) (err error) {
+	cfg := p.notifications
+	cfg.BM = 0
+	if enable {
+		cfg.BM = 2
+	}
+	if err = p.CNMI(cfg.Mode, cfg.MT, cfg.BM, cfg.DS, cfg.BFR); err == nil {
+		p.notifications = cfg
+	}
+	return
+}
+
+// ListenBroadcasts selects the cell broadcast channels, i.e. 4370-4383 for the emergency alerts,
+// and routes the pages to the CellBroadcasts channel. All channels are accepted if none are given.
+func (d *Device) ListenBroadcasts(channels ...int) error {
+	p, ok := d.Commands.(broadcastListener)
+	if !ok {
+		return ErrUnsupported
+	}
+	if err := p.CSCB(len(channels) > 0, channels); err != nil {
+		return fmt.Errorf("at: unable to select the broadcast channels: %w", err)
+	}
+	if err := p.routeBroadcasts(true); err != nil {
+		return fmt.Errorf("at: unable to route the broadcasts: %w", err)
+	}
+	return nil
+}
+
+// StopBroadcasts rejects all cell broadcast channels.
+func (d *Device) StopBroadcasts() error {
+	p, ok := d.Commands.(broadcastListener)
+	if !ok {
+		return ErrUnsupported
+	}
+	if err := p.routeBroadcasts(false); err != nil {
+		return fmt.Errorf("at: unable to route the broadcasts: %w", err)
+	}
+	if err := p.CSCB(true, nil); err != nil {
+		return fmt.Errorf("at: unable to select the broadcast channels: %w", err)
+	}
+	return nil
+}
+
+// CellBroadcasts fires when a cell broadcast page is received, see ListenBroadcasts.
+// The pages of a multipage message are delivered separately. The pages are dropped
+// if the channel is not consumed.
+func (d *Device) CellBroadcasts() <-chan *sms.CellBroadcast {
+	return d.broadcasts
+}
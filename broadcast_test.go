@@ -0,0 +1,34 @@
+package at
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/pdu"
+)
+
+func TestCellBroadcasts(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		`AT+CSCB=0,"4370,4371"`: "OK",
+		`AT+CSCB=0,""`:          "OK",
+		"AT+CNMI=0,0,2,0,0":     "OK",
+		"AT+CNMI=0,0,0,0,0":     "OK",
+	})
+	require.NoError(t, dev.ListenBroadcasts(4370, 4371))
+
+	text := "Emergency alert"
+	octets := append([]byte{0x63, 0x40, 0x11, 0x12, 0x01, 0x11}, pdu.Encode7Bit(text+strings.Repeat("\r", 93-len(text)))...)
+	require.NoError(t, dev.handleReport(fmt.Sprintf("+CBM: 88\n%02X", octets)))
+	page := <-dev.CellBroadcasts()
+	assert.Equal(t, 4370, page.MessageID)
+	assert.Equal(t, text, page.Text)
+	assert.ErrorIs(t, dev.handleReport("+CBM: 88"), ErrParseReport)
+
+	require.NoError(t, dev.StopBroadcasts())
+	assert.Error(t, dev.ListenBroadcasts(), "AT+CSCB=1 is not replied")
+}
@@ -0,0 +1,163 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// DefaultCanaryDeadline is the default time to wait for the canary message to come back.
+const DefaultCanaryDeadline = 2 * time.Minute
+
+// ErrCanaryLost happens when the canary message was not received in time.
+var ErrCanaryLost = errors.New("at: canary message was not received")
+
+// canaryPrefix starts the text of the canary messages, followed by a random token.
+const canaryPrefix = "at canary "
+
+// maxIssuedCanaries is the number of the recent canaries that are still caught
+// if they come back after the deadline.
+const maxIssuedCanaries = 100
+
+// ownNumberReader is implemented by profiles that are able to read the subscriber's number.
+type ownNumberReader interface {
+	OwnNumber() (str string, err error)
+}
+
+// Canary configures a periodic end-to-end check: an SMS is sent to the device's own number
+// (or to another device) and is expected to be received back before the deadline.
+type Canary struct {
+	// Address is the number to send the canary to, the own number (AT+CNUM) if empty.
+	// The receiving device must be watched, so it can catch the message.
+	Address sms.PhoneNumber
+	// Interval is the period between the checks.
+	Interval time.Duration
+	// Deadline overrides the default time to wait for the message (2m).
+	Deadline time.Duration
+}
+
+// CanaryResult is the outcome of a single canary check.
+type CanaryResult struct {
+	Time time.Time
+	// Latency is the time it took the message to come back.
+	Latency time.Duration
+	// Err is nil if the check has passed.
+	Err error
+}
+
+// canaries are the pending canary messages by their text, shared by all devices
+// so one device could catch the canary sent by another.
+var canaries = struct {
+	sync.Mutex
+	pending map[string]chan struct{}
+	// issued are the texts of the recent canaries.
+	issued []string
+}{
+	pending: make(map[string]chan struct{}),
+}
+
+// issueCanary generates the text of a canary message and registers it as pending,
+// the returned channel is closed once it is caught.
+func issueCanary() (string, chan struct{}) {
+	text := fmt.Sprintf("%s%08x", canaryPrefix, rand.Uint32())
+	received := make(chan struct{})
+	canaries.Lock()
+	defer canaries.Unlock()
+	canaries.pending[text] = received
+	canaries.issued = append(canaries.issued, text)
+	if len(canaries.issued) > maxIssuedCanaries {
+		canaries.issued = canaries.issued[1:]
+	}
+	return text, received
+}
+
+// caught reports whether the message is one of the issued canaries, the waiting
+// check is notified. Other messages are passed on, even if they look like a canary.
+func caught(msg *sms.Message) bool {
+	canaries.Lock()
+	defer canaries.Unlock()
+	if received, ok := canaries.pending[msg.Text]; ok {
+		close(received)
+		delete(canaries.pending, msg.Text)
+		return true
+	}
+	for _, text := range canaries.issued {
+		if text == msg.Text {
+			// came back after the deadline
+			return true
+		}
+	}
+	return false
+}
+
+// SelfTest sends a canary message to the given address (the own number if empty)
+// and waits until it is received back. Returns the round trip latency.
+func (d *Device) SelfTest(address sms.PhoneNumber, deadline time.Duration) (time.Duration, error) {
+	if len(address) == 0 {
+		p, ok := d.Commands.(ownNumberReader)
+		if !ok {
+			return 0, ErrUnsupported
+		}
+		number, err := p.OwnNumber()
+		if err != nil {
+			return 0, fmt.Errorf("at: unable to read the own number: %w", err)
+		}
+		address = sms.PhoneNumber(number)
+	}
+	if deadline == 0 {
+		deadline = DefaultCanaryDeadline
+	}
+
+	text, received := issueCanary()
+	defer func() {
+		canaries.Lock()
+		delete(canaries.pending, text)
+		canaries.Unlock()
+	}()
+
+	start := time.Now()
+	if err := d.SendSMS(text, address); err != nil {
+		return 0, err
+	}
+	t := time.NewTimer(deadline)
+	defer t.Stop()
+	select {
+	case <-received:
+		return time.Since(start), nil
+	case <-d.closed:
+		return 0, ErrClosed
+	case <-t.C:
+		// the send itself is counted by SendSMS
+		d.FailurePolicy.result(d, ErrCanaryLost)
+		return 0, ErrCanaryLost
+	}
+}
+
+// RunCanary runs the self-test periodically until the device is closed.
+// The results are dropped if the returned channel is not consumed.
+func (d *Device) RunCanary(c Canary) <-chan CanaryResult {
+	results := make(chan CanaryResult, 100)
+	go func() {
+		defer close(results)
+		t := time.NewTicker(c.Interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-d.closed:
+				return
+			case <-t.C:
+			}
+			result := CanaryResult{Time: time.Now()}
+			result.Latency, result.Err = d.SelfTest(c.Address, c.Deadline)
+			select {
+			case results <- result:
+			default:
+			}
+		}
+	}()
+	return results
+}
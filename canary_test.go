@@ -0,0 +1,34 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+)
+
+func TestCaught(t *testing.T) {
+	t.Parallel()
+
+	text, received := issueCanary()
+
+	assert.False(t, caught(&sms.Message{Text: "hello"}))
+	assert.False(t, caught(&sms.Message{Text: canaryPrefix + "test"}), "only the issued canaries are caught")
+	assert.True(t, caught(&sms.Message{Text: text}))
+	_, open := <-received
+	assert.False(t, open)
+	// a late canary is swallowed too
+	assert.True(t, caught(&sms.Message{Text: text}))
+}
+
+func TestOwnNumber(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CNUM": `+CNUM: "","+79261234567",145` + Sep + Sep + "OK",
+	})
+	number, err := dev.Commands.(ownNumberReader).OwnNumber()
+	require.NoError(t, err)
+	assert.Equal(t, "+79261234567", number)
+}
@@ -0,0 +1,175 @@
+package at
+
+import (
+	"fmt"
+	"strings"
+)
+
+var features = stringOpts{
+	{"sms", "Sending and receiving SMS"},
+	{"ussd", "USSD requests"},
+	{"voice", "Caller ID and hang up"},
+	{"data", "Transparent data mode"},
+	{"power", "Power modes"},
+	{"baudrate", "Baud rate configuration"},
+	{"carrierlock", "Carrier lock management"},
+	{"ownnumber", "Reading the own number"},
+	{"recording", "Call recording"},
+	{"boot", "BOOT handshake"},
+	{"gnss", "GNSS positioning"},
+	{"tcp", "Vendor TCP/IP stack"},
+	{"jamming", "Jamming detection"},
+}
+
+// Features represent the capabilities a device profile may have.
+var Features = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	SMS         StringOpt
+	USSD        StringOpt
+	Voice       StringOpt
+	Data        StringOpt
+	Power       StringOpt
+	BaudRate    StringOpt
+	CarrierLock StringOpt
+	OwnNumber   StringOpt
+	Recording   StringOpt
+	Boot        StringOpt
+	GNSS        StringOpt
+	TCP         StringOpt
+	Jamming     StringOpt
+}{
+	func(str string) StringOpt { return features.Resolve(str) },
+	featuresValues,
+
+	features[0], features[1], features[2], features[3],
+	features[4], features[5], features[6], features[7],
+	features[8], features[9], features[10], features[11],
+	features[12],
+}
+
+// Capabilities describes the device, so the clients could adapt to it.
+type Capabilities struct {
+	// Profile is the type name of the device profile.
+	Profile string
+	// Model is the modem's model name.
+	Model     string
+	Features  []StringOpt
+	Storages  []StringOpt
+	Encodings []string
+}
+
+// featureLister is implemented by profiles that declare the features of their commands.
+type featureLister interface {
+	Features() []StringOpt
+}
+
+// Features returns the features of the DefaultProfile commands (Huawei), the carrier lock
+// is managed with the vendor's AT^CARDLOCK.
+func (p *DefaultProfile) Features() []StringOpt {
+	return []StringOpt{
+		Features.SMS, Features.USSD, Features.Voice, Features.Data, Features.Power,
+		Features.BaudRate, Features.CarrierLock, Features.OwnNumber, Features.Boot, Features.Jamming,
+	}
+}
+
+// Features returns the features of the SIMComProfile commands, the call audio is
+// recorded only if CallRecording is set.
+func (p *SIMComProfile) Features() []StringOpt {
+	features := []StringOpt{
+		Features.SMS, Features.USSD, Features.Voice, Features.Data, Features.Power,
+		Features.BaudRate, Features.OwnNumber,
+	}
+	if p.CallRecording {
+		features = append(features, Features.Recording)
+	}
+	return features
+}
+
+// Features returns the features of the TelitProfile commands.
+func (p *TelitProfile) Features() []StringOpt {
+	return []StringOpt{
+		Features.SMS, Features.USSD, Features.Voice, Features.Data, Features.Power,
+		Features.BaudRate, Features.OwnNumber,
+	}
+}
+
+// Features returns the features of the ZTEProfile commands, the dongles have no voice.
+func (p *ZTEProfile) Features() []StringOpt {
+	return []StringOpt{
+		Features.SMS, Features.USSD, Features.Data, Features.Power,
+		Features.BaudRate, Features.OwnNumber,
+	}
+}
+
+// Features returns the features of the GenericProfile commands, the carrier lock
+// is probed with the standard AT+CLCK.
+func (p *GenericProfile) Features() []StringOpt {
+	return []StringOpt{
+		Features.SMS, Features.USSD, Features.Voice, Features.Data, Features.Power,
+		Features.BaudRate, Features.CarrierLock, Features.OwnNumber,
+	}
+}
+
+// features lists the features declared by the device's profile. Every profile embeds
+// the DefaultProfile and so implements all the commands, the declaration is what
+// tells which of them the vendor actually supports.
+func (d *Device) features() []StringOpt {
+	var features []StringOpt
+	if p, ok := d.Commands.(featureLister); ok {
+		features = append(features, p.Features()...)
+	} else {
+		// the methods of the DeviceProfile interface
+		features = append(features, Features.SMS, Features.USSD, Features.Voice, Features.Data)
+	}
+	if !hasDataMode {
+		features = without(features, Features.Data)
+	}
+	return features
+}
+
+// without returns the features except the given one.
+func without(features []StringOpt, feature StringOpt) []StringOpt {
+	var filtered []StringOpt
+	for _, f := range features {
+		if f != feature {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
+// Supports reports whether the device's profile has the feature, so the applications
+// could degrade gracefully instead of failing at runtime.
+func (d *Device) Supports(feature StringOpt) bool {
+	for _, f := range d.features() {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities describes the features of the device's profile, the supported message
+// storages and encodings.
+func (d *Device) Capabilities() (*Capabilities, error) {
+	if err := d.sanityCheck(true); err != nil {
+		return nil, err
+	}
+	c := &Capabilities{
+		Profile:   strings.TrimPrefix(fmt.Sprintf("%T", d.Commands), "*"),
+		Features:  d.features(),
+		Encodings: []string{"GSM 7-bit", "UCS2"},
+	}
+	if d.State != nil {
+		c.Model = d.State.ModelName
+	}
+	if p, ok := d.Commands.(interface {
+		SupportedStorages() ([]StringOpt, error)
+	}); ok {
+		// not every device reports them
+		c.Storages, _ = p.SupportedStorages()
+	}
+	return c, nil
+}
@@ -0,0 +1,48 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CPMS=?": `+CPMS: ("SM","ME"),("SM","ME"),("SM")` + Sep + Sep + "OK",
+	})
+	caps, err := dev.Capabilities()
+	require.NoError(t, err)
+	assert.Equal(t, "at.noopProfile", caps.Profile)
+	assert.Contains(t, caps.Features, Features.SMS)
+	assert.Contains(t, caps.Features, Features.Power)
+	assert.Equal(t, []StringOpt{MemoryTypes.Sim}, caps.Storages)
+}
+
+func TestSupports(t *testing.T) {
+	t.Parallel()
+
+	dev := &Device{Commands: DeviceMF823()}
+	assert.True(t, dev.Supports(Features.SMS))
+	assert.False(t, dev.Supports(Features.Voice))
+	assert.False(t, dev.Supports(Features.Boot))
+	assert.True(t, dev.Supports(Features.Power))
+
+	dev.Commands = DeviceE173()
+	assert.True(t, dev.Supports(Features.Boot))
+	assert.False(t, dev.Supports(Features.GNSS))
+	assert.False(t, dev.Supports(Features.Recording))
+
+	dev.Commands = DeviceSIM7600()
+	assert.True(t, dev.Supports(Features.Recording))
+	assert.False(t, dev.Supports(Features.CarrierLock))
+
+	dev.Commands = DeviceLE910()
+	assert.False(t, dev.Supports(Features.Recording))
+	assert.False(t, dev.Supports(Features.CarrierLock))
+
+	dev.Commands = DeviceGeneric()
+	assert.True(t, dev.Supports(Features.CarrierLock))
+}
@@ -0,0 +1,160 @@
+package at
+
+import (
+	"context"
+	"errors"
+
+	"github.com/xlab/at/sms"
+)
+
+// ErrDivertedFull is returned when the diverted message can't be delivered, the Diverted
+// channel is full. The message is not processed then, it's left in the storage.
+var ErrDivertedFull = errors.New("at: the Diverted channel is full")
+
+// MaxClassifications is the number of the classifications kept until they're read,
+// the oldest ones are forgotten.
+const MaxClassifications = 1000
+
+var verdicts = stringOpts{
+	{"deliver", "Delivered as is"},
+	{"tag", "Delivered with the labels"},
+	{"divert", "Delivered to the Diverted channel instead"},
+	{"drop", "Dropped"},
+}
+
+// Verdicts represent the decisions of a Classifier on an incoming message.
+var Verdicts = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	Deliver StringOpt
+	Tag     StringOpt
+	Divert  StringOpt
+	Drop    StringOpt
+}{
+	func(str string) StringOpt { return verdicts.Resolve(str) },
+	verdictsValues,
+
+	verdicts[0], verdicts[1], verdicts[2], verdicts[3],
+}
+
+// Classification is the verdict of a Classifier, the labels and the score are up
+// to the classifier, i.e. "spam" with the probability.
+type Classification struct {
+	Verdict StringOpt
+	Labels  []string
+	Score   float64
+}
+
+// Classifier classifies the incoming messages before they are delivered to the IncomingSms
+// channel, i.e. a rule-based or an ML spam filter. The message is delivered as is if
+// the classifier fails.
+type Classifier interface {
+	Classify(ctx context.Context, msg *sms.Message) (Classification, error)
+}
+
+// ClassifierFunc is a function used as a Classifier.
+type ClassifierFunc func(ctx context.Context, msg *sms.Message) (Classification, error)
+
+// Classify calls f(ctx, msg).
+func (f ClassifierFunc) Classify(ctx context.Context, msg *sms.Message) (Classification, error) {
+	return f(ctx, msg)
+}
+
+// DivertedMessage is an incoming message diverted by the Classifier.
+type DivertedMessage struct {
+	Message        *sms.Message
+	Classification Classification
+}
+
+// deliver classifies the message, if the Classifier is set, and delivers it according
+// to the verdict. The classification runs in the background if ClassifyAsync is set,
+// so a slow classifier doesn't hold the reports, the order of the messages is not kept then.
+// The commit is called once the message is delivered, see receive. In the background
+// it's called only if the message is delivered before the device is closed, the failed
+// message stays in the storage.
+func (d *Device) deliver(ctx context.Context, msg *sms.Message, commit func() error) error {
+	if d.Classifier == nil || msg.Type != sms.MessageTypes.Deliver {
+		if err := d.push(ctx, msg); err != nil {
+			return err
+		}
+		return committed(commit)
+	}
+	if d.ClassifyAsync {
+		// the classification outlives the command that has read the message
+		go func(ctx context.Context) {
+			if err := d.classify(ctx, msg); err == nil {
+				committed(commit)
+			} else {
+				d.forget(msg)
+			}
+		}(d.session())
+		return nil
+	}
+	if err := d.classify(ctx, msg); err != nil {
+		return err
+	}
+	return committed(commit)
+}
+
+func (d *Device) classify(ctx context.Context, msg *sms.Message) error {
+	c, err := d.Classifier.Classify(ctx, msg)
+	if err != nil {
+		return d.push(ctx, msg)
+	}
+	switch c.Verdict {
+	case Verdicts.Drop:
+		return d.MarkProcessed(msg)
+	case Verdicts.Divert:
+		select {
+		case d.diverted <- DivertedMessage{Message: msg, Classification: c}:
+		default:
+			return ErrDivertedFull
+		}
+		return d.MarkProcessed(msg)
+	case Verdicts.Tag:
+		d.tag(msg, c)
+	}
+	return d.push(ctx, msg)
+}
+
+// tag keeps the classification of the message, up to MaxClassifications of them.
+func (d *Device) tag(msg *sms.Message, c Classification) {
+	d.classifiedMux.Lock()
+	defer d.classifiedMux.Unlock()
+	if d.classified == nil {
+		d.classified = make(map[*sms.Message]Classification)
+		d.classifiedRing = make([]*sms.Message, MaxClassifications)
+	}
+	if oldest := d.classifiedRing[d.classifiedNext]; oldest != nil {
+		delete(d.classified, oldest)
+	}
+	d.classifiedRing[d.classifiedNext] = msg
+	d.classifiedNext = (d.classifiedNext + 1) % len(d.classifiedRing)
+	d.classified[msg] = c
+}
+
+func (d *Device) push(ctx context.Context, msg *sms.Message) error {
+	select {
+	case d.messages <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Classification returns the classification of the message tagged by the Classifier.
+// The classification is kept until it's read, so it's returned once, but only the last
+// MaxClassifications of them are kept.
+func (d *Device) Classification(msg *sms.Message) (Classification, bool) {
+	d.classifiedMux.Lock()
+	defer d.classifiedMux.Unlock()
+	c, ok := d.classified[msg]
+	delete(d.classified, msg)
+	return c, ok
+}
+
+// Diverted returns the channel of the incoming messages diverted by the Classifier.
+func (d *Device) Diverted() <-chan DivertedMessage {
+	return d.diverted
+}
@@ -0,0 +1,164 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xlab/at/sms"
+)
+
+// spamFilter drops the casino ads, diverts the links and tags the promotions.
+var spamFilter = ClassifierFunc(func(ctx context.Context, msg *sms.Message) (Classification, error) {
+	switch {
+	case strings.Contains(msg.Text, "casino"):
+		return Classification{Verdict: Verdicts.Drop}, nil
+	case strings.Contains(msg.Text, "http"):
+		return Classification{Verdict: Verdicts.Divert, Labels: []string{"phishing"}, Score: 0.9}, nil
+	case strings.Contains(msg.Text, "sale"):
+		return Classification{Verdict: Verdicts.Tag, Labels: []string{"promo"}}, nil
+	case strings.Contains(msg.Text, "fail"):
+		return Classification{}, errors.New("the model is not loaded")
+	}
+	return Classification{Verdict: Verdicts.Deliver}, nil
+})
+
+func cmtReport(t *testing.T, text string) string {
+	t.Helper()
+	msg := sms.Message{
+		Type:                 sms.MessageTypes.Deliver,
+		Encoding:             sms.Encodings.Gsm7Bit,
+		Address:              "+79269965690",
+		ServiceCenterAddress: "+79262000331",
+		Text:                 text,
+	}
+	n, octets, err := msg.PDU()
+	require.NoError(t, err)
+	return fmt.Sprintf("+CMT: ,%d\n%02X", n, octets)
+}
+
+func TestClassifier(t *testing.T) {
+	t.Parallel()
+
+	store := &memoryMessageStore{processed: make(map[string]bool)}
+	dev, _ := newTestDevice(t, nil)
+	dev.MessageStore = store
+	dev.Classifier = spamFilter
+
+	for _, text := range []string{"Win at the casino", "Verify at http://bank.example", "Big sale", "fail", "Hello"} {
+		require.NoError(t, dev.handleReport(cmtReport(t, text)))
+	}
+	diverted := <-dev.Diverted()
+	assert.Equal(t, "Verify at http://bank.example", diverted.Message.Text)
+	assert.Equal(t, []string{"phishing"}, diverted.Classification.Labels)
+
+	promo := <-dev.IncomingSms()
+	assert.Equal(t, "Big sale", promo.Text)
+	c, ok := dev.Classification(promo)
+	assert.True(t, ok)
+	assert.Equal(t, Verdicts.Tag, c.Verdict)
+	_, ok = dev.Classification(promo)
+	assert.False(t, ok)
+
+	// the message is delivered if the classifier fails
+	assert.Equal(t, "fail", (<-dev.IncomingSms()).Text)
+	msg := <-dev.IncomingSms()
+	assert.Equal(t, "Hello", msg.Text)
+	_, ok = dev.Classification(msg)
+	assert.False(t, ok)
+	assert.Empty(t, dev.IncomingSms())
+
+	// the dropped and the diverted messages are not redelivered
+	list, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, list, 3)
+}
+
+func TestClassifierAsync(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, nil)
+	release := make(chan struct{})
+	dev.ClassifyAsync = true
+	dev.Classifier = ClassifierFunc(func(ctx context.Context, msg *sms.Message) (Classification, error) {
+		<-release
+		return spamFilter(ctx, msg)
+	})
+
+	require.NoError(t, dev.handleReport(cmtReport(t, "Win at the casino")))
+	require.NoError(t, dev.handleReport(cmtReport(t, "Hello")))
+
+	// the message is removed from the device only once it's delivered,
+	// the context of the fetch doesn't bound the classification
+	ctx, cancel := context.WithCancel(context.Background())
+	committed := make(chan struct{})
+	msg := &sms.Message{Type: sms.MessageTypes.Deliver, Address: "+79269965690", Text: "Bye"}
+	require.NoError(t, dev.receiveMessage(ctx, msg, func() error {
+		close(committed)
+		return nil
+	}))
+	cancel()
+	select {
+	case <-committed:
+		t.Fatal("the message was removed before it's delivered")
+	default:
+	}
+	close(release)
+	var texts []string
+	for len(texts) < 2 {
+		select {
+		case msg := <-dev.IncomingSms():
+			texts = append(texts, msg.Text)
+		case <-time.After(time.Second):
+			t.Fatal("the message was not delivered")
+		}
+	}
+	assert.ElementsMatch(t, []string{"Hello", "Bye"}, texts)
+	select {
+	case <-committed:
+	case <-time.After(time.Second):
+		t.Fatal("the message was not removed")
+	}
+}
+
+func TestClassifierDivertedFull(t *testing.T) {
+	t.Parallel()
+
+	store := &memoryMessageStore{processed: make(map[string]bool)}
+	dev, _ := newTestDevice(t, nil)
+	dev.MessageStore = store
+	dev.Classifier = spamFilter
+	dev.diverted = make(chan DivertedMessage)
+
+	var commits int
+	msg := &sms.Message{Type: sms.MessageTypes.Deliver, Address: "+79269965690", Text: "Verify at http://bank.example"}
+	err := dev.receiveMessage(context.Background(), msg, func() error {
+		commits++
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrDivertedFull)
+	assert.Zero(t, commits)
+	list, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, list, 1, "the message should be left for Redeliver")
+}
+
+func TestClassificationsLimit(t *testing.T) {
+	t.Parallel()
+
+	dev := &Device{}
+	first := &sms.Message{}
+	dev.tag(first, Classification{Verdict: Verdicts.Tag})
+	for i := 0; i < MaxClassifications; i++ {
+		dev.tag(&sms.Message{}, Classification{Verdict: Verdicts.Tag})
+	}
+	assert.Len(t, dev.classified, MaxClassifications)
+	_, ok := dev.Classification(first)
+	assert.False(t, ok, "the oldest classification should be forgotten")
+}
@@ -0,0 +1,375 @@
+// Package cmux implements the basic mode of the GSM 07.10 (3GPP TS 27.010) multiplexer
+// protocol. It allows to carry several virtual channels over one physical serial port,
+// i.e. AT commands, unsolicited reports and PPP data at the same time.
+//
+// The channels implement io.ReadWriteCloser, so they could be attached to at.Device:
+//
+//	mux, err := cmux.Start(port, cmux.Config{})
+//	cmd, err := mux.Open(1)
+//	notify, err := mux.Open(2)
+//	err = dev.Attach(cmd, notify)
+package cmux
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the multiplexer parameters.
+const (
+	DefaultFrameSize = 31
+	DefaultTimeout   = 3 * time.Second
+)
+
+// Common errors.
+var (
+	ErrTimeout  = errors.New("cmux: timeout")
+	ErrRejected = errors.New("cmux: channel was rejected")
+	ErrClosed   = errors.New("cmux: closed")
+	ErrChannel  = errors.New("cmux: invalid channel number")
+)
+
+// Config holds the multiplexer parameters.
+type Config struct {
+	// FrameSize is the maximum length of the information field (N1), defaults to 31.
+	FrameSize int
+	// Timeout to wait for the acknowledgement (T1) and the AT+CMUX reply, defaults to 3s.
+	Timeout time.Duration
+}
+
+// Mux is the multiplexer that runs over a physical port.
+type Mux struct {
+	rwc io.ReadWriteCloser
+	cfg Config
+
+	writeMux sync.Mutex
+
+	mux      sync.Mutex
+	channels map[int]*Channel
+	acks     map[int]chan byte
+	closed   chan struct{}
+	err      error
+}
+
+// Start switches the modem into the multiplexer mode using AT+CMUX=0 and starts
+// the multiplexer over the port. The control channel is opened as well.
+func Start(rwc io.ReadWriteCloser, cfg Config) (*Mux, error) {
+	if _, err := io.WriteString(rwc, "AT+CMUX=0\r\n"); err != nil {
+		return nil, err
+	}
+	buf := bufio.NewReader(rwc)
+	deadline := time.Now().Add(cfg.timeout())
+	for {
+		if time.Now().After(deadline) {
+			return nil, ErrTimeout
+		}
+		line, err := buf.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if text := strings.TrimSpace(line); text == "OK" {
+			break
+		} else if strings.Contains(text, "ERROR") {
+			return nil, errors.New("cmux: " + text)
+		}
+	}
+	return New(rwc, cfg)
+}
+
+// New starts the multiplexer over the port that is already in the multiplexer mode
+// and opens the control channel.
+func New(rwc io.ReadWriteCloser, cfg Config) (*Mux, error) {
+	m := &Mux{
+		rwc:      rwc,
+		cfg:      cfg,
+		channels: make(map[int]*Channel),
+		acks:     make(map[int]chan byte),
+		closed:   make(chan struct{}),
+	}
+	go m.read()
+	if err := m.connect(0); err != nil {
+		m.rwc.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c Config) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+func (c Config) frameSize() int {
+	if c.FrameSize == 0 {
+		return DefaultFrameSize
+	}
+	return c.FrameSize
+}
+
+// Open establishes the virtual channel with the given number (1-63).
+func (m *Mux) Open(dlci int) (*Channel, error) {
+	if dlci < 1 || dlci > 63 {
+		return nil, ErrChannel
+	}
+	ch := &Channel{mux: m, dlci: dlci}
+	ch.cond = sync.NewCond(&ch.lock)
+	m.mux.Lock()
+	m.channels[dlci] = ch
+	m.mux.Unlock()
+
+	if err := m.connect(dlci); err != nil {
+		m.mux.Lock()
+		delete(m.channels, dlci)
+		m.mux.Unlock()
+		return nil, err
+	}
+	// set the V.24 signals: ready to communicate, ready to receive
+	msc := []byte{msgMSC | 0x02, 0x05, byte(dlci<<2) | 0x03, 0x8D}
+	if err := m.write(&frame{dlci: 0, cr: true, control: typeUIH, data: msc}); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Close closes all the channels, sends the close down command and closes the port.
+func (m *Mux) Close() error {
+	m.mux.Lock()
+	channels := make([]*Channel, 0, len(m.channels))
+	for _, ch := range m.channels {
+		channels = append(channels, ch)
+	}
+	m.mux.Unlock()
+	for _, ch := range channels {
+		ch.Close()
+	}
+	m.write(&frame{dlci: 0, cr: true, control: typeUIH, data: []byte{msgCLD | 0x02, 0x01}})
+	m.shutdown(ErrClosed)
+	return m.rwc.Close()
+}
+
+// connect sends SABM for the given channel and waits for the acknowledgement.
+func (m *Mux) connect(dlci int) error {
+	reply, err := m.command(dlci, typeSABM)
+	if err != nil {
+		return err
+	}
+	if reply != typeUA {
+		return ErrRejected
+	}
+	return nil
+}
+
+// command sends a command frame and waits for the reply type.
+func (m *Mux) command(dlci int, control byte) (byte, error) {
+	ack := make(chan byte, 1)
+	m.mux.Lock()
+	m.acks[dlci] = ack
+	m.mux.Unlock()
+	defer func() {
+		m.mux.Lock()
+		delete(m.acks, dlci)
+		m.mux.Unlock()
+	}()
+
+	if err := m.write(&frame{dlci: dlci, cr: true, control: control | pollFinal}); err != nil {
+		return 0, err
+	}
+	t := time.NewTimer(m.cfg.timeout())
+	defer t.Stop()
+	select {
+	case reply := <-ack:
+		return reply, nil
+	case <-m.closed:
+		return 0, m.err
+	case <-t.C:
+		return 0, ErrTimeout
+	}
+}
+
+func (m *Mux) write(f *frame) error {
+	m.writeMux.Lock()
+	defer m.writeMux.Unlock()
+	_, err := m.rwc.Write(f.Bytes())
+	return err
+}
+
+// read dispatches the incoming frames until the port fails.
+func (m *Mux) read() {
+	buf := bufio.NewReader(m.rwc)
+	for {
+		f, err := readFrame(buf)
+		if errors.Is(err, ErrBadFrame) {
+			continue
+		}
+		if err != nil {
+			m.shutdown(err)
+			return
+		}
+		switch control := f.control &^ pollFinal; control {
+		case typeUA, typeDM:
+			m.mux.Lock()
+			ack, ok := m.acks[f.dlci]
+			m.mux.Unlock()
+			if ok {
+				ack <- control
+			}
+			if control == typeDM {
+				m.remove(f.dlci)
+			}
+		case typeDISC:
+			m.write(&frame{dlci: f.dlci, control: typeUA | pollFinal})
+			m.remove(f.dlci)
+		case typeUIH, typeUI:
+			if f.dlci == 0 {
+				m.control(f.data)
+				continue
+			}
+			m.mux.Lock()
+			ch, ok := m.channels[f.dlci]
+			m.mux.Unlock()
+			if ok {
+				ch.push(f.data)
+			}
+		}
+	}
+}
+
+// control handles the messages of the control channel.
+func (m *Mux) control(data []byte) {
+	if len(data) < 2 {
+		return
+	}
+	if data[0]&0x02 == 0 {
+		// a response to our command
+		return
+	}
+	switch data[0] &^ 0x02 {
+	case msgMSC:
+		// acknowledge the modem status
+		reply := append([]byte{data[0] &^ 0x02}, data[1:]...)
+		m.write(&frame{dlci: 0, cr: true, control: typeUIH, data: reply})
+	case msgCLD:
+		m.shutdown(ErrClosed)
+	}
+}
+
+// remove detaches the channel that was closed by the modem.
+func (m *Mux) remove(dlci int) {
+	m.mux.Lock()
+	ch, ok := m.channels[dlci]
+	delete(m.channels, dlci)
+	m.mux.Unlock()
+	if ok {
+		ch.shutdown(io.EOF)
+	}
+}
+
+// shutdown stops all the channels with the given error.
+func (m *Mux) shutdown(err error) {
+	m.mux.Lock()
+	select {
+	case <-m.closed:
+		m.mux.Unlock()
+		return
+	default:
+	}
+	m.err = err
+	close(m.closed)
+	channels := m.channels
+	m.channels = make(map[int]*Channel)
+	m.mux.Unlock()
+	for _, ch := range channels {
+		ch.shutdown(err)
+	}
+}
+
+// Channel is a virtual channel of the multiplexer.
+type Channel struct {
+	mux  *Mux
+	dlci int
+
+	lock sync.Mutex
+	cond *sync.Cond
+	buf  bytes.Buffer
+	err  error
+}
+
+// DLCI returns the number of the channel.
+func (c *Channel) DLCI() int {
+	return c.dlci
+}
+
+// Read reads the data received over the channel.
+func (c *Channel) Read(b []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for c.buf.Len() == 0 && c.err == nil {
+		c.cond.Wait()
+	}
+	if c.buf.Len() > 0 {
+		return c.buf.Read(b)
+	}
+	return 0, c.err
+}
+
+// Write sends the data over the channel, splitting it into frames.
+func (c *Channel) Write(b []byte) (n int, err error) {
+	c.lock.Lock()
+	err = c.err
+	c.lock.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	size := c.mux.cfg.frameSize()
+	for n < len(b) {
+		end := n + size
+		if end > len(b) {
+			end = len(b)
+		}
+		f := &frame{dlci: c.dlci, cr: true, control: typeUIH, data: b[n:end]}
+		if err = c.mux.write(f); err != nil {
+			return
+		}
+		n = end
+	}
+	return
+}
+
+// Close disconnects the channel.
+func (c *Channel) Close() error {
+	c.lock.Lock()
+	closed := c.err != nil
+	c.lock.Unlock()
+	if closed {
+		return nil
+	}
+	c.mux.command(c.dlci, typeDISC) // the modem may not reply, ignore errors
+	c.mux.mux.Lock()
+	delete(c.mux.channels, c.dlci)
+	c.mux.mux.Unlock()
+	c.shutdown(ErrClosed)
+	return nil
+}
+
+func (c *Channel) push(data []byte) {
+	c.lock.Lock()
+	c.buf.Write(data)
+	c.lock.Unlock()
+	c.cond.Broadcast()
+}
+
+func (c *Channel) shutdown(err error) {
+	c.lock.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.lock.Unlock()
+	c.cond.Broadcast()
+}
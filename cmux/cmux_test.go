@@ -0,0 +1,79 @@
+package cmux
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrame(t *testing.T) {
+	t.Parallel()
+
+	sabm := &frame{dlci: 0, cr: true, control: typeSABM | pollFinal}
+	assert.Equal(t, []byte{0xF9, 0x03, 0x3F, 0x01, 0x1C, 0xF9}, sabm.Bytes())
+
+	long := &frame{dlci: 2, cr: true, control: typeUIH, data: bytes.Repeat([]byte{'A'}, 200)}
+	garbage := append([]byte("\r\nOK\r\n"), long.Bytes()...)
+	f, err := readFrame(bufio.NewReader(bytes.NewReader(garbage)))
+	require.NoError(t, err)
+	assert.Equal(t, long, f)
+
+	broken := sabm.Bytes()
+	broken[4]++
+	_, err = readFrame(bufio.NewReader(bytes.NewReader(broken)))
+	assert.ErrorIs(t, err, ErrBadFrame)
+}
+
+// serveMux emulates a modem in the multiplexer mode that acknowledges all the channels
+// and echoes the data received over them.
+func serveMux(conn io.ReadWriter) {
+	buf := bufio.NewReader(conn)
+	for {
+		f, err := readFrame(buf)
+		if err != nil {
+			return
+		}
+		var reply *frame
+		switch f.control &^ pollFinal {
+		case typeSABM, typeDISC:
+			reply = &frame{dlci: f.dlci, control: typeUA | pollFinal}
+		case typeUIH:
+			if f.dlci != 0 {
+				reply = &frame{dlci: f.dlci, control: typeUIH, data: f.data}
+			}
+		}
+		if reply != nil {
+			conn.Write(reply.Bytes())
+		}
+	}
+}
+
+func TestMux(t *testing.T) {
+	t.Parallel()
+
+	local, remote := net.Pipe()
+	go serveMux(remote)
+	mux, err := New(local, Config{Timeout: time.Second})
+	require.NoError(t, err)
+	defer mux.Close()
+
+	ch, err := mux.Open(1)
+	require.NoError(t, err)
+	payload := bytes.Repeat([]byte("AT\r\n"), 20)
+	go ch.Write(payload)
+
+	received := make([]byte, len(payload))
+	_, err = io.ReadFull(ch, received)
+	require.NoError(t, err)
+	assert.Equal(t, payload, received)
+
+	require.NoError(t, ch.Close())
+	_, err = ch.Write(payload)
+	assert.ErrorIs(t, err, ErrClosed)
+}
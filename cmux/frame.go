@@ -0,0 +1,150 @@
+package cmux
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Frame flag and frame types (3GPP TS 27.010, section 5.2).
+const (
+	flag byte = 0xF9
+
+	typeSABM byte = 0x2F // Set Asynchronous Balanced Mode
+	typeUA   byte = 0x63 // Unnumbered Acknowledgement
+	typeDM   byte = 0x0F // Disconnected Mode
+	typeDISC byte = 0x43 // Disconnect
+	typeUIH  byte = 0xEF // Unnumbered Information with Header check
+	typeUI   byte = 0x03 // Unnumbered Information
+
+	pollFinal byte = 0x10
+)
+
+// Control channel message types (3GPP TS 27.010, section 5.4.6.3).
+const (
+	msgCLD byte = 0xC1 // Multiplexer close down
+	msgMSC byte = 0xE1 // Modem status command
+)
+
+// ErrBadFrame happens when the received frame is malformed or its checksum mismatches.
+var ErrBadFrame = errors.New("cmux: bad frame")
+
+var crcTable = func() (table [256]byte) {
+	for i := range table {
+		crc := byte(i)
+		for j := 0; j < 8; j++ {
+			if crc&0x01 != 0 {
+				crc = crc>>1 ^ 0xE0
+			} else {
+				crc >>= 1
+			}
+		}
+		table[i] = crc
+	}
+	return
+}()
+
+// fcs computes the frame check sequence over the given octets.
+func fcs(octets []byte) byte {
+	crc := byte(0xFF)
+	for _, b := range octets {
+		crc = crcTable[crc^b]
+	}
+	return 0xFF - crc
+}
+
+// frame represents a basic mode frame.
+type frame struct {
+	dlci    int
+	cr      bool
+	control byte
+	data    []byte
+}
+
+// Bytes encodes the frame in the basic mode.
+func (f *frame) Bytes() []byte {
+	address := byte(f.dlci<<2) | 0x01
+	if f.cr {
+		address |= 0x02
+	}
+	header := []byte{address, f.control}
+	if n := len(f.data); n <= 0x7F {
+		header = append(header, byte(n<<1)|0x01)
+	} else {
+		header = append(header, byte(n<<1), byte(n>>7))
+	}
+	out := make([]byte, 0, len(header)+len(f.data)+3)
+	out = append(out, flag)
+	out = append(out, header...)
+	out = append(out, f.data...)
+	if f.control&^pollFinal == typeUIH {
+		out = append(out, fcs(header))
+	} else {
+		out = append(out, fcs(append(header, f.data...)))
+	}
+	return append(out, flag)
+}
+
+// readFrame reads the next frame from the stream, skipping the garbage before the flag.
+func readFrame(buf *bufio.Reader) (*frame, error) {
+	var b byte
+	var err error
+	for b != flag {
+		if b, err = buf.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	// the closing flag of a frame may be the opening flag of the next one
+	for b == flag {
+		if b, err = buf.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	header := []byte{b}
+	if b, err = buf.ReadByte(); err != nil {
+		return nil, err
+	}
+	header = append(header, b)
+	if b, err = buf.ReadByte(); err != nil {
+		return nil, err
+	}
+	header = append(header, b)
+	n := int(b >> 1)
+	if b&0x01 == 0 {
+		if b, err = buf.ReadByte(); err != nil {
+			return nil, err
+		}
+		header = append(header, b)
+		n |= int(b) << 7
+	}
+	data := make([]byte, n)
+	if _, err = io.ReadFull(buf, data); err != nil {
+		return nil, err
+	}
+	check, err := buf.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if b, err = buf.ReadByte(); err != nil {
+		return nil, err
+	}
+	if b != flag {
+		buf.UnreadByte()
+		return nil, ErrBadFrame
+	}
+	f := &frame{
+		dlci:    int(header[0] >> 2),
+		cr:      header[0]&0x02 != 0,
+		control: header[1],
+		data:    data,
+	}
+	expected := fcs(header)
+	if f.control&^pollFinal != typeUIH {
+		expected = fcs(append(header, data...))
+	}
+	if check != expected {
+		buf.UnreadByte() // the flag may start the next frame
+		return nil, ErrBadFrame
+	}
+	return f, nil
+}
@@ -1,9 +1,12 @@
 package at
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/xlab/at/calls"
 	"github.com/xlab/at/pdu"
@@ -14,23 +17,52 @@ import (
 // DeviceProfile hides the device-specific implementation
 // and provides a set of methods that can be used on a device.
 // Init should be called first.
+//
+// The profile is a union of the capability interfaces, a profile for a limited device
+// could embed UnsupportedCommands and implement only the interfaces it supports.
 type DeviceProfile interface {
 	Init(*Device) error
+	SMSCommander
+	USSDCommander
+	CallCommander
+	NetworkCommander
+	IdentityCommander
+}
+
+// SMSCommander provides the commands to send, receive and store SMS messages.
+type SMSCommander interface {
 	CMGS(length int, octets []byte) (byte, error)
-	CUSD(reporting Opt, octets []byte, enc Encoding) (err error)
 	CMGR(index uint16) (octets []byte, err error)
 	CMGD(index uint16, option Opt) (err error)
 	CMGL(flag Opt) (octets []MessageSlot, err error)
 	CMGF(text bool) (err error)
-	CLIP(text bool) (err error)
-	CHUP() (err error)
 	CNMI(mode, mt, bm, ds, bfr int) (err error)
 	CPMS(mem1 StringOpt, mem2 StringOpt, mem3 StringOpt) (err error)
+	FetchInbox() (err error)
+}
+
+// USSDCommander provides the command to send USSD requests.
+type USSDCommander interface {
+	CUSD(reporting Opt, octets []byte, enc Encoding) (err error)
+}
+
+// CallCommander provides the commands to handle the incoming calls.
+type CallCommander interface {
+	CLIP(text bool) (err error)
+	CHUP() (err error)
+}
+
+// NetworkCommander provides the commands to configure and query the cellular network.
+type NetworkCommander interface {
 	BOOT(token uint64) (err error)
 	SYSCFG(roaming, cellular bool) (err error)
 	SYSINFO() (info *SystemInfoReport, err error)
 	COPS(auto bool, text bool) (err error)
 	OperatorName() (str string, err error)
+}
+
+// IdentityCommander provides the commands to identify the device.
+type IdentityCommander interface {
 	ModelName() (str string, err error)
 	IMEI() (str string, err error)
 }
@@ -44,19 +76,87 @@ func DeviceE173() DeviceProfile {
 // DefaultProfile is a reference implementation that could be embedded
 // in any other custom implementation of the DeviceProfile interface.
 type DefaultProfile struct {
-	dev *Device
+	// Storage overrides the message storage selected during Init. If left empty,
+	// the storage is picked automatically from the ones reported by AT+CPMS=?.
+	Storage StringOpt
+	// CommandTimeouts overrides the reply timeouts of particular commands,
+	// see also DefaultCommandTimeouts.
+	CommandTimeouts TimeoutPolicy
+	// NotificationConfigs overrides the AT+CNMI settings tried on Init in order,
+	// see DefaultNotificationConfigs.
+	NotificationConfigs []CNMIConfig
+	// GuardTime is the minimum delay between the commands, a quirk for the firmwares
+	// that drop a command sent right after the previous reply. Zero by default.
+	GuardTime time.Duration
+	// InboxTimeout limits the time spent on the stored messages during Init, the rest
+	// of them stays in the storage. Unlimited if zero.
+	InboxTimeout time.Duration
+	// DeferInbox makes Init return without waiting for the stored messages,
+	// they're fetched in background then.
+	DeferInbox bool
+	// InboxPolicy is applied to the fetched messages, InboxPolicies.Delete if empty.
+	InboxPolicy StringOpt
+	// MessageListParser overrides the parsing of the AT+CMGL replies, for the modems
+	// that format them differently. ParseMessageList if nil.
+	MessageListParser MessageListParser
+	// TextMode makes Init switch to the text mode (AT+CMGF=1) instead of the PDU one,
+	// a fallback for the modems with broken PDU support. Only the GSM 7-bit texts
+	// are sent then, without the status reports and the concatenation.
+	TextMode bool
+	// DisableCallerID skips AT+CLIP=1 on Init, so the caller ID isn't reported.
+	DisableCallerID bool
+	// DisableBootHandshake stops replying to the ^BOOT reports with AT^BOOT, the application
+	// completes the handshake itself then.
+	DisableBootHandshake bool
+	// DisableMessageAck skips AT+CNMA after the messages routed directly to the terminal,
+	// for the firmwares that acknowledge them by themselves.
+	DisableMessageAck bool
+	// DisableCSMS skips the negotiation of the phase 2+ message service with AT+CSMS=1 on Init.
+	DisableCSMS bool
+	// IMS routes the messages directly to the terminal with IMSNotificationConfigs, for the
+	// LTE-only modules that receive them over IMS and don't store them. It takes effect only
+	// if the phase 2+ service is negotiated, so the messages could be acknowledged.
+	IMS bool
+	// Terminators are the non-standard final responses of the firmware, they complete
+	// the commands in addition to the standard ones like OK and ERROR.
+	Terminators []Terminator
+	// InitCommands are sent at the end of Init, before fetching the inbox.
+	InitCommands []string
+	// BeforeInit and AfterInit are invoked around the profile's Init, also when the device
+	// is re-initialized, see WithBeforeInit and WithAfterInit.
+	BeforeInit []InitHook
+	AfterInit  []InitHook
+
+	dev      *Device
+	textMode bool
+	// service is the message service negotiated on Init, UnknownOpt if not supported.
+	service Opt
+	// notifications is the AT+CNMI config that took effect on Init.
+	notifications CNMIConfig
+	// messageFlags and deleteOptions are reported by the device, nil if unknown.
+	messageFlags  []Opt
+	deleteOptions []Opt
 	DeviceProfile
 }
 
+// storagePreference lists the message storages in order of preference.
+var storagePreference = []StringOpt{
+	MemoryTypes.NvRAM, MemoryTypes.Associated, MemoryTypes.Sim,
+}
+
 // Init invokes a set of methods that will make the initial setup of the modem.
 func (p *DefaultProfile) Init(d *Device) (err error) {
 	p.dev = d
 	p.dev.Send(NoopCmd) // kinda flush
+	if err = p.ATE(false); err != nil {
+		return fmt.Errorf("at init: unable to disable command echo: %w", err)
+	}
 	if err = p.COPS(true, true); err != nil {
 		return fmt.Errorf("at init: unable to adjust the format of operator's name: %w", err)
 	}
 	var info *SystemInfoReport
-	if info, err = p.SYSINFO(); err != nil {
+	// dispatched through the device, so the embedding profiles could override it
+	if info, err = p.dev.Commands.SYSINFO(); err != nil {
 		return fmt.Errorf("at init: unable to read system info: %w", err)
 	}
 	p.dev.State = &DeviceState{
@@ -66,6 +166,9 @@ func (p *DefaultProfile) Init(d *Device) (err error) {
 		SystemMode:    info.SystemMode,
 		SystemSubmode: info.SystemSubmode,
 		SimState:      info.SimState,
+		// reported later, if ever
+		SignalStrength: 99,
+		StoredMessages: -1,
 	}
 	if p.dev.State.OperatorName, err = p.OperatorName(); err != nil {
 		return fmt.Errorf("at init: unable to read operator's name: %w", err)
@@ -76,41 +179,248 @@ func (p *DefaultProfile) Init(d *Device) (err error) {
 	if p.dev.State.IMEI, err = p.IMEI(); err != nil {
 		return fmt.Errorf("at init: unable to read modem's IMEI code: %w", err)
 	}
-	if err = p.CMGF(false); err != nil {
-		return fmt.Errorf("at init: unable to switch message format to PDU: %w", err)
+	// not every modem reports the lock state, it's left unknown then; dispatched
+	// through the device, so the embedding profiles could override the probe
+	p.dev.State.CarrierLock, p.dev.State.UnlockAttempts = UnknownOpt, -1
+	if l, ok := p.dev.Commands.(carrierLocker); ok {
+		p.dev.State.CarrierLock, p.dev.State.UnlockAttempts, _ = l.CarrierLock()
+	}
+	if err = p.CMGF(p.TextMode); err != nil {
+		return fmt.Errorf("at init: unable to switch message format: %w", err)
 	}
-	if err = p.CPMS(MemoryTypes.NvRAM, MemoryTypes.NvRAM, MemoryTypes.NvRAM); err != nil {
+	// the options are checked before use if the device reports them
+	p.messageFlags, _ = p.SupportedMessageFlags()
+	p.deleteOptions, _ = p.SupportedDeleteOptions()
+	if p.dev.State.MessageStorage, err = p.selectStorage(); err != nil {
 		return fmt.Errorf("at init: unable to set messages storage: %w", err)
 	}
-	if err = p.CNMI(1, 1, 0, 0, 0); err != nil {
+	p.service = UnknownOpt
+	if !p.DisableCSMS {
+		p.service = p.selectService()
+	}
+	p.dev.State.MessageService = p.service
+	if p.notifications, err = p.selectNotifications(); err != nil {
 		return fmt.Errorf("at init: unable to turn on message notifications: %w", err)
 	}
-	if err = p.CLIP(true); err != nil {
-		return fmt.Errorf("at init: unable to turn on calling party ID notifications: %w", err)
+	if !p.DisableCallerID {
+		if err = p.CLIP(true); err != nil {
+			return fmt.Errorf("at init: unable to turn on calling party ID notifications: %w", err)
+		}
+	}
+	for _, req := range p.InitCommands {
+		if _, err = p.dev.Send(req); err != nil {
+			return fmt.Errorf("at init: %s: %w", req, err)
+		}
+	}
+
+	if p.DeferInbox {
+		go p.drainInbox()
+		return nil
+	}
+	ctx := context.Background()
+	if p.InboxTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.InboxTimeout)
+		defer cancel()
 	}
+	if err = p.FetchInboxContext(ctx); errors.Is(err, context.DeadlineExceeded) {
+		// the rest is left in the storage until the next fetch
+		return nil
+	}
+	return err
+}
 
-	return p.FetchInbox()
+// drainInbox fetches the inbox in background until the device is closed. The errors
+// are ignored, the unprocessed messages stay in the storage.
+func (p *DefaultProfile) drainInbox() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	closed := p.dev.Closed()
+	go func() {
+		select {
+		case <-closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	p.FetchInboxContext(ctx)
+}
+
+// inboxPolicer is implemented by profiles that have the InboxPolicy.
+type inboxPolicer interface {
+	inboxPolicy() (StringOpt, error)
+}
+
+// inboxPolicy returns the InboxPolicy, InboxPolicies.Delete if empty.
+func (p *DefaultProfile) inboxPolicy() (StringOpt, error) {
+	switch p.InboxPolicy {
+	case StringOpt{}:
+		return InboxPolicies.Delete, nil
+	case InboxPolicies.Delete, InboxPolicies.Leave, InboxPolicies.MarkRead:
+		return p.InboxPolicy, nil
+	}
+	return p.InboxPolicy, fmt.Errorf("%w: inbox policy %s", ErrUnsupportedOption, p.InboxPolicy.ID)
 }
 
+// FetchInbox reads the stored messages, sends them to the IncomingSms channel and
+// applies the InboxPolicy to them.
 func (p *DefaultProfile) FetchInbox() error {
-	slots, err := p.CMGL(MessageFlags.Any)
+	return p.FetchInboxContext(context.Background())
+}
+
+// inboxChunkSize is the number of the listed messages handled between the checks
+// of the context in FetchInboxContext.
+const inboxChunkSize = 16
+
+// FetchInboxContext is like FetchInbox, but stops when the context is done. The messages
+// are listed at once with AT+CMGL and handled in chunks, the context is checked between
+// them. The modems that reject AT+CMGL are scanned with AT+CMGR until all the messages
+// reported by AT+CPMS? are found, the context is checked before each command then.
+// The policy is applied to a message only after it has been sent to the IncomingSms
+// channel, so the unprocessed ones stay in the storage.
+//
+// With InboxPolicies.MarkRead only the unread messages are delivered, the device marks them
+// as read when they're read. The storage is always scanned then, because AT+CMGL marks all
+// the listed messages as read at once, even those left unprocessed. With InboxPolicies.Leave
+// all the messages are delivered and they're delivered again on the next fetch.
+func (p *DefaultProfile) FetchInboxContext(ctx context.Context) error {
+	policy, err := p.inboxPolicy()
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	used, total, err := p.storageUsage()
 	if err != nil {
 		return fmt.Errorf("unable to check message inbox: %w", err)
 	}
+	if used == 0 {
+		return nil
+	}
+
+	if policy != InboxPolicies.MarkRead {
+		slots, err := p.CMGL(MessageFlags.Any)
+		switch {
+		case err == nil:
+			return p.fetchListed(ctx, policy, slots)
+		case !rejected(err) && !errors.Is(err, ErrUnsupportedOption) && !errors.Is(err, ErrParseReport):
+			return fmt.Errorf("unable to list message inbox: %w", err)
+		}
+		// the messages are read one by one
+	}
+	return p.scanInbox(ctx, policy, used, total)
+}
+
+// fetchListed handles the messages listed with AT+CMGL in chunks.
+func (p *DefaultProfile) fetchListed(ctx context.Context, policy StringOpt, slots []MessageSlot) error {
+	for i, slot := range slots {
+		if i%inboxChunkSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := p.receiveStored(ctx, policy, slot.Index, slot.Message, slot.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	for i := range slots {
-		var msg sms.Message
-		if _, err := msg.ReadFrom(slots[i].Payload); err != nil {
-			return fmt.Errorf("error while parsing message inbox: %w", err)
+// scanInbox reads the storage slots with AT+CMGR until the used ones are found.
+func (p *DefaultProfile) scanInbox(ctx context.Context, policy StringOpt, used, total int) error {
+	// the storages are indexed either from zero or from one
+	for index, found := 0, 0; index <= total && found < used; index++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		msg, err := p.ReadMessage(uint16(index))
+		switch {
+		case err == nil:
+		case errors.Is(err, errEmptySlot) || rejected(err):
+			// an empty or a missing slot
+			continue
+		default:
+			return fmt.Errorf("unable to read message inbox: %w", err)
 		}
-		if err := p.CMGD(slots[i].Index, DeleteOptions.Index); err != nil {
-			return fmt.Errorf("error while cleaning message inbox: %w", err)
+		found++
+		if policy == InboxPolicies.MarkRead && msg.Status != MessageFlags.Unread {
+			continue
+		}
+		if err = p.receiveStored(ctx, policy, uint16(index), msg.Message, msg.Octets); err != nil {
+			return err
 		}
-		p.dev.messages <- &msg
 	}
 	return nil
 }
 
+// receiveStored receives the message read from the storage slot, either parsed in the text
+// mode or as the PDU octets, and applies the policy to it.
+func (p *DefaultProfile) receiveStored(ctx context.Context, policy StringOpt, index uint16, msg *sms.Message, octets []byte) error {
+	var remove func() error
+	var removeErr error
+	if policy == InboxPolicies.Delete {
+		remove = func() error {
+			removeErr = p.CMGD(index, DeleteOptions.Index)
+			return removeErr
+		}
+	}
+	var err error
+	if msg != nil {
+		err = p.dev.receiveMessage(ctx, msg, remove)
+	} else {
+		err = p.dev.receive(ctx, octets, remove)
+	}
+	switch {
+	case err == nil:
+		return nil
+	case removeErr != nil:
+		return fmt.Errorf("error while cleaning message inbox: %w", err)
+	case ctx.Err() != nil:
+		return err
+	default:
+		return fmt.Errorf("error while parsing message inbox: %w", err)
+	}
+}
+
+// storageUsage sends AT+CPMS? to the device and returns the number of the messages
+// in the storage they're read from and its capacity, the number is kept in the state.
+func (p *DefaultProfile) storageUsage() (used, total int, err error) {
+	reply, err := p.dev.Send(`AT+CPMS?`)
+	if err != nil {
+		return
+	}
+	if !strings.HasPrefix(reply, "+CPMS:") {
+		return 0, 0, ErrParseReport
+	}
+	// +CPMS: "SM",3,20,"SM",3,20,"SM",3,20, the storage names may be omitted
+	fields := strings.Split(strings.TrimSpace(strings.TrimPrefix(reply, "+CPMS:")), ",")
+	if len(fields) > 0 && strings.HasPrefix(fields[0], `"`) {
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return 0, 0, ErrParseReport
+	}
+	if used, err = strconv.Atoi(strings.TrimSpace(fields[0])); err != nil {
+		return 0, 0, ErrParseReport
+	}
+	if total, err = strconv.Atoi(strings.TrimSpace(fields[1])); err != nil {
+		return 0, 0, ErrParseReport
+	}
+	if p.dev.State != nil {
+		p.dev.State.StoredMessages = used
+	}
+	return used, total, nil
+}
+
+// rejected reports whether the command has been rejected by the device, unlike the port
+// errors and the timeouts.
+func rejected(err error) bool {
+	return errors.Is(err, errFinalError) ||
+		strings.HasPrefix(err.Error(), FinalResults.CmsError.ID) ||
+		strings.HasPrefix(err.Error(), FinalResults.CmeError.ID)
+}
+
 type signalStrengthReport uint64
 
 func (s *signalStrengthReport) Parse(str string) error {
@@ -177,9 +487,10 @@ func (b *bootHandshakeReport) Parse(str string) error {
 		return ErrParseReport
 	}
 
-	key, err := parseUint8(fields[0])
+	// the token is a large number, i.e. ^BOOT:20952548,0,0,0,72
+	key, err := strconv.ParseUint(strings.TrimSpace(fields[0]), 10, 64)
 	if err != nil {
-		return err
+		return ErrParseReport
 	}
 
 	*b = bootHandshakeReport(key)
@@ -275,43 +586,143 @@ func (c *callerIDReport) GetCallerID() *calls.CallerID {
 	}
 }
 
-type messageReport struct {
+// MessageIndication is the storage slot of a received message or a status report
+// indicated by +CMTI or +CDSI.
+type MessageIndication struct {
 	Memory StringOpt
 	Index  uint16
 }
 
-func (m *messageReport) Parse(str string) (err error) {
-	fields := strings.Split(str, ",")
+// Parse scans the indication with or without its prefix, i.e. +CMTI: "SM",3.
+func (m *MessageIndication) Parse(str string) (err error) {
+	for _, prefix := range []string{Reports.Message.ID, Reports.StatusReport.ID} {
+		str = strings.TrimPrefix(str, prefix)
+	}
+	fields := strings.Split(strings.TrimSpace(str), ",")
 	if len(fields) < 2 {
 		return ErrParseReport
 	}
 	if m.Memory = MemoryTypes.Resolve(strings.Trim(fields[0], `"`)); m.Memory == UnknownStringOpt {
 		return ErrParseReport
 	}
-	if m.Index, err = parseUint16(fields[1]); err != nil {
+	if m.Index, err = parseUint16(strings.TrimSpace(fields[1])); err != nil {
 		return
 	}
 	return
 }
 
-// CMGR sends AT+CMGR with the given index to the device and returns the message contents.
-func (p *DefaultProfile) CMGR(index uint16) (octets []byte, err error) {
+// errEmptySlot is returned by ReadMessage for the empty storage slot, some devices
+// reply OK instead of an error.
+var errEmptySlot = errors.New("at: the storage slot is empty")
+
+// StoredMessage represents the reply to AT+CMGR in the PDU mode:
+// +CMGR: <stat>,[<alpha>],<length> followed by the PDU.
+type StoredMessage struct {
+	// Status is one of the MessageFlags.
+	Status Opt
+	// Alpha is the phonebook entry of the address, if the device reports it.
+	Alpha string
+	// Length is the TPDU length in octets, the SMSC information is not counted.
+	Length int
+	// Octets holds the PDU including the SMSC information.
+	Octets []byte
+	// Message is the message read in the text mode, the Octets are empty then.
+	Message *sms.Message
+}
+
+// Parse parses the AT+CMGR reply. ErrTruncated is returned if the PDU is shorter
+// than the length declared in the header.
+func (m *StoredMessage) Parse(reply string) error {
+	var header, payload string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case len(line) == 0:
+		case strings.HasPrefix(line, "+CMGR:"):
+			header = strings.TrimSpace(strings.TrimPrefix(line, "+CMGR:"))
+		case len(header) == 0:
+			return ErrParseReport
+		default:
+			payload += line
+		}
+	}
+	fields := strings.Split(header, ",")
+	if len(fields) < 2 {
+		return ErrParseReport
+	}
+	stat, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return ErrParseReport
+	}
+	m.Status = MessageFlags.Resolve(stat)
+	if len(fields) > 2 {
+		m.Alpha = strings.Trim(strings.TrimSpace(strings.Join(fields[1:len(fields)-1], ",")), `"`)
+	}
+	if m.Length, err = strconv.Atoi(strings.TrimSpace(fields[len(fields)-1])); err != nil {
+		return ErrParseReport
+	}
+	if m.Octets, err = util.Bytes(payload); err != nil || len(m.Octets) == 0 {
+		return ErrParseReport
+	}
+	if len(m.Octets)-int(m.Octets[0])-1 < m.Length {
+		return ErrTruncated
+	}
+	return nil
+}
+
+// ReadMessage sends AT+CMGR with the given index to the device and returns the message
+// along with its header, so the truncated reads can be told apart.
+func (p *DefaultProfile) ReadMessage(index uint16) (*StoredMessage, error) {
 	req := fmt.Sprintf(`AT+CMGR=%d`, index)
 	reply, err := p.dev.Send(req)
 	if err != nil {
-		return
+		return nil, err
+	}
+	if len(strings.TrimSpace(reply)) == 0 {
+		return nil, errEmptySlot
+	}
+	var msg StoredMessage
+	parse := msg.Parse
+	if p.textMode {
+		parse = msg.ParseText
+	}
+	if err = parse(reply); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// CMGR sends AT+CMGR with the given index to the device and returns the message contents.
+// See ReadMessage for the header information.
+func (p *DefaultProfile) CMGR(index uint16) (octets []byte, err error) {
+	msg, err := p.ReadMessage(index)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Message != nil {
+		return nil, fmt.Errorf("%w: PDU of AT+CMGR in the text mode", ErrUnsupported)
+	}
+	return msg.Octets, nil
+}
+
+// readText reads the stored message in the text mode.
+func (p *DefaultProfile) readText(index uint16) (*sms.Message, error) {
+	msg, err := p.ReadMessage(index)
+	if err != nil {
+		return nil, err
 	}
-	lines := strings.Split(reply, "\n")
-	if len(lines) < 2 {
+	if msg.Message == nil {
 		return nil, ErrParseReport
 	}
-	octets, err = util.Bytes(lines[1])
-	return
+	return msg.Message, nil
 }
 
 // CMGD sends AT+CMGD with the given index and option to the device. Option defines the mode
 // in which messages will be deleted. The default mode is to delete by index.
 func (p *DefaultProfile) CMGD(index uint16, option Opt) (err error) {
+	if !supported(p.deleteOptions, option) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedOption, option)
+	}
 	req := fmt.Sprintf(`AT+CMGD=%d,%d`, index, option.ID)
 	_, err = p.dev.Send(req)
 	return
@@ -325,6 +736,97 @@ func (p *DefaultProfile) CPMS(mem1 StringOpt, mem2 StringOpt, mem3 StringOpt) (e
 	return
 }
 
+// SupportedStorages sends AT+CPMS=? to the device and returns the message storages
+// that could be used for reading, writing and receiving messages at the same time.
+func (p *DefaultProfile) SupportedStorages() (storages []StringOpt, err error) {
+	reply, err := p.dev.Send(`AT+CPMS=?`)
+	if err != nil {
+		return
+	}
+	var report storageListReport
+	if err = report.Parse(strings.TrimPrefix(reply, `+CPMS:`)); err != nil {
+		return
+	}
+	return report.Common(), nil
+}
+
+// selectStorage applies the storage set in the profile, or tries the supported
+// storages in order of preference until the device accepts one of them.
+func (p *DefaultProfile) selectStorage() (StringOpt, error) {
+	if p.Storage.ID != "" {
+		return p.Storage, p.CPMS(p.Storage, p.Storage, p.Storage)
+	}
+	candidates := storagePreference
+	if supported, err := p.SupportedStorages(); err == nil && len(supported) > 0 {
+		candidates = supported
+	}
+	var err error
+	for _, mem := range candidates {
+		if err = p.CPMS(mem, mem, mem); err == nil {
+			return mem, nil
+		}
+	}
+	if err == nil {
+		err = ErrParseReport
+	}
+	return UnknownStringOpt, err
+}
+
+// storageListReport represents the reply to AT+CPMS=?, i.e. the lists of storages
+// supported for reading (mem1), writing (mem2) and receiving (mem3) messages.
+type storageListReport [][]StringOpt
+
+func (s *storageListReport) Parse(str string) error {
+	*s = nil
+	str = strings.TrimSpace(str)
+	for len(str) > 0 {
+		start := strings.IndexByte(str, '(')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(str[start:], ')')
+		if end < 0 {
+			return ErrParseReport
+		}
+		var group []StringOpt
+		for _, field := range strings.Split(str[start+1:start+end], ",") {
+			if mem := MemoryTypes.Resolve(strings.Trim(field, `" `)); mem != UnknownStringOpt {
+				group = append(group, mem)
+			}
+		}
+		*s = append(*s, group)
+		str = str[start+end+1:]
+	}
+	if len(*s) == 0 {
+		return ErrParseReport
+	}
+	return nil
+}
+
+// Common returns the storages present in every list, ordered by preference.
+func (s storageListReport) Common() (storages []StringOpt) {
+	for _, mem := range storagePreference {
+		common := true
+		for _, group := range s {
+			var found bool
+			for _, m := range group {
+				if m == mem {
+					found = true
+					break
+				}
+			}
+			if !found {
+				common = false
+				break
+			}
+		}
+		if common {
+			storages = append(storages, mem)
+		}
+	}
+	return
+}
+
 // CNMI sends AT+CNMI with the given parameters to the device.
 // It's used to adjust the settings of the new message arrival notifications.
 func (p *DefaultProfile) CNMI(mode, mt, bm, ds, bfr int) (err error) {
@@ -333,20 +835,174 @@ func (p *DefaultProfile) CNMI(mode, mt, bm, ds, bfr int) (err error) {
 	return
 }
 
+// CNMIConfig holds the parameters of AT+CNMI.
+type CNMIConfig struct {
+	Mode, MT, BM, DS, BFR int
+}
+
+// DirectRouting reports whether the messages or the status reports are routed
+// to the terminal as +CMT and +CDS instead of being stored. Each of them has
+// to be acknowledged with AT+CNMA then, or the network re-delivers it.
+func (c CNMIConfig) DirectRouting() bool {
+	return c.MT == 2 || c.MT == 3 || c.DS == 1
+}
+
+// CNMA sends AT+CNMA to the device, acknowledging the message that was routed
+// directly to the terminal.
+func (p *DefaultProfile) CNMA() (err error) {
+	_, err = p.dev.Send(`AT+CNMA`)
+	return
+}
+
+// acknowledge sends AT+CNMA if the routing mode in use requires it. The messages
+// are acknowledged by the ME itself with the phase 2 service.
+func (p *DefaultProfile) acknowledge() error {
+	if p.DisableMessageAck || !p.notifications.DirectRouting() || p.service == MessageServices.Phase2 {
+		return nil
+	}
+	return p.CNMA()
+}
+
+// CSMS sends AT+CSMS with the given service to the device, the support of the mobile
+// terminated, the mobile originated and the broadcast messages is returned.
+func (p *DefaultProfile) CSMS(service Opt) (mt, mo, bm bool, err error) {
+	reply, err := p.dev.Send(fmt.Sprintf(`AT+CSMS=%d`, service.ID))
+	if err != nil {
+		return
+	}
+	// +CSMS: <mt>,<mo>,<bm>
+	fields := strings.Split(strings.TrimSpace(strings.TrimPrefix(reply, "+CSMS:")), ",")
+	if len(fields) < 3 {
+		return false, false, false, ErrParseReport
+	}
+	return strings.TrimSpace(fields[0]) == "1", strings.TrimSpace(fields[1]) == "1",
+		strings.TrimSpace(fields[2]) == "1", nil
+}
+
+// selectService negotiates the phase 2+ message service, falling back to the phase 2 one
+// if the mobile terminated messages are not supported with it. UnknownOpt is returned
+// if the device doesn't support AT+CSMS, the messages are acknowledged as before then.
+func (p *DefaultProfile) selectService() Opt {
+	if mt, _, _, err := p.CSMS(MessageServices.Phase2Plus); err == nil && mt {
+		return MessageServices.Phase2Plus
+	}
+	if _, _, _, err := p.CSMS(MessageServices.Phase2); err == nil {
+		return MessageServices.Phase2
+	}
+	return UnknownOpt
+}
+
+// DefaultNotificationConfigs are the AT+CNMI settings tried on Init until the device
+// accepts one of them: with the status reports, then without them.
+var DefaultNotificationConfigs = []CNMIConfig{
+	{Mode: 1, MT: 1, BM: 0, DS: 2, BFR: 0},
+	{Mode: 1, MT: 1, BM: 0, DS: 0, BFR: 0},
+	{Mode: 2, MT: 1, BM: 0, DS: 0, BFR: 0},
+}
+
+// IMSNotificationConfigs are the AT+CNMI settings tried on Init with the IMS option: the
+// messages and the status reports are routed directly to the terminal, then the messages only.
+var IMSNotificationConfigs = []CNMIConfig{
+	{Mode: 2, MT: 2, BM: 0, DS: 1, BFR: 0},
+	{Mode: 2, MT: 2, BM: 0, DS: 0, BFR: 0},
+}
+
+// ReadCNMI sends AT+CNMI? to the device and returns the current settings.
+func (p *DefaultProfile) ReadCNMI() (cfg CNMIConfig, err error) {
+	reply, err := p.dev.Send(`AT+CNMI?`)
+	if err != nil {
+		return
+	}
+	fields := strings.Split(strings.TrimSpace(strings.TrimPrefix(reply, "+CNMI:")), ",")
+	values := []*int{&cfg.Mode, &cfg.MT, &cfg.BM, &cfg.DS, &cfg.BFR}
+	if len(fields) < 2 || len(fields) > len(values) {
+		return cfg, ErrParseReport
+	}
+	for i, field := range fields {
+		var v uint8
+		if v, err = parseUint8(strings.TrimSpace(field)); err != nil {
+			return cfg, ErrParseReport
+		}
+		*values[i] = int(v)
+	}
+	return
+}
+
+// selectNotifications applies the notification settings and reads them back, because
+// some modems silently clamp the unsupported combinations. The next config is tried
+// on mismatch. Returns the config that took effect.
+func (p *DefaultProfile) selectNotifications() (CNMIConfig, error) {
+	candidates := p.NotificationConfigs
+	if len(candidates) == 0 {
+		candidates = DefaultNotificationConfigs
+		if p.IMS && p.service == MessageServices.Phase2Plus {
+			candidates = append(append([]CNMIConfig(nil), IMSNotificationConfigs...), candidates...)
+		}
+	}
+	err := ErrParseReport
+	for _, cfg := range candidates {
+		if err = p.CNMI(cfg.Mode, cfg.MT, cfg.BM, cfg.DS, cfg.BFR); err != nil {
+			continue
+		}
+		actual, readErr := p.ReadCNMI()
+		if readErr != nil {
+			// the settings can't be verified, trust the device
+			return cfg, nil
+		}
+		// BM and BFR don't affect the message notifications
+		if actual.Mode == cfg.Mode && actual.MT == cfg.MT && actual.DS == cfg.DS {
+			return cfg, nil
+		}
+		err = fmt.Errorf("AT+CNMI=%d,%d,%d,%d,%d has been clamped to %d,%d,%d,%d,%d",
+			cfg.Mode, cfg.MT, cfg.BM, cfg.DS, cfg.BFR,
+			actual.Mode, actual.MT, actual.BM, actual.DS, actual.BFR)
+	}
+	return CNMIConfig{}, err
+}
+
 // CMGF sends AT+CMGF with the given value to the device. It toggles
 // the mode of message handling between PDU and TEXT.
 //
-// Note, that the at package works only in PDU mode.
+// Note, that the text mode is limited, see DefaultProfile.TextMode.
 func (p *DefaultProfile) CMGF(text bool) (err error) {
 	var flag int
 	if text {
 		flag = 1
 	}
 	req := fmt.Sprintf(`AT+CMGF=%d`, flag)
+	if _, err = p.dev.Send(req); err == nil {
+		p.textMode = text
+	}
+	return
+}
+
+// ATE sends ATE with the given value to the device. It toggles the echo
+// of the commands, replies are parsed correctly in both modes.
+func (p *DefaultProfile) ATE(echo bool) (err error) {
+	var flag int
+	if echo {
+		flag = 1
+	}
+	req := fmt.Sprintf(`ATE%d`, flag)
 	_, err = p.dev.Send(req)
 	return
 }
 
+// IPR sends AT+IPR with the given baud rate to the device. The device will switch
+// its serial port to the new rate, so the port should be reopened at that rate.
+func (p *DefaultProfile) IPR(baud int) (err error) {
+	req := fmt.Sprintf(`AT+IPR=%d`, baud)
+	_, err = p.dev.Send(req)
+	return
+}
+
+// ATW sends AT&W to the device. It stores the current settings (i.e. the baud rate)
+// in the user profile, so they survive a restart of the module.
+func (p *DefaultProfile) ATW() (err error) {
+	_, err = p.dev.Send(`AT&W`)
+	return
+}
+
 // CLIP sends AT+CLIP with the given value to the device. It toggles
 // the mode of periodic calling party ID notification
 func (p *DefaultProfile) CLIP(text bool) (err error) {
@@ -363,50 +1019,180 @@ func (p *DefaultProfile) CLIP(text bool) (err error) {
 // an active incoming call
 func (p *DefaultProfile) CHUP() (err error) {
 	req := "ATH+CHUP"
-	_, err = p.dev.Send(req)
+	_, err = p.dev.SendPriority(req, PriorityHigh)
 	return
 }
 
+// messageStatuses maps MessageFlags to the status strings used in text mode.
+var messageStatuses = map[int]string{
+	0: "REC UNREAD",
+	1: "REC READ",
+	2: "STO UNSENT",
+	3: "STO SENT",
+	4: "ALL",
+}
+
+// MessageFilter returns the AT+CMGL filter argument for the given MessageFlags option:
+// a number in PDU mode, or a quoted status string (i.e. "REC UNREAD") in text mode.
+func MessageFilter(flag Opt, text bool) string {
+	if status, ok := messageStatuses[flag.ID]; ok && text {
+		return `"` + status + `"`
+	}
+	return strconv.Itoa(flag.ID)
+}
+
+// SupportedMessageFlags sends AT+CMGL=? to the device and returns the supported
+// filtering flags, see MessageFlags.
+func (p *DefaultProfile) SupportedMessageFlags() ([]Opt, error) {
+	return p.supportedOptions(`AT+CMGL=?`, 0, MessageFlags.AllValues())
+}
+
+// SupportedDeleteOptions sends AT+CMGD=? to the device and returns the supported
+// deletion masks, see DeleteOptions.
+func (p *DefaultProfile) SupportedDeleteOptions() ([]Opt, error) {
+	return p.supportedOptions(`AT+CMGD=?`, 1, DeleteOptions.AllValues())
+}
+
+// supportedOptions parses the n-th parameter range of the test command reply,
+// i.e. +CMGD: (1-30),(0-4), into the options. The quoted statuses of the text mode,
+// i.e. +CMGL: ("REC UNREAD","REC READ","ALL"), are also recognized.
+func (p *DefaultProfile) supportedOptions(req string, n int, values []Opt) (opts []Opt, err error) {
+	reply, err := p.dev.Send(req)
+	if err != nil {
+		return
+	}
+	if i := strings.IndexByte(reply, ':'); i >= 0 {
+		reply = reply[i+1:]
+	}
+	if strings.Contains(reply, `"`) {
+		for _, opt := range values {
+			if status, ok := messageStatuses[opt.ID]; ok && strings.Contains(reply, `"`+status+`"`) {
+				opts = append(opts, opt)
+			}
+		}
+		return opts, nil
+	}
+	ranges, err := parseRanges(reply)
+	if err != nil || len(ranges) <= n {
+		return nil, ErrParseReport
+	}
+	for _, opt := range values {
+		if ranges[n].Contains(opt.ID) {
+			opts = append(opts, opt)
+		}
+	}
+	return opts, nil
+}
+
+// supported reports whether the option is in the list, any option is considered
+// supported if the list is unknown.
+func supported(opts []Opt, opt Opt) bool {
+	if opts == nil {
+		return true
+	}
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
 type MessageSlot struct {
-	Index   uint16
+	Index uint16
+	// Status of the message, i.e. MessageFlags.Unread.
+	Status Opt
+	// Alpha is the phonebook entry of the address, if the device reports it.
+	Alpha string
+	// Length is the TPDU length declared in the header, zero in the text mode.
+	Length  int
 	Payload []byte
+	// Message is the message listed in the text mode, the Payload is empty then.
+	Message *sms.Message
 }
 
 // CMGL sends AT+CMGL with the given filtering flag to the device and then parses
 // the list of received messages that match their filter. See MessageFlags for the
 // list of supported filters.
 func (p *DefaultProfile) CMGL(flag Opt) (result []MessageSlot, err error) {
-	req := fmt.Sprintf(`AT+CMGL=%d`, flag.ID)
+	if !supported(p.messageFlags, flag) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedOption, flag)
+	}
+	req := fmt.Sprintf(`AT+CMGL=%s`, MessageFilter(flag, p.textMode))
 	reply, err := p.dev.Send(req)
 	if err != nil {
 		return
 	}
-	lines := strings.Split(reply, "\n")
-	if len(lines) < 2 {
-		return
+	switch {
+	case p.MessageListParser != nil:
+		return p.MessageListParser(reply)
+	case p.textMode:
+		return ParseTextMessageList(reply)
 	}
+	return ParseMessageList(reply)
+}
 
-	for i := 0; i < len(lines); i += 2 {
-		header := strings.TrimPrefix(lines[i], `+CMGL: `)
-		fields := strings.Split(header, ",")
-		if len(fields) < 4 {
-			return nil, ErrParseReport
+// MessageListParser parses the reply to AT+CMGL in the PDU mode into the message slots.
+type MessageListParser func(reply string) ([]MessageSlot, error)
+
+// ParseMessageList is the default MessageListParser. Each +CMGL: <index>,<stat>,[<alpha>],<length>
+// header is followed by the hexadecimal PDU, which may be wrapped over several lines,
+// the blank lines are skipped.
+// The reply is rejected with ErrParseReport if the PDU is missing or there's data
+// before the first header.
+func ParseMessageList(reply string) (result []MessageSlot, err error) {
+	var slot MessageSlot
+	var payload string
+	var header bool
+	flush := func() error {
+		if !header {
+			return nil
 		}
-		n, err := parseUint16(fields[0])
-		if err != nil {
-			return nil, ErrParseReport
+		oct, err := util.Bytes(payload)
+		if err != nil || len(oct) == 0 {
+			return ErrParseReport
 		}
-		var oct []byte
-		if oct, err = util.Bytes(lines[i+1]); err != nil {
+		slot.Payload = oct
+		result = append(result, slot)
+		return nil
+	}
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "+CMGL:"):
+			if err = flush(); err != nil {
+				return nil, err
+			}
+			fields := strings.Split(strings.TrimSpace(strings.TrimPrefix(line, "+CMGL:")), ",")
+			if len(fields) < 4 {
+				return nil, ErrParseReport
+			}
+			slot = MessageSlot{}
+			if slot.Index, err = parseUint16(fields[0]); err != nil {
+				return nil, ErrParseReport
+			}
+			var stat int
+			if stat, err = strconv.Atoi(strings.TrimSpace(fields[1])); err != nil {
+				return nil, ErrParseReport
+			}
+			slot.Status = MessageFlags.Resolve(stat)
+			slot.Alpha = strings.Trim(strings.TrimSpace(strings.Join(fields[2:len(fields)-1], ",")), `"`)
+			if slot.Length, err = strconv.Atoi(strings.TrimSpace(fields[len(fields)-1])); err != nil {
+				return nil, ErrParseReport
+			}
+			payload, header = "", true
+		case !header:
 			return nil, ErrParseReport
+		default:
+			payload += line
 		}
-
-		result = append(result, MessageSlot{
-			Index:   n,
-			Payload: oct,
-		})
 	}
-	return
+	if err = flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // BOOT sends AT^BOOT with the given token to the device. This completes
@@ -417,14 +1203,38 @@ func (p *DefaultProfile) BOOT(token uint64) (err error) {
 	return
 }
 
+// bootHandshake reports whether the ^BOOT reports are replied to automatically.
+func (p *DefaultProfile) bootHandshake() bool {
+	return !p.DisableBootHandshake
+}
+
 // CMGS sends AT+CMGS with the given parameters to the device. This is used to send SMS
 // using the given PDU data. Length is a number of TPDU bytes.
 // Returns the reference number of the sent message.
 func (p *DefaultProfile) CMGS(length int, octets []byte) (byte, error) {
-	part1 := fmt.Sprintf("AT+CMGS=%d", length)
-	part2 := fmt.Sprintf("%02X", octets)
-	reply, err := p.dev.sendInteractive(part1, part2, byte('>'))
+	return p.CMGSPriority(length, octets, PriorityNormal, "")
+}
 
+// CMGSPriority is like CMGS, but the command is queued with the given priority
+// and listed as pending with the correlation ID, if it's not empty.
+func (p *DefaultProfile) CMGSPriority(length int, octets []byte, prio Priority, id string) (byte, error) {
+	i := interaction{
+		cmd: fmt.Sprintf("AT+CMGS=%d", length),
+		stages: []PromptStage{{
+			Prompt:     ">",
+			Payload:    fmt.Sprintf("%02X", octets),
+			Terminator: Sub,
+		}},
+		priority:      prio,
+		correlationID: id,
+	}
+	if p.textMode {
+		var err error
+		if i.cmd, i.stages[0].Payload, err = textSubmission(octets); err != nil {
+			return 0, err
+		}
+	}
+	reply, err := p.dev.sendInteractive(i)
 	if err != nil {
 		return 0, err
 	}
@@ -520,6 +1330,53 @@ func (p *DefaultProfile) SYSINFO() (info *SystemInfoReport, err error) {
 	return
 }
 
+// registration reads the network registration status with AT+CREG? into the system
+// info, for the modems lacking AT^SYSINFO. The other fields are left unknown.
+func (p *DefaultProfile) registration() (*SystemInfoReport, error) {
+	info := &SystemInfoReport{
+		ServiceState:  ServiceStates.None,
+		ServiceDomain: UnknownOpt,
+		RoamingState:  RoamingStates.NotRoaming,
+		SystemMode:    UnknownOpt,
+		SystemSubmode: UnknownOpt,
+		SimState:      SimStates.Invalid,
+	}
+	reply, err := p.dev.Send(`AT+CREG?`)
+	if err != nil {
+		return nil, err
+	}
+	// +CREG: <n>,<stat>, the registration status is 1 for home and 5 for roaming
+	fields := strings.Split(strings.TrimPrefix(reply, "+CREG:"), ",")
+	if len(fields) < 2 {
+		return nil, ErrParseReport
+	}
+	switch strings.TrimSpace(fields[1]) {
+	case "1":
+		info.ServiceState = ServiceStates.Valid
+		info.ServiceDomain = ServiceDomains.Restricted // cellular service
+	case "5":
+		info.ServiceState = ServiceStates.Valid
+		info.ServiceDomain = ServiceDomains.Restricted
+		info.RoamingState = RoamingStates.Roaming
+	}
+	return info, nil
+}
+
+// simState reads the SIM state with AT+CPIN?, for the modems lacking AT^SYSINFO.
+func (p *DefaultProfile) simState() Opt {
+	reply, err := p.dev.Send(`AT+CPIN?`)
+	switch {
+	case err != nil:
+		// i.e. +CME ERROR: SIM not inserted
+		return SimStates.NoCard
+	case strings.Contains(reply, "READY"):
+		return SimStates.Valid
+	default:
+		// locked with PIN or PUK
+		return SimStates.Invalid
+	}
+}
+
 // COPS sends AT+COPS to the device with parameters that define autosearch and
 // the operator's name representation. The default representation is numerical.
 func (p *DefaultProfile) COPS(auto bool, text bool) (err error) {
@@ -558,3 +1415,22 @@ func (p *DefaultProfile) IMEI() (str string, err error) {
 	str, err = p.dev.Send(`AT+GSN`)
 	return
 }
+
+// OwnNumber sends AT+CNUM to the device and gets the subscriber's number (MSISDN)
+// stored in the SIM, it's not available on every SIM card.
+func (p *DefaultProfile) OwnNumber() (str string, err error) {
+	result, err := p.dev.Send(`AT+CNUM`)
+	if err != nil {
+		return
+	}
+	fields := strings.Split(strings.TrimPrefix(result, `+CNUM: `), ",")
+	if len(fields) < 2 {
+		err = ErrParseReport
+		return
+	}
+	str = strings.Trim(fields[1], `"`)
+	if len(str) == 0 {
+		err = ErrParseReport
+	}
+	return
+}
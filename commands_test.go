@@ -0,0 +1,259 @@
+package at
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageListReport(t *testing.T) {
+	t.Parallel()
+
+	var report storageListReport
+	err := report.Parse(` ("ME","MT","SM","SR"),("ME","MT","SM"),("SM","MT")`)
+	require.NoError(t, err)
+	assert.Len(t, report, 3)
+	assert.Equal(t, []StringOpt{MemoryTypes.Associated, MemoryTypes.Sim}, report.Common())
+
+	err = report.Parse(` ("SM"),("SM"),("SM")`)
+	require.NoError(t, err)
+	assert.Equal(t, []StringOpt{MemoryTypes.Sim}, report.Common())
+
+	assert.Error(t, report.Parse(`ERROR`))
+}
+
+func TestMessageFilter(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "4", MessageFilter(MessageFlags.Any, false))
+	assert.Equal(t, `"ALL"`, MessageFilter(MessageFlags.Any, true))
+	assert.Equal(t, `"REC UNREAD"`, MessageFilter(MessageFlags.Unread, true))
+}
+
+func TestCMGS(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGS=2":  "> ",
+		"00AA" + Sub: "+CMGS: 42" + Sep + Sep + "OK",
+	})
+
+	ref, err := dev.Commands.CMGS(2, []byte{0x00, 0xAA})
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, ref)
+}
+
+func TestSelectNotifications(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CNMI=1,1,0,2,0": "OK",
+		"AT+CNMI=1,1,0,0,0": "OK",
+		"AT+CNMI?":          "+CNMI: 1,1,0,0,0" + Sep + Sep + "OK",
+	})
+	cfg, err := dev.Commands.(*noopProfile).selectNotifications()
+	require.NoError(t, err)
+	assert.Equal(t, CNMIConfig{Mode: 1, MT: 1}, cfg)
+}
+
+func TestSelectService(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CSMS=1":         "+CSMS: 1,1,1" + Sep + Sep + "OK",
+		"AT+CNMI=2,2,0,1,0": "OK",
+		"AT+CNMI?":          "+CNMI: 2,2,0,1,0" + Sep + Sep + "OK",
+		"AT+CNMA":           "OK",
+	})
+	p := dev.Commands.(*noopProfile)
+	p.IMS = true
+	p.service = p.selectService()
+	assert.Equal(t, MessageServices.Phase2Plus, p.service)
+	var err error
+	p.notifications, err = p.selectNotifications()
+	require.NoError(t, err)
+	assert.Equal(t, IMSNotificationConfigs[0], p.notifications)
+	assert.NoError(t, p.acknowledge())
+
+	dev, _ = newTestDevice(t, map[string]string{
+		"AT+CSMS=1": "+CSMS: 0,1,1" + Sep + Sep + "OK",
+		"AT+CSMS=0": "+CSMS: 1,1,1" + Sep + Sep + "OK",
+	})
+	p = dev.Commands.(*noopProfile)
+	p.service = p.selectService()
+	assert.Equal(t, MessageServices.Phase2, p.service)
+	// the messages are acknowledged by the ME with the phase 2 service, AT+CNMA would fail
+	p.notifications = IMSNotificationConfigs[0]
+	assert.NoError(t, p.acknowledge())
+	dev, _ = newTestDevice(t, nil)
+	assert.Equal(t, UnknownOpt, dev.Commands.(*noopProfile).selectService())
+}
+
+func TestFetchInboxContext(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CPMS?":    `+CPMS: "SM",3,5,"SM",3,5,"SM",3,5` + Sep + Sep + "OK",
+		"AT+CMGR=0":   "+CMS ERROR: 321",
+		"AT+CMGR=1":   "+CMGR: 1,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGR=2":   "OK",
+		"AT+CMGR=3":   "+CMGR: 1,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGR=4":   "+CMGR: 1,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGD=1,0": "OK",
+		"AT+CMGD=3,0": "OK",
+		"AT+CMGD=4,0": "OK",
+		// AT+CMGR=5 would fail, the messages are found by then;
+		// AT+CMGL=4 fails, so the storage is scanned
+	})
+	p := dev.Commands.(*noopProfile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, p.FetchInboxContext(ctx), context.Canceled)
+	assert.Len(t, dev.IncomingSms(), 0)
+
+	require.NoError(t, p.FetchInboxContext(context.Background()))
+	assert.Len(t, dev.IncomingSms(), 3)
+
+	dev.State = NewDeviceState()
+	used, total, err := p.storageUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 3, used)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, 3, dev.State.StoredMessages)
+}
+
+func TestFetchInboxList(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	const n = 2*inboxChunkSize + 1
+	replies := map[string]string{
+		"AT+CPMS?": fmt.Sprintf(`+CPMS: "SM",%d,50,"SM",%d,50,"SM",%d,50`, n, n, n) + Sep + Sep + "OK",
+		// AT+CMGR would fail, the messages are listed
+	}
+	var list strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&list, "+CMGL: %d,1,,25"+Sep+pdu+Sep, i*2)
+		replies[fmt.Sprintf("AT+CMGD=%d,0", i*2)] = "OK"
+	}
+	replies["AT+CMGL=4"] = list.String() + Sep + "OK"
+	dev, _ := newTestDevice(t, replies)
+
+	require.NoError(t, dev.Commands.FetchInbox())
+	assert.Len(t, dev.IncomingSms(), n)
+}
+
+func TestSupportedOptions(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGL=?":   `+CMGL: ("REC UNREAD","REC READ","ALL")` + Sep + Sep + "OK",
+		"AT+CMGD=?":   "+CMGD: (1-30),(0,4)" + Sep + Sep + "OK",
+		"AT+CMGD=1,0": "OK",
+	})
+	p := dev.Commands.(*noopProfile)
+	flags, err := p.SupportedMessageFlags()
+	require.NoError(t, err)
+	assert.Equal(t, []Opt{MessageFlags.Unread, MessageFlags.Read, MessageFlags.Any}, flags)
+	p.deleteOptions, err = p.SupportedDeleteOptions()
+	require.NoError(t, err)
+	assert.Equal(t, []Opt{DeleteOptions.Index, DeleteOptions.All}, p.deleteOptions)
+
+	assert.NoError(t, p.CMGD(1, DeleteOptions.Index))
+	assert.ErrorIs(t, p.CMGD(1, DeleteOptions.AllNotUnread), ErrUnsupportedOption)
+}
+
+func TestParseRanges(t *testing.T) {
+	t.Parallel()
+
+	ranges, err := parseRanges("(0-2147483647),(0,2-3)")
+	require.NoError(t, err)
+	require.Len(t, ranges, 2)
+	assert.True(t, ranges[0].Contains(2147483647))
+	assert.False(t, ranges[0].Contains(-1))
+	assert.True(t, ranges[1].Contains(0))
+	assert.False(t, ranges[1].Contains(1))
+	assert.True(t, ranges[1].Contains(3))
+
+	_, err = parseRanges("(0-x)")
+	assert.ErrorIs(t, err, ErrParseReport)
+}
+
+func TestInboxPolicy(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CPMS?":  `+CPMS: "SM",2,2,"SM",2,2,"SM",2,2` + Sep + Sep + "OK",
+		"AT+CMGR=1": "+CMGR: 0,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGR=2": "+CMGR: 1,,25" + Sep + pdu + Sep + Sep + "OK",
+		// AT+CMGD would fail
+	})
+	p := dev.Commands.(*noopProfile)
+
+	p.InboxPolicy = InboxPolicies.MarkRead
+	require.NoError(t, dev.Commands.FetchInbox())
+	assert.Len(t, dev.IncomingSms(), 1)
+
+	p.InboxPolicy = InboxPolicies.Leave
+	require.NoError(t, dev.Commands.FetchInbox())
+	assert.Len(t, dev.IncomingSms(), 3)
+
+	p.InboxPolicy = UnknownStringOpt
+	assert.ErrorIs(t, dev.Commands.FetchInbox(), ErrUnsupportedOption)
+}
+
+func TestParseMessageList(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	slots, err := ParseMessageList("+CMGL: 1,1,,27\n" + pdu[:30] + "\n" + pdu[30:] + "\n\n+CMGL: 7,0,\"Mom\",27\n" + pdu)
+	require.NoError(t, err)
+	if assert.Len(t, slots, 2) {
+		assert.Equal(t, uint16(1), slots[0].Index)
+		assert.Equal(t, MessageFlags.Read, slots[0].Status)
+		assert.Equal(t, 27, slots[0].Length)
+		assert.Equal(t, slots[0].Payload, slots[1].Payload)
+		assert.Equal(t, uint16(7), slots[1].Index)
+		assert.Equal(t, MessageFlags.Unread, slots[1].Status)
+		assert.Equal(t, "Mom", slots[1].Alpha)
+	}
+
+	slots, err = ParseMessageList("")
+	assert.NoError(t, err)
+	assert.Empty(t, slots)
+
+	_, err = ParseMessageList("+CMGL: 1,1,,27\n+CMGL: 2,1,,27\n" + pdu)
+	assert.ErrorIs(t, err, ErrParseReport)
+	_, err = ParseMessageList(pdu + "\n+CMGL: 2,1,,27\n" + pdu)
+	assert.ErrorIs(t, err, ErrParseReport)
+	_, err = ParseMessageList("+CMGL: 2,1,,x\n" + pdu)
+	assert.ErrorIs(t, err, ErrParseReport)
+}
+
+func TestStoredMessage(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	var msg StoredMessage
+	require.NoError(t, msg.Parse(`+CMGR: 1,"Alice, home",25`+"\n"+pdu[:30]+"\n"+pdu[30:]))
+	assert.Equal(t, MessageFlags.Read, msg.Status)
+	assert.Equal(t, "Alice, home", msg.Alpha)
+	assert.Equal(t, 25, msg.Length)
+	assert.Len(t, msg.Octets, 33)
+
+	msg = StoredMessage{}
+	require.NoError(t, msg.Parse("+CMGR: 0,,25\n"+pdu))
+	assert.Equal(t, MessageFlags.Unread, msg.Status)
+	assert.Empty(t, msg.Alpha)
+
+	assert.ErrorIs(t, msg.Parse("+CMGR: 0,,25\n"+pdu[:60]), ErrTruncated)
+	assert.ErrorIs(t, msg.Parse("+CMGR: 0,,25"), ErrParseReport)
+	assert.ErrorIs(t, msg.Parse(pdu), ErrParseReport)
+}
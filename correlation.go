@@ -0,0 +1,22 @@
+package at
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// GenerateID returns a new correlation ID for the sent messages, see SendOptions.CorrelationID.
+// It may be replaced to use the IDs of the application, i.e. the request IDs of a web service.
+var GenerateID = RandomID
+
+// RandomID returns 16 random hexadecimal digits, the default GenerateID.
+func RandomID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// the system randomness is unavailable
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,40 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomID(t *testing.T) {
+	t.Parallel()
+
+	id := RandomID()
+	assert.Len(t, id, 16)
+	assert.NotEqual(t, id, RandomID())
+}
+
+// tracingProfile records the correlation IDs of the sent messages.
+type tracingProfile struct {
+	noopProfile
+	ids []string
+}
+
+func (p *tracingProfile) CMGSPriority(length int, octets []byte, prio Priority, id string) (byte, error) {
+	p.ids = append(p.ids, id)
+	return 1, nil
+}
+
+func TestCorrelationID(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, nil)
+	p := &tracingProfile{}
+	p.dev = dev
+	dev.Commands = p
+	ref, err := dev.SendSMSRef("hello", "+79269965690", SendOptions{CorrelationID: "otp"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, ref)
+	assert.Equal(t, []string{"otp"}, p.ids, "the message is sent through the profile's override")
+}
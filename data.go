@@ -0,0 +1,175 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hasDataMode reports whether the transparent data mode is built in, it's left out
+// of the minimal builds.
+const hasDataMode = true
+
+// DefaultGuardTime is the silence kept before and after the +++ escape sequence.
+const DefaultGuardTime = time.Second
+
+// EscapeSequence switches the modem from the data mode back to the command mode.
+const EscapeSequence = "+++"
+
+// DataConn is the raw stream of the command port in the transparent data mode,
+// i.e. to run PPP on top of it. The port is owned by the connection until it's closed,
+// so no commands could be sent meanwhile: the reports that need the command port
+// (i.e. +CMTI to read the message) are handled once the connection is closed.
+type DataConn struct {
+	// GuardTime overrides the default silence around the escape sequence (1s).
+	GuardTime time.Duration
+
+	d      *Device
+	closed sync.Once
+}
+
+// DataMode sends the dial command (i.e. ATD*99# or a vendor socket open command)
+// and switches into the transparent data mode once the modem replies with CONNECT.
+func (d *Device) DataMode(dial string) (*DataConn, error) {
+	if err := d.sanityCheck(true); err != nil {
+		return nil, err
+	}
+	d.cmdMux.Lock()
+	d.pace()
+	err := d.withTimeout(d.commandTimeout(dial), func() error {
+		if _, err := d.cmdPort.Write([]byte(dial + Sep)); err != nil {
+			return d.portError(err)
+		}
+		var echoed bool
+		for {
+			line, err := d.cmdReader.ReadString('\n')
+			if err != nil {
+				return d.portError(err)
+			}
+			text := strings.TrimSpace(line)
+			if len(text) < 1 {
+				continue
+			}
+			if !echoed && strings.HasPrefix(dial, text) {
+				echoed = true
+				continue
+			}
+			switch FinalResults.Resolve(text) {
+			case FinalResults.Connect:
+				return nil
+			case FinalResults.Ok, FinalResults.Ring:
+				// not a final reply for the dial command
+			default:
+				return errors.New(text)
+			}
+		}
+	})
+	if err != nil {
+		d.lastCommand = time.Now()
+		d.cmdMux.Unlock()
+		return nil, err
+	}
+	d.dataMux.Lock()
+	d.dataSession = true
+	d.dataMux.Unlock()
+	return &DataConn{d: d}, nil
+}
+
+// commandReports are the reports followed up by the commands.
+var commandReports = []StringOpt{
+	Reports.Message, Reports.StatusReport, Reports.MessagePDU, Reports.StatusReportPDU,
+	Reports.BootHandshake, Reports.Indicator,
+}
+
+// inDataSession reports whether the command port is owned by a DataConn.
+func (d *Device) inDataSession() bool {
+	d.dataMux.Lock()
+	defer d.dataMux.Unlock()
+	return d.dataSession
+}
+
+// deferReport queues the report followed up by the commands during a data session,
+// so it doesn't block the watch loop. It returns false if the report should be handled now.
+func (d *Device) deferReport(str string) bool {
+	d.dataMux.Lock()
+	defer d.dataMux.Unlock()
+	if !d.dataSession {
+		return false
+	}
+	report := Reports.Resolve(str)
+	for _, r := range commandReports {
+		if r == report {
+			d.dataReports = append(d.dataReports, str)
+			return true
+		}
+	}
+	return false
+}
+
+// endDataSession handles the reports queued during the data session.
+func (d *Device) endDataSession() {
+	d.dataMux.Lock()
+	reports := d.dataReports
+	d.dataSession, d.dataReports = false, nil
+	d.dataMux.Unlock()
+	for _, str := range reports {
+		d.handleReport(str) // ignore errors
+	}
+}
+
+// Read reads the data received from the network.
+func (c *DataConn) Read(p []byte) (int, error) {
+	return c.d.cmdReader.Read(p)
+}
+
+// Write sends the data to the network.
+func (c *DataConn) Write(p []byte) (int, error) {
+	return c.d.cmdPort.Write(p)
+}
+
+// SetDeadline sets the read and write deadline of the stream.
+func (c *DataConn) SetDeadline(t time.Time) error {
+	return c.d.cmdPort.SetDeadline(t)
+}
+
+// Close escapes to the command mode with +++, hangs up the connection
+// and releases the command port, the reports queued meanwhile are handled then.
+func (c *DataConn) Close() (err error) {
+	c.closed.Do(func() {
+		defer c.d.endDataSession()
+		defer c.d.cmdMux.Unlock()
+		guard := c.GuardTime
+		if guard == 0 {
+			guard = DefaultGuardTime
+		}
+		time.Sleep(guard)
+		if _, err = c.d.cmdPort.Write([]byte(EscapeSequence)); err != nil {
+			err = c.d.portError(err)
+			return
+		}
+		time.Sleep(guard)
+		err = c.d.withTimeout(c.d.commandTimeout(EscapeSequence), func() error {
+			// skip the remaining data until the modem confirms the command mode
+			for {
+				line, err := c.d.cmdReader.ReadString('\n')
+				if err != nil {
+					return c.d.portError(err)
+				}
+				switch FinalResults.Resolve(strings.TrimSpace(line)) {
+				case FinalResults.Ok, FinalResults.NoCarrier:
+					return nil
+				}
+			}
+		})
+		if err != nil {
+			return
+		}
+		_, err = c.d.send("ATH")
+		c.d.lastCommand = time.Now()
+	})
+	return
+}
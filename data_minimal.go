@@ -0,0 +1,18 @@
+//go:build at_minimal
+// +build at_minimal
+
+package at
+
+// hasDataMode reports whether the transparent data mode is built in, it's left out
+// of the minimal builds.
+const hasDataMode = false
+
+// inDataSession reports whether the command port is owned by a DataConn.
+func (d *Device) inDataSession() bool {
+	return false
+}
+
+// deferReport queues the report during a data session, there are none.
+func (d *Device) deferReport(str string) bool {
+	return false
+}
@@ -0,0 +1,67 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataMode(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := attachTestDevice(t, func(modem, urc net.Conn) {
+		buf := bufio.NewReader(modem)
+		expect := func(req, reply string) {
+			data := make([]byte, len(req))
+			if _, err := io.ReadFull(buf, data); err != nil || string(data) != req {
+				modem.Close()
+				return
+			}
+			io.WriteString(modem, reply)
+		}
+		expect(NoopCmd+Sep, Sep+"OK"+Sep)
+		expect("ATD*99#"+Sep, Sep+"CONNECT 115200"+Sep)
+		expect("ping", "pong")
+		expect(EscapeSequence, Sep+"OK"+Sep)
+		expect("ATH"+Sep, Sep+"OK"+Sep)
+		// the message indicated during the session
+		expect("AT+CMGR=1"+Sep, Sep+"ERROR"+Sep)
+		expect(NoopCmd+Sep, Sep+"OK"+Sep)
+	})
+	require.NoError(t, dev.Init(&noopProfile{}))
+
+	cgev := dev.Subscribe("+CGEV:")
+	go dev.Watch()
+	io.WriteString(urc, Sep+"+CGEV: ME ATTACH"+Sep)
+	<-cgev
+
+	conn, err := dev.DataMode("ATD*99#")
+	require.NoError(t, err)
+	conn.GuardTime = 10 * time.Millisecond
+
+	// the reports are handled during the session, the ones that need the port are queued
+	io.WriteString(urc, Sep+`+CMTI: "SM",1`+Sep+"+CGEV: ME DETACH"+Sep)
+	select {
+	case <-cgev:
+	case <-time.After(time.Second):
+		t.Fatal("the watch loop is blocked by the data session")
+	}
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	data := make([]byte, 4)
+	_, err = io.ReadFull(conn, data)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(data))
+
+	require.NoError(t, conn.Close())
+	_, err = dev.Send(NoopCmd)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,237 @@
+package at
+
+import (
+	"sort"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// LatencySamples is the number of the latest deliveries kept per operator to compute the percentiles.
+var LatencySamples = 1000
+
+// Delivery describes a sent message confirmed by a status report.
+type Delivery struct {
+	// Reference is the message reference returned by AT+CMGS.
+	Reference byte
+	Address   sms.PhoneNumber
+	Operator  string
+	Status    sms.Status
+	// Submitted is the local time when the message was accepted by the SMSC.
+	Submitted time.Time
+	// Delivered is the discharge time from the status report.
+	Delivered time.Time
+	// Latency is the submit to delivery latency.
+	Latency time.Duration
+	// CorrelationID of the sent message, see SendOptions.CorrelationID.
+	CorrelationID string
+}
+
+// LatencyStats summarizes the delivery latencies of the recent messages.
+type LatencyStats struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+	// Failed is the number of the failed deliveries among the latest status reports,
+	// FailureRate is their share.
+	Failed      int
+	FailureRate float64
+}
+
+type submission struct {
+	address  sms.PhoneNumber
+	operator string
+	time     time.Time
+	id       string
+}
+
+// latencySamples is a ring of the latest latencies, along with a ring of the
+// outcomes of the latest final status reports.
+type latencySamples struct {
+	values []time.Duration
+	next   int
+
+	failures    []bool
+	nextOutcome int
+}
+
+func (l *latencySamples) add(v time.Duration) {
+	if len(l.values) < LatencySamples {
+		l.values = append(l.values, v)
+		return
+	}
+	l.values[l.next] = v
+	l.next = (l.next + 1) % len(l.values)
+}
+
+func (l *latencySamples) outcome(failed bool) {
+	if len(l.failures) < LatencySamples {
+		l.failures = append(l.failures, failed)
+		return
+	}
+	l.failures[l.nextOutcome] = failed
+	l.nextOutcome = (l.nextOutcome + 1) % len(l.failures)
+}
+
+func (l *latencySamples) stats() LatencyStats {
+	sorted := make([]time.Duration, len(l.values))
+	copy(sorted, l.values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p int) time.Duration {
+		if len(sorted) == 0 {
+			return 0
+		}
+		return sorted[(len(sorted)-1)*p/100]
+	}
+	stats := LatencyStats{
+		Count: len(sorted),
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P99:   percentile(99),
+		Max:   percentile(100),
+	}
+	for _, failed := range l.failures {
+		if failed {
+			stats.Failed++
+		}
+	}
+	if len(l.failures) > 0 {
+		stats.FailureRate = float64(stats.Failed) / float64(len(l.failures))
+	}
+	return stats
+}
+
+// Deliveries fires when a status report confirms a message sent with SendOptions.StatusReport.
+// The deliveries are dropped if the channel is not consumed.
+func (d *Device) Deliveries() <-chan Delivery {
+	return d.deliveries
+}
+
+// SubscribeDeliveries returns a channel that fires with every delivery like Deliveries,
+// so the deliveries could be tracked by several consumers (i.e. an Outbox) independently.
+// The deliveries are dropped if the channel is full, UnsubscribeDeliveries stops them.
+func (d *Device) SubscribeDeliveries() <-chan Delivery {
+	ch := make(chan Delivery, 100)
+	d.deliveryMux.Lock()
+	d.deliverySubs = append(d.deliverySubs, ch)
+	d.deliveryMux.Unlock()
+	return ch
+}
+
+// UnsubscribeDeliveries stops the deliveries to the channel obtained from SubscribeDeliveries.
+func (d *Device) UnsubscribeDeliveries(ch <-chan Delivery) {
+	d.deliveryMux.Lock()
+	defer d.deliveryMux.Unlock()
+	for i, sub := range d.deliverySubs {
+		if sub == ch {
+			d.deliverySubs = append(d.deliverySubs[:i], d.deliverySubs[i+1:]...)
+			return
+		}
+	}
+}
+
+// DeliveryStats returns the delivery latency percentiles and the failure rates per operator.
+func (d *Device) DeliveryStats() map[string]LatencyStats {
+	d.deliveryMux.Lock()
+	defer d.deliveryMux.Unlock()
+	stats := make(map[string]LatencyStats, len(d.latencies))
+	for operator, samples := range d.latencies {
+		stats[operator] = samples.stats()
+	}
+	return stats
+}
+
+// submitted remembers the sent message to match its status report later.
+func (d *Device) submitted(ref byte, address sms.PhoneNumber, id string) {
+	var operator string
+	if d.State != nil {
+		operator = d.State.OperatorName
+	}
+	d.deliveryMux.Lock()
+	defer d.deliveryMux.Unlock()
+	if d.submissions == nil {
+		d.submissions = make(map[byte]submission)
+	}
+	// the references wrap around, the older submission is replaced
+	d.submissions[ref] = submission{
+		address:  address,
+		operator: operator,
+		time:     time.Now(),
+		id:       id,
+	}
+}
+
+// delivered matches the status report with the submitted message and records the latency.
+func (d *Device) delivered(report *sms.Message) {
+	d.deliveryMux.Lock()
+	sub, ok := d.submissions[report.MessageReference]
+	if !ok {
+		d.deliveryMux.Unlock()
+		return
+	}
+	delete(d.submissions, report.MessageReference)
+	delivery := Delivery{
+		Reference: report.MessageReference,
+		Address:   sub.address,
+		Operator:  sub.operator,
+		Status:    report.Status,
+		Submitted: sub.time,
+		Delivered: time.Time(report.DischargeTime),
+
+		CorrelationID: sub.id,
+	}
+	delivery.Latency = delivery.Delivered.Sub(delivery.Submitted)
+	if delivery.Latency < 0 {
+		// the clocks of the SMSC and the host differ
+		delivery.Latency = 0
+	}
+	if elapsed := time.Since(sub.time); delivery.Latency > elapsed {
+		// the host clock was wrong at the submission, i.e. before an NTP step,
+		// the report couldn't be delivered later than it has arrived
+		delivery.Latency = elapsed
+	}
+	switch report.Status.Category() {
+	case sms.StatusCategories.Complete:
+		samples := d.operatorSamples(sub.operator)
+		samples.add(delivery.Latency)
+		samples.outcome(false)
+	case sms.StatusCategories.PermanentError, sms.StatusCategories.FinalError:
+		// the SMSC is not making any more attempts
+		d.operatorSamples(sub.operator).outcome(true)
+	}
+	d.deliveryMux.Unlock()
+
+	d.publishDelivery(delivery)
+}
+
+// publishDelivery sends the delivery to the Deliveries channel and the subscriptions,
+// the ones that are full are skipped.
+func (d *Device) publishDelivery(delivery Delivery) {
+	select {
+	case d.deliveries <- delivery:
+	default:
+	}
+	d.deliveryMux.Lock()
+	defer d.deliveryMux.Unlock()
+	for _, ch := range d.deliverySubs {
+		select {
+		case ch <- delivery:
+		default:
+		}
+	}
+}
+
+// operatorSamples returns the samples of the operator, the caller must hold the deliveryMux.
+func (d *Device) operatorSamples(operator string) *latencySamples {
+	if d.latencies == nil {
+		d.latencies = make(map[string]*latencySamples)
+	}
+	samples, ok := d.latencies[operator]
+	if !ok {
+		samples = &latencySamples{}
+		d.latencies[operator] = samples
+	}
+	return samples
+}
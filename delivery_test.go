@@ -0,0 +1,102 @@
+package at
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+)
+
+func TestLatencyStats(t *testing.T) {
+	t.Parallel()
+
+	var samples latencySamples
+	for i := 100; i > 0; i-- {
+		samples.add(time.Duration(i) * time.Second)
+	}
+	assert.Equal(t, LatencyStats{
+		Count: 100,
+		P50:   50 * time.Second,
+		P90:   90 * time.Second,
+		P99:   99 * time.Second,
+		Max:   100 * time.Second,
+	}, samples.stats())
+
+	for i := 0; i < 4; i++ {
+		samples.outcome(i == 0)
+	}
+	stats := samples.stats()
+	assert.Equal(t, 1, stats.Failed)
+	assert.Equal(t, 0.25, stats.FailureRate)
+}
+
+func TestDeliveries(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := newTestDevice(t, map[string]string{
+		"AT+CMGR=3": "+CMGR: 0,,25" + Sep +
+			"079194710600400706360d91947106000000f122206151457440222061514584400000" + Sep + Sep + "OK",
+		"AT+CMGD=3,0": "OK",
+	})
+	dev.submitted(54, "+4917600000001", "otp-1")
+	go dev.Watch()
+
+	io.WriteString(urc, Sep+`+CDSI: "SM",3`+Sep)
+	select {
+	case delivery := <-dev.Deliveries():
+		assert.EqualValues(t, 54, delivery.Reference)
+		assert.Equal(t, sms.PhoneNumber("+4917600000001"), delivery.Address)
+		assert.Equal(t, sms.StatusCodes.CompletedReceived, delivery.Status)
+		assert.Equal(t, "otp-1", delivery.CorrelationID)
+	case <-time.After(time.Second):
+		t.Fatal("delivery was not reported")
+	}
+	msg := <-dev.IncomingSms()
+	assert.Equal(t, sms.MessageTypes.StatusReport, msg.Type)
+	assert.Equal(t, 1, dev.DeliveryStats()[""].Count)
+}
+
+func TestDirectDeliveries(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := newTestDevice(t, nil)
+	dev.submitted(54, "+4917600000001", "")
+	go dev.Watch()
+
+	io.WriteString(urc, Sep+"+CDS: 25"+Sep+
+		"079194710600400706360d91947106000000f122206151457440222061514584400000"+Sep)
+	select {
+	case delivery := <-dev.Deliveries():
+		assert.EqualValues(t, 54, delivery.Reference)
+		assert.Equal(t, sms.StatusCodes.CompletedReceived, delivery.Status)
+	case <-time.After(time.Second):
+		t.Fatal("delivery was not reported")
+	}
+	msg := <-dev.IncomingSms()
+	assert.Equal(t, sms.MessageTypes.StatusReport, msg.Type)
+}
+
+func TestDirectMessages(t *testing.T) {
+	t.Parallel()
+
+	const cmt = "+CMT: ,27\n07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, nil) // AT+CNMA would fail
+	p := dev.Commands.(*noopProfile)
+	require.NoError(t, dev.handleReport(cmt), "not acknowledged in the default mode")
+
+	p.notifications = CNMIConfig{Mode: 2, MT: 2}
+	assert.Error(t, dev.handleReport(cmt))
+	p.DisableMessageAck = true
+	require.NoError(t, dev.handleReport(cmt))
+
+	require.Len(t, dev.IncomingSms(), 3, "messages are received even if not acknowledged")
+	msg := <-dev.IncomingSms()
+	assert.Equal(t, sms.MessageTypes.Deliver, msg.Type)
+	assert.Equal(t, "crap Δ", msg.Text)
+
+	assert.True(t, CNMIConfig{MT: 1, DS: 1}.DirectRouting())
+	assert.False(t, CNMIConfig{MT: 1, DS: 2}.DirectRouting())
+}
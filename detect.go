@@ -0,0 +1,111 @@
+package at
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownProfile happens when there's no profile registered with the given name.
+var ErrUnknownProfile = errors.New("at: unknown device profile")
+
+// modelEntry matches the manufacturer and model to the name of the profile.
+type modelEntry struct {
+	manufacturer string
+	model        string
+	profile      string
+}
+
+var (
+	profiles = map[string]func() DeviceProfile{
+		"e173":    DeviceE173,
+		"sim800":  DeviceSIM800,
+		"sim7600": DeviceSIM7600,
+		"le910":   DeviceLE910,
+		"me910":   DeviceME910,
+		"mf190":   DeviceMF190,
+		"mf823":   DeviceMF823,
+		"generic": DeviceGeneric,
+	}
+	models = []modelEntry{
+		{"huawei", "", "e173"},
+		{"simcom", "sim800", "sim800"},
+		{"simcom", "", "sim7600"},
+		{"telit", "me910", "me910"},
+		{"telit", "", "le910"},
+		{"zte", "mf823", "mf823"},
+		{"zte", "", "mf190"},
+	}
+	registryMux sync.Mutex
+)
+
+// RegisterProfile makes the profile available by its name to ProfileByName, the name
+// is case-insensitive. The registered profile replaces the one with the same name.
+func RegisterProfile(name string, factory func() DeviceProfile) {
+	registryMux.Lock()
+	defer registryMux.Unlock()
+	profiles[strings.ToLower(name)] = factory
+}
+
+// ProfileByName returns a new instance of the profile registered with the name,
+// i.e. "e173", "sim7600" or "generic". See also Profiles.
+func ProfileByName(name string) (DeviceProfile, error) {
+	registryMux.Lock()
+	defer registryMux.Unlock()
+	factory, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownProfile
+	}
+	return factory(), nil
+}
+
+// Profiles returns the sorted names of the registered profiles.
+func Profiles() []string {
+	registryMux.Lock()
+	defer registryMux.Unlock()
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterModel makes DetectProfile select the named profile if the manufacturer and model
+// reported by the device contain the given strings, the matching is case-insensitive and
+// the empty string matches any. The models registered later take precedence.
+func RegisterModel(manufacturer, model, profile string) {
+	registryMux.Lock()
+	defer registryMux.Unlock()
+	entry := modelEntry{strings.ToLower(manufacturer), strings.ToLower(model), strings.ToLower(profile)}
+	models = append([]modelEntry{entry}, models...)
+}
+
+// DetectProfile identifies the device with ATI, AT+CGMI and AT+GMM and returns
+// the matching registered profile, see RegisterModel. The device should be opened,
+// but not initialized yet. Returns the DeviceGeneric profile if nothing matches.
+func DetectProfile(dev *Device) (DeviceProfile, error) {
+	if err := dev.sanityCheck(false); err != nil {
+		return nil, err
+	}
+	dev.cmdMux.Lock()
+	// not every device supports each of the commands
+	info, _ := dev.send(`ATI`)
+	manufacturer, _ := dev.send(`AT+CGMI`)
+	model, _ := dev.send(`AT+GMM`)
+	dev.cmdMux.Unlock()
+	manufacturer = strings.ToLower(manufacturer + "\n" + info)
+	model = strings.ToLower(model + "\n" + info)
+
+	registryMux.Lock()
+	name := "generic"
+	for _, entry := range models {
+		if strings.Contains(manufacturer, entry.manufacturer) && strings.Contains(model, entry.model) {
+			name = entry.profile
+			break
+		}
+	}
+	registryMux.Unlock()
+	return ProfileByName(name)
+}
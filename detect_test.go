@@ -0,0 +1,57 @@
+package at
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func detect(t *testing.T, replies map[string]string) DeviceProfile {
+	t.Helper()
+	dev, _ := attachTestDevice(t, func(modem, urc net.Conn) {
+		serveModem(modem, replies)
+	})
+
+	profile, err := DetectProfile(dev)
+	require.NoError(t, err)
+	return profile
+}
+
+func TestDetectProfile(t *testing.T) {
+	t.Parallel()
+
+	assert.IsType(t, &DefaultProfile{}, detect(t, map[string]string{
+		"AT+CGMI": "huawei" + Sep + Sep + "OK",
+		"AT+GMM":  "E173" + Sep + Sep + "OK",
+	}))
+	assert.IsType(t, &TelitProfile{}, detect(t, map[string]string{
+		"ATI": "Telit" + Sep + "LE910C1-EU" + Sep + "25.21.660" + Sep + Sep + "OK",
+	}))
+	assert.IsType(t, &GenericProfile{}, detect(t, nil))
+
+	type customProfile struct{ SIMComProfile }
+	RegisterProfile("SIM7000", func() DeviceProfile { return &customProfile{} })
+	RegisterModel("SIMCOM", "SIM7000", "sim7000")
+	assert.IsType(t, &customProfile{}, detect(t, map[string]string{
+		"AT+CGMI": "SIMCOM INCORPORATED" + Sep + Sep + "OK",
+		"AT+GMM":  "SIMCOM_SIM7000E" + Sep + Sep + "OK",
+	}))
+	assert.IsType(t, &SIMComProfile{}, detect(t, map[string]string{
+		"AT+CGMI": "SIMCOM INCORPORATED" + Sep + Sep + "OK",
+		"AT+GMM":  "SIMCOM_SIM7600E" + Sep + Sep + "OK",
+	}))
+}
+
+func TestProfileByName(t *testing.T) {
+	t.Parallel()
+
+	profile, err := ProfileByName("LE910")
+	require.NoError(t, err)
+	assert.IsType(t, &TelitProfile{}, profile)
+	assert.Contains(t, Profiles(), "generic")
+
+	_, err = ProfileByName("air720")
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+}
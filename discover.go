@@ -0,0 +1,95 @@
+package at
+
+import (
+	"sort"
+	"time"
+)
+
+// PortInfo describes a serial port found by the port enumeration.
+type PortInfo struct {
+	// Path is the name of the port that can be passed to a Dialer.
+	Path string
+	// VendorID and ProductID are the USB identifiers in hex, e.g. "12d1" and "1001".
+	// Empty if the platform doesn't expose them.
+	VendorID  string
+	ProductID string
+	// Interface is the USB interface number of the port, -1 if unknown.
+	Interface int
+	// Device identifies the physical USB device the port belongs to,
+	// ports sharing the Device are the interfaces of the same modem.
+	Device string
+}
+
+// ModemPorts is a command/notification port pair of a discovered modem,
+// ready to be used as Device.CommandPort and Device.NotifyPort.
+type ModemPorts struct {
+	CommandPort string
+	NotifyPort  string
+	VendorID    string
+	ProductID   string
+}
+
+// Discover enumerates the USB serial ports, probes each of them with NoopCmd
+// and returns the port pairs of the modems that reply. The interface with the lowest number
+// is used as the command port and the highest one as the notification port,
+// a modem with the only responsive port gets it in both roles.
+func Discover() ([]ModemPorts, error) {
+	return discover(DialSerial, DefaultProbeTimeout)
+}
+
+func discover(dial Dialer, timeout time.Duration) ([]ModemPorts, error) {
+	ports, err := listPorts()
+	if err != nil {
+		return nil, err
+	}
+	var alive []PortInfo
+	for _, info := range ports {
+		rwc, err := dial(info.Path)
+		if err != nil {
+			continue
+		}
+		err = probe(rwc, timeout)
+		rwc.Close()
+		if err == nil {
+			alive = append(alive, info)
+		}
+	}
+	return pairPorts(alive), nil
+}
+
+// pairPorts groups the ports by device and picks the command and notification ports of each.
+func pairPorts(ports []PortInfo) []ModemPorts {
+	var devices []string
+	groups := make(map[string][]PortInfo)
+	for _, info := range ports {
+		dev := info.Device
+		if len(dev) == 0 {
+			// nothing to group by, treat as a standalone modem
+			dev = info.Path
+		}
+		if _, ok := groups[dev]; !ok {
+			devices = append(devices, dev)
+		}
+		groups[dev] = append(groups[dev], info)
+	}
+	sort.Strings(devices)
+
+	modems := make([]ModemPorts, 0, len(devices))
+	for _, dev := range devices {
+		group := groups[dev]
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Interface != group[j].Interface {
+				return group[i].Interface < group[j].Interface
+			}
+			return group[i].Path < group[j].Path
+		})
+		first, last := group[0], group[len(group)-1]
+		modems = append(modems, ModemPorts{
+			CommandPort: first.Path,
+			NotifyPort:  last.Path,
+			VendorID:    first.VendorID,
+			ProductID:   first.ProductID,
+		})
+	}
+	return modems
+}
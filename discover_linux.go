@@ -0,0 +1,64 @@
+package at
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysfsRoot is the mount point of sysfs, overridden in tests.
+var sysfsRoot = "/sys"
+
+// listPorts enumerates the USB serial ports (ttyUSB*, ttyACM*) using sysfs.
+func listPorts() ([]PortInfo, error) {
+	dir := filepath.Join(sysfsRoot, "class", "tty")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ports []PortInfo
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, "ttyUSB") && !strings.HasPrefix(name, "ttyACM") {
+			continue
+		}
+		dev, err := filepath.EvalSymlinks(filepath.Join(dir, name, "device"))
+		if err != nil {
+			continue
+		}
+		info := PortInfo{
+			Path:      filepath.Join("/dev", name),
+			Interface: -1,
+		}
+		// walk up from the tty to the USB interface and then to the USB device
+		for p := dev; len(p) > len(sysfsRoot); p = filepath.Dir(p) {
+			if info.Interface < 0 {
+				if num, ok := readSysfs(p, "bInterfaceNumber"); ok {
+					if n, err := strconv.ParseInt(num, 16, 32); err == nil {
+						info.Interface = int(n)
+					}
+				}
+			}
+			if vid, ok := readSysfs(p, "idVendor"); ok {
+				info.VendorID = vid
+				info.ProductID, _ = readSysfs(p, "idProduct")
+				info.Device = filepath.Base(p)
+				break
+			}
+		}
+		if len(info.VendorID) == 0 {
+			continue
+		}
+		ports = append(ports, info)
+	}
+	return ports, nil
+}
+
+func readSysfs(dir, attr string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, attr))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
@@ -0,0 +1,42 @@
+package at
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPorts(t *testing.T) {
+	root := t.TempDir()
+	usb := filepath.Join(root, "devices", "usb1", "1-1")
+	write := func(dir, attr, value string) {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0644))
+	}
+	write(usb, "idVendor", "12d1")
+	write(usb, "idProduct", "1001")
+	for i, tty := range []string{"ttyUSB0", "ttyUSB1"} {
+		iface := filepath.Join(usb, "1-1:1."+string(rune('0'+i)))
+		write(iface, "bInterfaceNumber", "0"+string(rune('0'+i)))
+		require.NoError(t, os.MkdirAll(filepath.Join(iface, tty), 0755))
+		class := filepath.Join(root, "class", "tty", tty)
+		require.NoError(t, os.MkdirAll(class, 0755))
+		require.NoError(t, os.Symlink(filepath.Join(iface, tty), filepath.Join(class, "device")))
+	}
+	// not a USB port
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "class", "tty", "ttyS0"), 0755))
+
+	saved := sysfsRoot
+	sysfsRoot = root
+	defer func() { sysfsRoot = saved }()
+
+	ports, err := listPorts()
+	require.NoError(t, err)
+	assert.Equal(t, []PortInfo{
+		{Path: "/dev/ttyUSB0", VendorID: "12d1", ProductID: "1001", Interface: 0, Device: "1-1"},
+		{Path: "/dev/ttyUSB1", VendorID: "12d1", ProductID: "1001", Interface: 1, Device: "1-1"},
+	}, ports)
+}
@@ -0,0 +1,40 @@
+//go:build !linux
+// +build !linux
+
+package at
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// portPatterns are the names of the serial ports used by USB modems on systems
+// where the port enumeration is not implemented. USB identifiers are not available there.
+var portPatterns = []string{"/dev/cu.usb*", "/dev/cu.HUAWEI*", "/dev/cu.wwan*", "/dev/ttyU*"}
+
+// listPorts returns the candidate ports by name, on Windows the COM ports are tried.
+func listPorts() ([]PortInfo, error) {
+	var ports []PortInfo
+	if runtime.GOOS == "windows" {
+		for i := 1; i <= 32; i++ {
+			name := fmt.Sprintf(`\\.\COM%d`, i)
+			if _, err := os.Stat(name); err != nil {
+				continue
+			}
+			ports = append(ports, PortInfo{Path: name, Interface: -1})
+		}
+		return ports, nil
+	}
+	for _, pattern := range portPatterns {
+		names, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			ports = append(ports, PortInfo{Path: name, Interface: -1})
+		}
+	}
+	return ports, nil
+}
@@ -0,0 +1,21 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairPorts(t *testing.T) {
+	t.Parallel()
+
+	modems := pairPorts([]PortInfo{
+		{Path: "/dev/ttyUSB2", VendorID: "12d1", ProductID: "1001", Interface: 2, Device: "1-1"},
+		{Path: "/dev/ttyACM0", VendorID: "2c7c", ProductID: "0125", Interface: 0, Device: "1-2"},
+		{Path: "/dev/ttyUSB0", VendorID: "12d1", ProductID: "1001", Interface: 0, Device: "1-1"},
+	})
+	assert.Equal(t, []ModemPorts{
+		{CommandPort: "/dev/ttyUSB0", NotifyPort: "/dev/ttyUSB2", VendorID: "12d1", ProductID: "1001"},
+		{CommandPort: "/dev/ttyACM0", NotifyPort: "/dev/ttyACM0", VendorID: "2c7c", ProductID: "0125"},
+	}, modems)
+}
@@ -0,0 +1,112 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DumpCommands are the commands whose replies are collected by Dump, the commands
+// unsupported by the modem are skipped.
+var DumpCommands = []string{
+	"ATI",            // identification
+	"AT&V",           // active profile and S-registers
+	"AT^GETPORTMODE", // Huawei USB port layout
+	"AT+QCFG=?",      // Quectel extended settings, each of them is read
+}
+
+// ConfigDump is the modem's configuration collected by Dump.
+type ConfigDump struct {
+	Time time.Time
+	// Replies holds the replies by the commands.
+	Replies map[string]string
+	// Errors holds the errors of the failed commands.
+	Errors map[string]string
+	// Settings holds the profile settings parsed from the AT&V reply, i.e. "E" or "S0".
+	Settings map[string]string
+}
+
+// Dump collects the current configuration of the modem for the comparison across a fleet.
+// Only the device closure is reported as an error, failed commands are listed in the dump.
+func (d *Device) Dump() (*ConfigDump, error) {
+	dump := &ConfigDump{
+		Time:    time.Now(),
+		Replies: make(map[string]string),
+		Errors:  make(map[string]string),
+	}
+	query := func(cmd string) (string, bool) {
+		reply, err := d.Send(cmd)
+		if err != nil {
+			dump.Errors[cmd] = err.Error()
+			return "", false
+		}
+		dump.Replies[cmd] = reply
+		return reply, true
+	}
+	for _, cmd := range DumpCommands {
+		reply, ok := query(cmd)
+		if !ok {
+			continue
+		}
+		switch cmd {
+		case "AT&V":
+			dump.Settings = parseProfileSettings(reply)
+		case "AT+QCFG=?":
+			for _, name := range parseQCFGNames(reply) {
+				query(fmt.Sprintf(`AT+QCFG="%s"`, name))
+			}
+		}
+	}
+	if err := d.sanityCheck(true); err != nil {
+		return nil, err
+	}
+	return dump, nil
+}
+
+// parseProfileSettings parses the settings from the AT&V reply, the "E: 1; Q: 0"
+// and "E1 Q0 S0:0" layouts are recognized.
+func parseProfileSettings(reply string) map[string]string {
+	settings := make(map[string]string)
+	for _, line := range strings.Split(reply, "\n") {
+		fields := strings.Fields(strings.ReplaceAll(line, ";", " "))
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			switch {
+			case strings.HasSuffix(field, ":") && i+1 < len(fields):
+				// "E: 1"
+				settings[strings.TrimSuffix(field, ":")] = fields[i+1]
+				i++
+			case strings.Contains(field, ":"):
+				// "S0:0", but not a header like "PROFILE:"
+				if kv := strings.SplitN(field, ":", 2); len(kv[1]) > 0 {
+					settings[kv[0]] = kv[1]
+				}
+			default:
+				// "E1", "&C1"
+				name := strings.TrimRight(field, "0123456789")
+				if len(name) > 0 && len(name) < len(field) {
+					settings[name] = field[len(name):]
+				}
+			}
+		}
+	}
+	return settings
+}
+
+// parseQCFGNames parses the setting names from the AT+QCFG=? reply: +QCFG: "nwscanmode",(0-3).
+func parseQCFGNames(reply string) []string {
+	var names []string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "+QCFG:"))
+		if !strings.HasPrefix(line, `"`) {
+			continue
+		}
+		if end := strings.Index(line[1:], `"`); end > 0 {
+			names = append(names, line[1:end+1])
+		}
+	}
+	return names
+}
@@ -0,0 +1,38 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfileSettings(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[string]string{"&C": "2", "E": "1", "S0": "000"},
+		parseProfileSettings("&C: 2; E: 1; S0: 000;"))
+	assert.Equal(t, map[string]string{"E": "1", "Q": "0", "&D": "2", "S0": "0"},
+		parseProfileSettings("ACTIVE PROFILE:\nE1 Q0 &D2 S0:0"))
+}
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"ATI":                  "Quectel" + Sep + "EC25" + Sep + Sep + "OK",
+		"AT&V":                 "E1 Q0" + Sep + Sep + "OK",
+		"AT+QCFG=?":            `+QCFG: "nwscanmode",(0-3)` + Sep + `+QCFG: "band",(0-F)` + Sep + Sep + "OK",
+		`AT+QCFG="nwscanmode"`: `+QCFG: "nwscanmode",0` + Sep + Sep + "OK",
+	})
+	dump, err := dev.Dump()
+	require.NoError(t, err)
+	assert.Equal(t, "Quectel\nEC25", dump.Replies["ATI"])
+	assert.Equal(t, map[string]string{"E": "1", "Q": "0"}, dump.Settings)
+	assert.Equal(t, `+QCFG: "nwscanmode",0`, dump.Replies[`AT+QCFG="nwscanmode"`])
+	assert.Contains(t, dump.Errors, "AT^GETPORTMODE")
+	assert.Contains(t, dump.Errors, `AT+QCFG="band"`)
+}
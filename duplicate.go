@@ -0,0 +1,66 @@
+package at
+
+import (
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// duplicateKey identifies a received message or a part of a concatenated one.
+type duplicateKey struct {
+	address   sms.PhoneNumber
+	timestamp int64
+	tag       int
+	sequence  int
+	text      string
+}
+
+// receivedKey returns the key of the message.
+func receivedKey(msg *sms.Message) duplicateKey {
+	key := duplicateKey{
+		address:   msg.Address,
+		timestamp: time.Time(msg.ServiceCenterTime).Unix(),
+		text:      msg.Text + string(msg.Data),
+	}
+	if msg.UserDataStartsWithHeader {
+		key.tag, key.sequence = msg.UserDataHeader.Tag, msg.UserDataHeader.Sequence
+	}
+	return key
+}
+
+// duplicate reports whether the same message was received within the DuplicateWindow,
+// otherwise the message is remembered. The status reports are not checked, the repeated
+// ones don't match the submissions anymore.
+func (d *Device) duplicate(msg *sms.Message, now time.Time) bool {
+	if d.DuplicateWindow <= 0 || msg.Type != sms.MessageTypes.Deliver {
+		return false
+	}
+	key := receivedKey(msg)
+
+	d.receivedMux.Lock()
+	defer d.receivedMux.Unlock()
+	for k, t := range d.received {
+		if now.Sub(t) >= d.DuplicateWindow {
+			delete(d.received, k)
+		}
+	}
+	if _, ok := d.received[key]; ok {
+		return true
+	}
+	if d.received == nil {
+		d.received = make(map[duplicateKey]time.Time)
+	}
+	d.received[key] = now
+	return false
+}
+
+// forget drops the message from the received ones, it's not handled and stays
+// in the storage, so it's not a duplicate once it's read again.
+func (d *Device) forget(msg *sms.Message) {
+	if d.DuplicateWindow <= 0 || msg.Type != sms.MessageTypes.Deliver {
+		return
+	}
+	d.receivedMux.Lock()
+	defer d.receivedMux.Unlock()
+	delete(d.received, receivedKey(msg))
+}
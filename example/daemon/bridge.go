@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/xlab/at"
+)
+
+// serveBridge exposes the modem to the web consoles over WebSocket, the admin token
+// authorizes them: /ws/cmd is a virtual command port shared with the daemon (see
+// Device.ServeVirtual) and /ws/notify streams the unsolicited reports.
+func (m *Monitor) serveBridge(w http.ResponseWriter, r *http.Request) {
+	if !m.admin(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if !m.Ready {
+		http.Error(w, "no device", http.StatusServiceUnavailable)
+		return
+	}
+	dev := m.dev
+	if r.URL.Path == "/ws/cmd" {
+		ws, err := at.UpgradeWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		if err = dev.ServeVirtual(ws); err != nil {
+			log.Println("Console bridge closed:", err)
+		}
+		return
+	}
+
+	// subscribed before the handshake completes, so no report is missed
+	reports := dev.Subscribe("")
+	defer dev.Unsubscribe(reports)
+	ws, err := at.UpgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	closed := make(chan struct{})
+	go func() {
+		// the console doesn't write to the notification port, its close is awaited
+		io.Copy(io.Discard, ws)
+		close(closed)
+	}()
+	for {
+		select {
+		case report := <-reports:
+			if _, err := io.WriteString(ws, at.Sep+report+at.Sep); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-dev.Closed():
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xlab/at"
+)
+
+func TestBridge(t *testing.T) {
+	t.Parallel()
+
+	m, urc := newTestMonitor(t, map[string]string{
+		"AT+GMM": "E173" + at.Sep + at.Sep + "OK",
+	})
+	m.AdminToken = "secret"
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	addr := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	console := &at.Device{
+		CommandPort: addr + "/ws/cmd",
+		NotifyPort:  addr + "/ws/notify",
+		Dial:        at.WebSocketConfig{Header: http.Header{"Authorization": {"Bearer secret"}}}.Dialer(),
+		Timeout:     time.Second,
+	}
+	require.NoError(t, console.Open())
+	defer console.Close()
+	require.NoError(t, console.Init(&testProfile{}))
+	reply, err := console.Send("AT+GMM")
+	require.NoError(t, err)
+	assert.Equal(t, "E173", reply)
+
+	notify, err := console.Dial(addr + "/ws/notify")
+	require.NoError(t, err)
+	defer notify.Close()
+	reports := bufio.NewReader(notify)
+	io.WriteString(urc, at.Sep+"+CGEV: ME PDN ACT 1"+at.Sep)
+	for {
+		line, err := reports.ReadString('\n')
+		require.NoError(t, err)
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			assert.Equal(t, "+CGEV: ME PDN ACT 1", line)
+			break
+		}
+	}
+
+	_, err = at.WebSocketConfig{}.Dialer()(addr + "/ws/cmd")
+	assert.ErrorIs(t, err, at.ErrWebSocket, "the admin token is required")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xlab/at"
+	"github.com/xlab/at/sms"
+)
+
+// Inbox keeps the received messages that are not routed to the tenants in memory.
+type Inbox struct {
+	// Retention prunes the messages as the new ones arrive.
+	Retention at.RetentionPolicy
+
+	mux      sync.Mutex
+	messages []at.PersistedMessage
+}
+
+// Add keeps the message received at the time and prunes the old ones.
+func (in *Inbox) Add(msg *sms.Message, now time.Time) {
+	in.mux.Lock()
+	defer in.mux.Unlock()
+	in.messages = append(in.messages, at.PersistedMessage{ID: at.RandomID(), Message: *msg, Received: now})
+	pruned := make(map[string]bool)
+	for _, msg := range in.Retention.Prune(in.messages, now) {
+		pruned[msg.ID] = true
+	}
+	if len(pruned) == 0 {
+		return
+	}
+	kept := in.messages[:0]
+	for _, msg := range in.messages {
+		if !pruned[msg.ID] {
+			kept = append(kept, msg)
+		}
+	}
+	in.messages = kept
+}
+
+// Query returns the messages selected by the query in the order they were received.
+func (in *Inbox) Query(q at.MessageQuery) []at.PersistedMessage {
+	in.mux.Lock()
+	defer in.mux.Unlock()
+	var list []at.PersistedMessage
+	for _, msg := range in.messages {
+		if q.Match(msg) {
+			list = append(list, msg)
+		}
+	}
+	return list
+}
+
+// serveInbox searches the inbox: GET /inbox with the optional "sender", "text", "since" and
+// "until" (RFC 3339) parameters, the messages are exported as CSV with "format=csv".
+func (m *Monitor) serveInbox(w http.ResponseWriter, r *http.Request) {
+	if !m.admin(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	q := at.MessageQuery{
+		Sender: sms.PhoneNumber(r.FormValue("sender")),
+		Text:   r.FormValue("text"),
+	}
+	for _, param := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &q.Since}, {"until", &q.Until}} {
+		if v := r.FormValue(param.name); len(v) > 0 {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "invalid "+param.name, http.StatusBadRequest)
+				return
+			}
+			*param.t = t
+		}
+	}
+	list := m.Inbox.Query(q)
+
+	switch r.FormValue("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="inbox.csv"`)
+		out := csv.NewWriter(w)
+		out.Write([]string{"id", "received", "sender", "sent", "text"})
+		for _, msg := range list {
+			out.Write([]string{
+				msg.ID,
+				msg.Received.Format(time.RFC3339),
+				string(msg.Message.Address),
+				time.Time(msg.Message.ServiceCenterTime).Format(time.RFC3339),
+				msg.Message.Text,
+			})
+		}
+		out.Flush()
+	default:
+		http.Error(w, "unknown format", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xlab/at"
+	"github.com/xlab/at/sms"
+)
+
+func TestInbox(t *testing.T) {
+	t.Parallel()
+
+	m := NewMonitor("cmd", "notify")
+	m.AdminToken = "secret"
+	m.Inbox.Retention = at.RetentionPolicy{MaxMessages: 2}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	m.Inbox.Add(&sms.Message{Address: "+79261234567", Text: "pruned"}, now)
+	m.Inbox.Add(&sms.Message{Address: "+79261234567", Text: "Your code is 1234"}, now.Add(time.Hour))
+	m.Inbox.Add(&sms.Message{Address: "+79267654321", Text: "Balance: 10"}, now.Add(2*time.Hour))
+	require.Len(t, m.Messages(), 2, "the oldest message is pruned")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/inbox?"+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		return w
+	}
+	w := get("sender=%2B79261234567&text=code")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []at.PersistedMessage
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "Your code is 1234", list[0].Message.Text)
+
+	w = get("since=" + now.Add(90*time.Minute).Format(time.RFC3339) + "&format=csv")
+	require.Equal(t, http.StatusOK, w.Code)
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"+79267654321", "Balance: 10"}, []string{records[1][2], records[1][4]})
+
+	assert.Equal(t, http.StatusBadRequest, get("until=yesterday").Code)
+	assert.Equal(t, http.StatusBadRequest, get("format=xml").Code)
+	m.AdminToken = ""
+	assert.Equal(t, http.StatusForbidden, get("").Code)
+}
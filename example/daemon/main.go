@@ -1,16 +1,27 @@
 package main
 
-import "log"
+import (
+	"log"
+	"os"
+)
 
 const (
 	CommandPortPath = "/dev/tty.HUAWEIMobile-Modem"
 	NotifyPortPath  = "/dev/tty.HUAWEIMobile-Pcui"
+	ProfileName     = "e173"
 	WebPort         = 5051
+	TenantsPath     = "tenants.json"
 )
 
 func main() {
 	log.Printf("Staring daemon at http://localhost:%d", WebPort)
 	mon := NewMonitor(CommandPortPath, NotifyPortPath)
+	tenants, err := LoadTenants(TenantsPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	mon.Tenants = tenants
+	mon.AdminToken = os.Getenv("AT_ADMIN_TOKEN")
 
 	if err := mon.Run(); err != nil {
 		log.Fatalln(err)
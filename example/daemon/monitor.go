@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/xlab/at"
@@ -14,6 +15,8 @@ const (
 	BalanceUSSD          = "*100#"
 	BalanceCheckInterval = time.Minute
 	DeviceCheckInterval  = time.Second * 10
+	InboxMaxAge          = 30 * 24 * time.Hour
+	InboxMaxMessages     = 10000
 )
 
 type State uint8
@@ -24,12 +27,17 @@ const (
 )
 
 type Monitor struct {
-	// Messages is the most simpliest volatile DB storing sms messages.
-	Messages []*sms.Message
+	// Inbox is the most simpliest volatile DB storing sms messages.
+	Inbox Inbox
 	// Balance is the balance reply we've got with an USSD query.
 	Balance string
 	// Ready signals if device is ready.
 	Ready bool
+	// Tenants are the applications allowed to send the messages through the daemon,
+	// the replies are routed to them instead of the Inbox.
+	Tenants []*Tenant
+	// AdminToken authorizes the management of the pending commands, it's disabled if empty.
+	AdminToken string
 
 	cmdPort    string
 	notifyPort string
@@ -37,6 +45,14 @@ type Monitor struct {
 	dev          *at.Device
 	stateChanged chan State
 	checkTimer   *time.Timer
+
+	routes    map[sms.PhoneNumber]*Tenant
+	routesMux sync.Mutex
+}
+
+// Messages returns the messages of the inbox.
+func (m *Monitor) Messages() []at.PersistedMessage {
+	return m.Inbox.Query(at.MessageQuery{})
 }
 
 func (m *Monitor) DeviceState() *at.DeviceState {
@@ -45,9 +61,11 @@ func (m *Monitor) DeviceState() *at.DeviceState {
 
 func NewMonitor(cmdPort, notifyPort string) *Monitor {
 	return &Monitor{
+		Inbox:        Inbox{Retention: at.RetentionPolicy{MaxAge: InboxMaxAge, MaxMessages: InboxMaxMessages}},
 		cmdPort:      cmdPort,
 		notifyPort:   notifyPort,
 		stateChanged: make(chan State, 10),
+		routes:       make(map[sms.PhoneNumber]*Tenant),
 	}
 }
 
@@ -110,8 +128,8 @@ func (m *Monitor) Run() (err error) {
 								m.Balance = string(ussd)
 							}
 						case msg, ok := <-m.dev.IncomingSms():
-							if ok {
-								m.Messages = append(m.Messages, msg)
+							if ok && !m.route(msg) {
+								m.Inbox.Add(msg, time.Now())
 							}
 						case <-t.C:
 							m.dev.SendUSSD(BalanceUSSD)
@@ -133,7 +151,11 @@ func (m *Monitor) openDevice() (err error) {
 	if err = m.dev.Open(); err != nil {
 		return
 	}
-	if err = m.dev.Init(at.DeviceE173()); err != nil {
+	profile, err := at.ProfileByName(ProfileName)
+	if err != nil {
+		return
+	}
+	if err = m.dev.Init(profile); err != nil {
 		return
 	}
 	return
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xlab/at"
+)
+
+// testProfile is the DefaultProfile that doesn't configure the device on Init,
+// the messages are accepted without the modem unless fail is set.
+type testProfile struct {
+	at.DefaultProfile
+	fail error
+	// ids are the correlation IDs of the accepted messages.
+	ids []string
+}
+
+func (p *testProfile) Init(d *at.Device) error {
+	_, err := d.Send(at.NoopCmd)
+	return err
+}
+
+func (p *testProfile) CMGS(length int, octets []byte) (byte, error) {
+	return p.CMGSPriority(length, octets, at.PriorityNormal, "")
+}
+
+func (p *testProfile) CMGSPriority(length int, octets []byte, prio at.Priority, id string) (byte, error) {
+	if p.fail != nil {
+		return 0, p.fail
+	}
+	p.ids = append(p.ids, id)
+	return 1, nil
+}
+
+// serveModem replies to the commands with the replies, the unknown ones are answered with OK.
+func serveModem(conn io.ReadWriter, replies map[string]string) {
+	buf := bufio.NewReader(conn)
+	for {
+		line, err := buf.ReadString('\r')
+		if err != nil {
+			return
+		}
+		req := strings.TrimSpace(line)
+		if len(req) == 0 {
+			continue
+		}
+		reply, ok := replies[req]
+		if !ok {
+			reply = "OK"
+		}
+		if _, err = io.WriteString(conn, req+at.Sep+reply+at.Sep); err != nil {
+			return
+		}
+	}
+}
+
+// newTestMonitor returns a ready monitor of the emulated modem and the modem's end
+// of the notification port.
+func newTestMonitor(t *testing.T, replies map[string]string) (*Monitor, net.Conn) {
+	t.Helper()
+	return newProfileMonitor(t, &testProfile{}, replies)
+}
+
+// newProfileMonitor is like newTestMonitor, but the device is initialized with the profile.
+func newProfileMonitor(t *testing.T, profile at.DeviceProfile, replies map[string]string) (*Monitor, net.Conn) {
+	t.Helper()
+	cmd, modem := net.Pipe()
+	notify, urc := net.Pipe()
+	go serveModem(modem, replies)
+
+	m := NewMonitor("cmd", "notify")
+	m.dev = &at.Device{Timeout: time.Second}
+	require.NoError(t, m.dev.Attach(cmd, notify))
+	require.NoError(t, m.dev.Init(profile))
+	go m.dev.Watch()
+	m.Ready = true
+	t.Cleanup(func() {
+		m.dev.Close()
+		modem.Close()
+		urc.Close()
+	})
+	return m, urc
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	m, _ := newTestMonitor(t, nil)
+	m.dev.State = at.NewDeviceState()
+	m.dev.State.SimState = at.SimStates.Valid
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Unknown service, SIM ready\n", w.Body.String())
+
+	m.Ready = false
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
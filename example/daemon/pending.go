@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xlab/at"
+)
+
+var priorities = map[string]at.Priority{
+	"low":    at.PriorityLow,
+	"normal": at.PriorityNormal,
+	"high":   at.PriorityHigh,
+}
+
+// admin reports whether the request is authorized by the admin token, the admin
+// endpoints are disabled if it's not set.
+func (m *Monitor) admin(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return len(m.AdminToken) > 0 && subtle.ConstantTimeCompare([]byte(token), []byte(m.AdminToken)) == 1
+}
+
+// servePending manages the commands waiting for the command port:
+// GET /pending lists them, POST /pending/cancel and /pending/priority with the "id"
+// (and the "priority": low, normal or high) form values cancel or reprioritize one,
+// POST /pending/flush cancels all of them.
+func (m *Monitor) servePending(w http.ResponseWriter, r *http.Request) {
+	if !m.admin(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if !m.Ready {
+		http.Error(w, "no device", http.StatusServiceUnavailable)
+		return
+	}
+	if r.URL.Path == "/pending" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.dev.Pending())
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path == "/pending/flush" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int{"canceled": m.dev.FlushPending()})
+		return
+	}
+	id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	switch r.URL.Path {
+	case "/pending/cancel":
+		ok = m.dev.CancelPending(id)
+	case "/pending/priority":
+		prio, known := priorities[r.FormValue("priority")]
+		if !known {
+			http.Error(w, "invalid priority", http.StatusBadRequest)
+			return
+		}
+		ok = m.dev.Reprioritize(id, prio)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if !ok {
+		http.Error(w, "the command is not pending", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xlab/at"
+	"github.com/xlab/at/sms"
+)
+
+var (
+	ErrDestination = errors.New("destination is not allowed")
+	ErrQuota       = errors.New("daily quota is exceeded")
+)
+
+// MaxHistory is the number of the latest records kept in the tenant's history.
+const MaxHistory = 1000
+
+// Record is an entry of the tenant's message history.
+type Record struct {
+	Time    time.Time       `json:"time"`
+	Inbound bool            `json:"inbound"`
+	Address sms.PhoneNumber `json:"address"`
+	Text    string          `json:"text"`
+	// CorrelationID of the sent message, see at.SendOptions.
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Tenant is an internal application sharing the modem, it's identified by its token.
+type Tenant struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+	// MaxPerDay is the quota of the messages sent by the tenant per day, unlimited if zero.
+	MaxPerDay int `json:"max_per_day"`
+	// Destinations are the allowed destination patterns (i.e. "+7926*"), see path.Match.
+	Destinations []string `json:"destinations"`
+	// Webhook receives the replies to the tenant's messages as JSON records, if set.
+	Webhook string `json:"webhook"`
+
+	mux     sync.Mutex
+	day     time.Time
+	sent    int
+	history []Record
+}
+
+// LoadTenants reads the tenants from the JSON file, there are none if it doesn't exist.
+func LoadTenants(name string) ([]*Tenant, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var tenants []*Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func (t *Tenant) allowed(address sms.PhoneNumber) bool {
+	for _, pattern := range t.Destinations {
+		if ok, _ := path.Match(pattern, string(address)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// reserve checks the destination and takes one message of the daily quota,
+// it returns the day of the reservation.
+func (t *Tenant) reserve(address sms.PhoneNumber, now time.Time) (time.Time, error) {
+	if !t.allowed(address) {
+		return time.Time{}, ErrDestination
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if day := now.Truncate(24 * time.Hour); !day.Equal(t.day) {
+		t.day, t.sent = day, 0
+	}
+	if t.MaxPerDay > 0 && t.sent >= t.MaxPerDay {
+		return time.Time{}, ErrQuota
+	}
+	t.sent++
+	return t.day, nil
+}
+
+// release returns the message reserved on the day to the quota if it has not been sent,
+// the quota of the next day is not affected.
+func (t *Tenant) release(day time.Time) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if day.Equal(t.day) && t.sent > 0 {
+		t.sent--
+	}
+}
+
+// record adds the record to the history, the oldest ones are dropped beyond MaxHistory.
+func (t *Tenant) record(r Record) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.history = append(t.history, r)
+	if n := len(t.history) - MaxHistory; n > 0 {
+		t.history = append(t.history[:0], t.history[n:]...)
+	}
+}
+
+// History returns the messages sent and received by the tenant.
+func (t *Tenant) History() []Record {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return append([]Record(nil), t.history...)
+}
+
+// notify posts the inbound record to the tenant's webhook.
+func (t *Tenant) notify(r Record) {
+	if len(t.Webhook) == 0 {
+		return
+	}
+	body, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	resp, err := http.Post(t.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Webhook of %s failed: %v", t.Name, err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// tenant returns the tenant authorized by the bearer token of the request.
+func (m *Monitor) tenant(r *http.Request) *Tenant {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if len(token) == 0 {
+		return nil
+	}
+	for _, t := range m.Tenants {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return t
+		}
+	}
+	return nil
+}
+
+// send sends the message on behalf of the tenant and returns its correlation ID, the replies
+// from the address are routed to the tenant afterwards. The replies are not correlated with
+// the messages, so if several tenants write to the same address, the one that wrote last gets them.
+func (m *Monitor) send(t *Tenant, address sms.PhoneNumber, text string) (id string, err error) {
+	now := time.Now()
+	day, err := t.reserve(address, now)
+	if err != nil {
+		return "", err
+	}
+	id = at.GenerateID()
+	if err := m.dev.SendLongSMS(text, address, at.SendOptions{CorrelationID: id}); err != nil {
+		t.release(day)
+		return id, err
+	}
+	t.record(Record{Time: now, Address: address, Text: text, CorrelationID: id})
+	m.routesMux.Lock()
+	m.routes[address] = t
+	m.routesMux.Unlock()
+	return id, nil
+}
+
+// route delivers the incoming message to the tenant that has last written to its sender,
+// it's reported by the false if there is none.
+func (m *Monitor) route(msg *sms.Message) bool {
+	m.routesMux.Lock()
+	t, ok := m.routes[msg.Address]
+	m.routesMux.Unlock()
+	if !ok {
+		return false
+	}
+	r := Record{Time: time.Now(), Inbound: true, Address: msg.Address, Text: msg.Text}
+	t.record(r)
+	go t.notify(r)
+	return true
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xlab/at/sms"
+)
+
+func TestTenantQuota(t *testing.T) {
+	t.Parallel()
+
+	tenant := &Tenant{MaxPerDay: 2, Destinations: []string{"+7926*"}}
+	now := time.Date(2026, 10, 1, 23, 59, 0, 0, time.UTC)
+	_, err := tenant.reserve("+79267654321", now)
+	require.NoError(t, err)
+	day, err := tenant.reserve("+79267654321", now)
+	require.NoError(t, err)
+	_, err = tenant.reserve("+79267654321", now)
+	assert.ErrorIs(t, err, ErrQuota)
+
+	tenant.release(day)
+	_, err = tenant.reserve("+79267654321", now)
+	assert.NoError(t, err, "the released message is returned to the quota")
+
+	_, err = tenant.reserve("+79267654321", now.Add(time.Minute))
+	require.NoError(t, err)
+	tenant.release(day)
+	_, err = tenant.reserve("+79267654321", now.Add(time.Minute))
+	require.NoError(t, err)
+	_, err = tenant.reserve("+79267654321", now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrQuota, "the release of the previous day doesn't affect the next one")
+
+	_, err = tenant.reserve("+79031234567", now)
+	assert.ErrorIs(t, err, ErrDestination)
+}
+
+func TestTenantHistory(t *testing.T) {
+	t.Parallel()
+
+	tenant := &Tenant{}
+	for i := 0; i < MaxHistory+10; i++ {
+		tenant.record(Record{Text: "message"})
+	}
+	assert.Len(t, tenant.History(), MaxHistory)
+}
+
+func TestTenants(t *testing.T) {
+	t.Parallel()
+
+	profile := &testProfile{}
+	m, _ := newProfileMonitor(t, profile, nil)
+	billing := &Tenant{Name: "billing", Token: "billing", MaxPerDay: 1, Destinations: []string{"+7926*"}}
+	support := &Tenant{Name: "support", Token: "support", Destinations: []string{"*"}}
+	m.Tenants = []*Tenant{billing, support}
+
+	send := func(token, to string) *httptest.ResponseRecorder {
+		form := url.Values{"to": {to}, "text": {"hello"}}
+		req := httptest.NewRequest(http.MethodPost, "/send", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusUnauthorized, send("unknown", "+79261234567").Code)
+	assert.Equal(t, http.StatusForbidden, send("billing", "+79031234567").Code)
+	profile.fail = errors.New("network timeout")
+	assert.Equal(t, http.StatusBadGateway, send("billing", "+79261234567").Code)
+	profile.fail = nil
+	w := send("billing", "+79261234567")
+	require.Equal(t, http.StatusAccepted, w.Code, "the failed message is not counted")
+	var sent struct {
+		CorrelationID string `json:"correlation_id"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&sent))
+	assert.NotEmpty(t, sent.CorrelationID)
+	assert.Equal(t, sent.CorrelationID, w.Header().Get("X-Correlation-ID"))
+	assert.Equal(t, []string{sent.CorrelationID}, profile.ids, "the message is sent with the correlation ID")
+	assert.Equal(t, http.StatusTooManyRequests, send("billing", "+79261234567").Code)
+	assert.Equal(t, http.StatusAccepted, send("support", "+79031234567").Code)
+
+	assert.True(t, m.route(&sms.Message{Address: "+79261234567", Text: "thanks"}))
+	assert.False(t, m.route(&sms.Message{Address: "+79037654321", Text: "unknown"}))
+
+	history := func(token string) (records []Record) {
+		req := httptest.NewRequest(http.MethodGet, "/history", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&records))
+		return
+	}
+	records := history("billing")
+	require.Len(t, records, 2)
+	assert.False(t, records[0].Inbound)
+	assert.Equal(t, sent.CorrelationID, records[0].CorrelationID)
+	assert.True(t, records[1].Inbound)
+	assert.Equal(t, "thanks", records[1].Text)
+	records = history("support")
+	require.Len(t, records, 1, "the tenants don't see each other's messages")
+	assert.Equal(t, "+79031234567", string(records[0].Address))
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -13,6 +15,29 @@ import (
 )
 
 func (m *Monitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/capabilities":
+		m.serveCapabilities(w)
+		return
+	case "/status":
+		m.serveStatus(w)
+		return
+	case "/send":
+		m.serveSend(w, r)
+		return
+	case "/history":
+		m.serveHistory(w, r)
+		return
+	case "/pending", "/pending/cancel", "/pending/priority", "/pending/flush":
+		m.servePending(w, r)
+		return
+	case "/inbox":
+		m.serveInbox(w, r)
+		return
+	case "/ws/cmd", "/ws/notify":
+		m.serveBridge(w, r)
+		return
+	}
 	data := struct {
 		Mon  *Monitor
 		Dev  *at.Device
@@ -31,6 +56,76 @@ func (m *Monitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	io.Copy(w, &buf)
 }
 
+func (m *Monitor) serveCapabilities(w http.ResponseWriter) {
+	if !m.Ready {
+		http.Error(w, "no device", http.StatusServiceUnavailable)
+		return
+	}
+	caps, err := m.dev.Capabilities()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(caps)
+}
+
+// serveStatus serves the status line of the device, i.e. for the health checks.
+func (m *Monitor) serveStatus(w http.ResponseWriter) {
+	if !m.Ready {
+		http.Error(w, "no device", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, m.dev.State.Summary()+"\n")
+}
+
+// serveSend sends the message of the tenant: POST /send with the "to" and "text" form values.
+// The correlation ID of the message is returned in the X-Correlation-ID header, and in the
+// JSON body once the message is accepted.
+func (m *Monitor) serveSend(w http.ResponseWriter, r *http.Request) {
+	t := m.tenant(r)
+	if t == nil {
+		http.Error(w, "unknown tenant", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !m.Ready {
+		http.Error(w, "no device", http.StatusServiceUnavailable)
+		return
+	}
+	id, err := m.send(t, sms.PhoneNumber(r.FormValue("to")), r.FormValue("text"))
+	if len(id) > 0 {
+		w.Header().Set("X-Correlation-ID", id)
+	}
+	switch {
+	case err == nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"correlation_id": id})
+	case errors.Is(err, ErrDestination):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrQuota):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+// serveHistory serves the messages of the tenant as JSON.
+func (m *Monitor) serveHistory(w http.ResponseWriter, r *http.Request) {
+	t := m.tenant(r)
+	if t == nil {
+		http.Error(w, "unknown tenant", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.History())
+}
+
 func decorateSignalStrength(n int) string {
 	switch {
 	case n == 0:
@@ -85,6 +180,9 @@ const indexTpl = `<!DOCTYPE html>
         </div>
         <div class="row">
         	{{ if .Mon.Ready }}
+            <div class="col-xs-12">
+                <p class="lead">{{ .Dev.State.Summary }}</p>
+            </div>
             <div class="col-xs-6">
                 <h4>Operator</h4>
                 <p>{{ .Dev.State.OperatorName }}</p>
@@ -117,9 +215,9 @@ const indexTpl = `<!DOCTYPE html>
         	{{ range $k,$v := .Mon.Messages }}
         	<tr>
                 <th>{{ inc $k }}</th>
-                <td>{{ timestamp $v.ServiceCenterTime }}</td>
-                <td>{{ $v.Address }}</td>
-                <td>{{ $v.Text }}</td>
+                <td>{{ timestamp $v.Message.ServiceCenterTime }}</td>
+                <td>{{ $v.Message.Address }}</td>
+                <td>{{ $v.Message.Text }}</td>
             </tr>
             {{ else }}
             <tr>
@@ -0,0 +1,49 @@
+package at
+
+import "sync"
+
+// FailurePolicy configures the reaction on the repeated failures of a device.
+// The failures of SendSMS, SelfTest and the Watchdog's health checks are counted,
+// any success resets the counter.
+type FailurePolicy struct {
+	// Threshold is the number of consecutive failures that triggers the callback.
+	Threshold int
+	// OnFailure is invoked once the threshold is reached with the last error,
+	// then again only after a success and another series of failures.
+	OnFailure func(d *Device, err error)
+
+	mux      sync.Mutex
+	failures int
+}
+
+// result counts the outcome of an operation and invokes the callback if the threshold is reached.
+func (p *FailurePolicy) result(d *Device, err error) {
+	if p == nil {
+		return
+	}
+	p.mux.Lock()
+	if err == nil {
+		p.failures = 0
+		p.mux.Unlock()
+		if d != nil && d.Lifecycle() == LifecycleStates.Degraded {
+			d.transition(LifecycleStates.Ready, nil)
+		}
+		return
+	}
+	p.failures++
+	trigger := p.failures == p.Threshold
+	p.mux.Unlock()
+	if trigger && d != nil {
+		d.transition(LifecycleStates.Degraded, err)
+	}
+	if trigger && p.OnFailure != nil {
+		p.OnFailure(d, err)
+	}
+}
+
+// Failures returns the number of consecutive failures.
+func (p *FailurePolicy) Failures() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.failures
+}
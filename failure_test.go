@@ -0,0 +1,34 @@
+package at
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	p := &FailurePolicy{
+		Threshold: 2,
+		OnFailure: func(d *Device, err error) { calls++ },
+	}
+	failed := errors.New("failed")
+	p.result(nil, failed)
+	assert.Equal(t, 0, calls)
+	p.result(nil, failed)
+	p.result(nil, failed)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 3, p.Failures())
+
+	p.result(nil, nil)
+	assert.Equal(t, 0, p.Failures())
+	p.result(nil, failed)
+	p.result(nil, failed)
+	assert.Equal(t, 2, calls)
+
+	var none *FailurePolicy
+	none.result(nil, failed) // no-op
+}
@@ -0,0 +1,140 @@
+//go:build ignore
+// +build ignore
+
+// This program generates opts_values.go from the option sets of the package.
+// An option set is an exported variable of an anonymous struct with the Resolve
+// and AllValues fields followed by the options, i.e. SimStates. The AllValues
+// of a set lists its options in the order of the fields.
+//
+// Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const output = "opts_values.go"
+
+type set struct {
+	name   string
+	elem   string
+	fields []string
+	values []string
+}
+
+func main() {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(files)
+
+	fset := token.NewFileSet()
+	var sets []set
+	for _, file := range files {
+		if file == output || strings.HasSuffix(file, "_test.go") || strings.HasPrefix(file, "gen_") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.VAR {
+				for _, spec := range gen.Specs {
+					if s, ok := optionSet(fset, spec.(*ast.ValueSpec)); ok {
+						sets = append(sets, s)
+					}
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_opts.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package at\n\n")
+	for _, s := range sets {
+		fmt.Fprintf(&buf, "// %s returns the options of %s in the order of its fields.\n", funcName(s.name), s.name)
+		fmt.Fprintf(&buf, "func %s() []%s {\n", funcName(s.name), s.elem)
+		fmt.Fprintf(&buf, "\treturn []%s{\n", s.elem)
+		for i, value := range s.values {
+			fmt.Fprintf(&buf, "\t\t%s, // %s\n", value, s.fields[i])
+		}
+		buf.WriteString("\t}\n}\n\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// optionSet returns the option set declared by the spec, if it's one.
+func optionSet(fset *token.FileSet, spec *ast.ValueSpec) (s set, ok bool) {
+	if len(spec.Names) != 1 || len(spec.Values) != 1 || !spec.Names[0].IsExported() {
+		return
+	}
+	lit, ok := spec.Values[0].(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	typ, ok := lit.Type.(*ast.StructType)
+	if !ok {
+		return s, false
+	}
+	var names []string
+	for _, field := range typ.Fields.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+			if name.Name != "AllValues" {
+				continue
+			}
+			fn, ok := field.Type.(*ast.FuncType)
+			if !ok || fn.Results == nil || len(fn.Results.List) != 1 {
+				log.Fatalf("%s: AllValues of %s is not a func() []T", fset.Position(field.Pos()), spec.Names[0].Name)
+			}
+			s.elem = strings.TrimPrefix(source(fset, fn.Results.List[0].Type), "[]")
+		}
+	}
+	if len(names) < 2 || names[0] != "Resolve" || names[1] != "AllValues" {
+		return s, false
+	}
+	if len(lit.Elts) != len(names) {
+		log.Fatalf("%s: %s has %d fields, but %d values", fset.Position(lit.Pos()), spec.Names[0].Name, len(names), len(lit.Elts))
+	}
+	s.name = spec.Names[0].Name
+	s.fields = names[2:]
+	for _, elt := range lit.Elts[2:] {
+		s.values = append(s.values, source(fset, elt))
+	}
+	return s, true
+}
+
+func source(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		log.Fatal(err)
+	}
+	return buf.String()
+}
+
+// funcName returns the name of the generated function, i.e. simStatesValues for SimStates.
+func funcName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:] + "Values"
+}
@@ -0,0 +1,101 @@
+package at
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenericProfile is the DeviceProfile implementation that uses only the standard
+// 3GPP TS 27.007 commands. The system info is collected from AT+CREG?, AT+CPIN?
+// and AT+COPS?, the signal strength is read with AT+CSQ. It's a safe choice
+// for the unknown hardware.
+type GenericProfile struct {
+	DefaultProfile
+}
+
+// DeviceGeneric returns an instance of DeviceProfile implementation that relies
+// on the standard commands only.
+func DeviceGeneric() DeviceProfile {
+	return &GenericProfile{}
+}
+
+// Init invokes the DefaultProfile's Init and reads the signal strength.
+func (p *GenericProfile) Init(d *Device) (err error) {
+	if err = p.DefaultProfile.Init(d); err != nil {
+		return
+	}
+	if p.dev.State.SignalStrength, err = p.CSQ(); err != nil {
+		return fmt.Errorf("at init: unable to read signal strength: %w", err)
+	}
+	return nil
+}
+
+// CSQ sends AT+CSQ to the device and returns the RSSI: 0 is -113 dBm or less,
+// 31 is -51 dBm or greater, 99 if not known or not detectable.
+func (p *DefaultProfile) CSQ() (rssi int, err error) {
+	reply, err := p.dev.Send(`AT+CSQ`)
+	if err != nil {
+		return
+	}
+	// +CSQ: <rssi>,<ber>
+	fields := strings.Split(strings.TrimPrefix(reply, "+CSQ:"), ",")
+	var report signalStrengthReport
+	if err = report.Parse(strings.TrimSpace(fields[0])); err != nil {
+		return 0, ErrParseReport
+	}
+	return int(report), nil
+}
+
+// copsAccessTechnologies maps the <AcT> of AT+COPS? to the system mode and submode.
+var copsAccessTechnologies = map[string][2]Opt{
+	"0": {SystemModes.GsmGprs, SystemSubmodes.GSM},
+	"2": {SystemModes.WCDMA, SystemSubmodes.WCDMA},
+	"3": {SystemModes.GsmGprs, SystemSubmodes.EDGE},
+	"4": {SystemModes.WCDMA, SystemSubmodes.HSDPA},
+	"5": {SystemModes.WCDMA, SystemSubmodes.HSUPA},
+	"6": {SystemModes.WCDMA, SystemSubmodes.HsdpaHsupa},
+	"7": {SystemModes.LTE, UnknownOpt},
+}
+
+// SYSINFO collects the system info from AT+CREG?, AT+CPIN? and AT+COPS?.
+func (p *GenericProfile) SYSINFO() (info *SystemInfoReport, err error) {
+	if info, err = p.registration(); err != nil {
+		return nil, err
+	}
+	info.SimState = p.simState()
+
+	// +COPS: <mode>[,<format>,<oper>[,<AcT>]]
+	if reply, err := p.dev.Send(`AT+COPS?`); err == nil {
+		fields := strings.Split(strings.TrimPrefix(reply, "+COPS:"), ",")
+		if len(fields) == 4 {
+			if modes, ok := copsAccessTechnologies[strings.TrimSpace(fields[3])]; ok {
+				info.SystemMode, info.SystemSubmode = modes[0], modes[1]
+			}
+		}
+	}
+	return info, nil
+}
+
+// CarrierLock gets the network personalization state with the standard AT+CLCK="PN",2,
+// the vendor AT^CARDLOCK? is not probed. The attempts are not reported, -1 is returned.
+func (p *GenericProfile) CarrierLock() (state Opt, attempts int, err error) {
+	return p.networkLock()
+}
+
+// CardUnlock sends AT+CLCK="PN",0 with the given network unlock code (NCK) to the device.
+func (p *GenericProfile) CardUnlock(code string) (err error) {
+	req := fmt.Sprintf(`AT+CLCK="PN",0,"%s"`, code)
+	_, err = p.dev.Send(req)
+	return
+}
+
+// SYSCFG is not supported by the generic profile, the mode selection commands
+// are vendor-specific.
+func (p *GenericProfile) SYSCFG(roaming, cellular bool) error {
+	return ErrUnsupported
+}
+
+// BOOT is a no-op, the ^BOOT handshake is specific to Huawei modems.
+func (p *GenericProfile) BOOT(token uint64) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenericInit(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newProfileDevice(t, DeviceGeneric(), map[string]string{
+		"AT+COPS=0,0":            "OK",
+		"AT+CREG?":               "+CREG: 0,1" + Sep + Sep + "OK",
+		"AT+CPIN?":               "+CPIN: SIM PIN" + Sep + Sep + "OK",
+		"AT+COPS?":               `+COPS: 0,0,"MegaFon",2` + Sep + Sep + "OK",
+		"AT+GMM":                 "Unknown" + Sep + Sep + "OK",
+		"AT+GSN":                 "351234567890123" + Sep + Sep + "OK",
+		"AT+CMGF=0":              "OK",
+		"AT+CPMS=?":              `+CPMS: ("SM"),("SM"),("SM")` + Sep + Sep + "OK",
+		`AT+CPMS="SM","SM","SM"`: "+CPMS: 0,30,0,30,0,30" + Sep + Sep + "OK",
+		"AT+CNMI=1,1,0,2,0":      "OK",
+		"AT+CNMI?":               "+CNMI: 1,1,0,2,0" + Sep + Sep + "OK",
+		"AT+CLIP=1":              "OK",
+		"AT+CPMS?":               `+CPMS: "SM",0,20,"SM",0,20,"SM",0,20` + Sep + Sep + "OK",
+		"AT+CSQ":                 "+CSQ: 17,99" + Sep + Sep + "OK",
+		`AT+CLCK="PN",2`:         "+CLCK: 0" + Sep + Sep + "OK",
+		// the vendor probe is not sent
+		"AT^CARDLOCK?": "^CARDLOCK: 1,10,0" + Sep + Sep + "OK",
+	})
+
+	assert.Equal(t, ServiceStates.Valid, dev.State.ServiceState)
+	assert.Equal(t, SystemModes.WCDMA, dev.State.SystemMode)
+	assert.Equal(t, SimStates.Invalid, dev.State.SimState)
+	assert.Equal(t, "MegaFon", dev.State.OperatorName)
+	assert.Equal(t, 17, dev.State.SignalStrength)
+	assert.Equal(t, LockStates.Unlocked, dev.State.CarrierLock)
+	assert.ErrorIs(t, dev.Commands.SYSCFG(false, true), ErrUnsupported)
+}
@@ -1,6 +1,9 @@
 package at
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 func parseUint8(str string) (uint8, error) {
 	i, err := strconv.ParseUint(str, 10, 8)
@@ -11,3 +14,54 @@ func parseUint16(str string) (uint16, error) {
 	i, err := strconv.ParseUint(str, 10, 16)
 	return uint16(i), err
 }
+
+// valueRange is a range of the parameter values, both bounds are inclusive.
+type valueRange struct {
+	From, To int
+}
+
+// valueRanges are the allowed values of a test command parameter.
+type valueRanges []valueRange
+
+// Contains reports whether the value is within any of the ranges.
+func (r valueRanges) Contains(v int) bool {
+	for _, b := range r {
+		if v >= b.From && v <= b.To {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRanges parses the parameter ranges of a test command reply,
+// i.e. (0-3),(0-3),(0,2),(0-2),(0,1) into the bounds of the allowed values.
+func parseRanges(str string) (ranges []valueRanges, err error) {
+	str = strings.TrimSpace(str)
+	for len(str) > 0 {
+		if str[0] != '(' {
+			return nil, ErrParseReport
+		}
+		end := strings.IndexByte(str, ')')
+		if end < 0 {
+			return nil, ErrParseReport
+		}
+		var allowed valueRanges
+		for _, item := range strings.Split(str[1:end], ",") {
+			bounds := strings.SplitN(strings.TrimSpace(item), "-", 2)
+			from, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, ErrParseReport
+			}
+			to := from
+			if len(bounds) > 1 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, ErrParseReport
+				}
+			}
+			allowed = append(allowed, valueRange{From: from, To: to})
+		}
+		ranges = append(ranges, allowed)
+		str = strings.TrimPrefix(strings.TrimSpace(str[end+1:]), ",")
+	}
+	return ranges, nil
+}
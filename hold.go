@@ -0,0 +1,179 @@
+package at
+
+import (
+	"errors"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// ErrHeld is returned when the message is held until the service returns, see HoldPolicy.
+var ErrHeld = errors.New("at: message is held until the service returns")
+
+// ErrHoldFull is returned when the message can't be held, the HoldPolicy limit is reached.
+var ErrHoldFull = errors.New("at: too many messages are held")
+
+// HoldPolicy holds the messages sent with SendSMS, SendLongSMS and SendData while the modem
+// has no service (i.e. it's not registered) instead of failing them. The held messages are
+// sent when the service returns, the events are reported to the HoldEvents channel.
+type HoldPolicy struct {
+	// Limit is the maximum number of the held messages, unlimited if zero.
+	Limit int
+	// TTL is the time after which a held message expires, it's never expired if zero.
+	TTL time.Duration
+}
+
+var holdEvents = stringOpts{
+	{"queued", "Held until the service returns"},
+	{"flushed", "Sent after the service returned"},
+	{"expired", "Not sent within the TTL"},
+}
+
+// HoldEvents represent the events of the held messages.
+var HoldEvents = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	Queued  StringOpt
+	Flushed StringOpt
+	Expired StringOpt
+}{
+	func(str string) StringOpt { return holdEvents.Resolve(str) },
+	holdEventsValues,
+
+	holdEvents[0], holdEvents[1], holdEvents[2],
+}
+
+// HoldEvent is reported when a message is held, flushed or expired.
+type HoldEvent struct {
+	Event         StringOpt
+	CorrelationID string
+	Address       sms.PhoneNumber
+	// Err is the result of sending the flushed message.
+	Err  error
+	Time time.Time
+}
+
+type heldMessage struct {
+	parts []sms.Message
+	opts  SendOptions
+	timer *time.Timer
+}
+
+// inService reports whether the modem is able to send the messages. The unknown
+// state is treated as the valid one, the modem may not report it at all.
+func (d *Device) inService() bool {
+	if d.State == nil {
+		return true
+	}
+	s := d.State.ServiceState
+	return s == ServiceStates.Valid || s == UnknownOpt
+}
+
+// submitSMS holds the message if the modem has no service, otherwise it's sent right away.
+func (d *Device) submitSMS(m sms.Message, split bool, o SendOptions) error {
+	parts, err := d.split(m, split)
+	if err != nil {
+		return err
+	}
+	_, err = d.submitParts(parts, o)
+	return err
+}
+
+// submitParts is like submitSMS, but the message is already split, i.e. the rest of the parts
+// is sent again. It returns the references of the parts sent.
+func (d *Device) submitParts(parts []sms.Message, o SendOptions) ([]byte, error) {
+	if d.Hold == nil || d.inService() {
+		return d.sendParts(parts, o)
+	}
+	if o.CorrelationID == "" {
+		o.CorrelationID = GenerateID()
+	}
+	msg := &heldMessage{parts: parts, opts: o}
+
+	d.holdMux.Lock()
+	if d.Hold.Limit > 0 && len(d.held) >= d.Hold.Limit {
+		d.holdMux.Unlock()
+		return nil, ErrHoldFull
+	}
+	d.held = append(d.held, msg)
+	if d.Hold.TTL > 0 {
+		msg.timer = time.AfterFunc(d.Hold.TTL, func() { d.expireHeld(msg) })
+	}
+	d.holdMux.Unlock()
+
+	d.holdEvent(HoldEvents.Queued, msg, nil)
+	return nil, ErrHeld
+}
+
+// expireHeld drops the message if it's still held.
+func (d *Device) expireHeld(msg *heldMessage) {
+	d.holdMux.Lock()
+	var found bool
+	for i, m := range d.held {
+		if m == msg {
+			d.held = append(d.held[:i], d.held[i+1:]...)
+			found = true
+			break
+		}
+	}
+	d.holdMux.Unlock()
+	if found {
+		d.holdEvent(HoldEvents.Expired, msg, nil)
+	}
+}
+
+// flushHeld sends the held messages in order, it's called when the service returns.
+// The messages are held again if the service is lost during the flush.
+func (d *Device) flushHeld() {
+	d.flushMux.Lock()
+	defer d.flushMux.Unlock()
+	for d.inService() {
+		d.holdMux.Lock()
+		if len(d.held) == 0 {
+			d.holdMux.Unlock()
+			return
+		}
+		msg := d.held[0]
+		d.held = d.held[1:]
+		if msg.timer != nil {
+			msg.timer.Stop()
+		}
+		d.holdMux.Unlock()
+
+		_, err := d.sendParts(msg.parts, msg.opts)
+		d.holdEvent(HoldEvents.Flushed, msg, err)
+	}
+}
+
+func (d *Device) holdEvent(event StringOpt, msg *heldMessage, err error) {
+	select {
+	case d.holdEvents <- HoldEvent{
+		Event:         event,
+		CorrelationID: msg.opts.CorrelationID,
+		Address:       msg.parts[0].Address,
+		Err:           err,
+		Time:          time.Now(),
+	}:
+	default:
+	}
+}
+
+// serviceChanged flushes the held messages when the service returns.
+func (d *Device) serviceChanged() {
+	if d.Hold != nil && d.inService() {
+		go d.flushHeld()
+	}
+}
+
+// HeldMessages returns the number of the messages held until the service returns.
+func (d *Device) HeldMessages() int {
+	d.holdMux.Lock()
+	defer d.holdMux.Unlock()
+	return len(d.held)
+}
+
+// HoldEvents returns the channel of the events of the held messages, see HoldPolicy.
+func (d *Device) HoldEvents() <-chan HoldEvent {
+	return d.holdEvents
+}
@@ -0,0 +1,49 @@
+package at
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHold(t *testing.T) {
+	t.Parallel()
+
+	opts := SendOptions{CorrelationID: "held"}
+	msg := opts.Message("hello", "+79269965690")
+	n, octets, err := msg.PDU()
+	require.NoError(t, err)
+	dev, _ := newTestDevice(t, map[string]string{
+		fmt.Sprintf("AT+CMGS=%d", n):      "> ",
+		fmt.Sprintf("%02X", octets) + Sub: "+CMGS: 7" + Sep + Sep + "OK",
+	})
+	dev.Hold = &HoldPolicy{Limit: 2, TTL: 50 * time.Millisecond}
+	dev.State = &DeviceState{ServiceState: ServiceStates.None}
+
+	assert.ErrorIs(t, dev.SendSMS("expiring", "+79269965690"), ErrHeld)
+	assert.Equal(t, HoldEvents.Queued, (<-dev.HoldEvents()).Event)
+	event := <-dev.HoldEvents()
+	assert.Equal(t, HoldEvents.Expired, event.Event)
+	assert.NotEmpty(t, event.CorrelationID)
+
+	dev.Hold.TTL = 0
+	assert.ErrorIs(t, dev.SendSMS("hello", "+79269965690", opts), ErrHeld)
+	assert.ErrorIs(t, dev.SendSMS("hello", "+79269965690", opts), ErrHeld)
+	assert.ErrorIs(t, dev.SendSMS("hello", "+79269965690", opts), ErrHoldFull)
+	assert.Equal(t, 2, dev.HeldMessages())
+	<-dev.HoldEvents()
+	<-dev.HoldEvents()
+
+	require.NoError(t, dev.handleReport("^SRVST: 2"))
+	for i := 0; i < 2; i++ {
+		event := <-dev.HoldEvents()
+		assert.Equal(t, HoldEvents.Flushed, event.Event)
+		assert.Equal(t, "held", event.CorrelationID)
+		assert.NoError(t, event.Err)
+	}
+	assert.Zero(t, dev.HeldMessages())
+	assert.NoError(t, dev.SendSMS("hello", "+79269965690", opts))
+}
@@ -0,0 +1,41 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"syscall"
+)
+
+// DeviceLost is emitted when a port of the device disappears, i.e. a USB modem was unplugged.
+// The device must be closed and discovered again, unless the Watchdog manages to reopen it.
+type DeviceLost struct {
+	// Err is the error returned by the port.
+	Err error
+}
+
+// Lost fires when the device's port disappears, all the commands fail with ErrDeviceLost after that.
+func (d *Device) Lost() <-chan DeviceLost {
+	return d.lostEvents
+}
+
+// isDeviceLost reports whether the port error means that the device is gone.
+func isDeviceLost(err error) bool {
+	return errors.Is(err, syscall.EIO) || errors.Is(err, syscall.ENODEV) || errors.Is(err, syscall.ENXIO)
+}
+
+// deviceLost marks the device as lost and emits the event once. Returns the error wrapped
+// with ErrDeviceLost if the port has gone, otherwise the passed error as is.
+func (d *Device) deviceLost(err error) error {
+	if !isDeviceLost(err) {
+		return err
+	}
+	if atomic.CompareAndSwapInt32(&d.lost, 0, 1) {
+		d.transition(LifecycleStates.Degraded, err)
+		select {
+		case d.lostEvents <- DeviceLost{Err: err}:
+		default:
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrDeviceLost, err)
+}
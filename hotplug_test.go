@@ -0,0 +1,29 @@
+package at
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceLost(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, nil)
+	assert.NotErrorIs(t, dev.portError(os.ErrDeadlineExceeded), ErrDeviceLost)
+	assert.Len(t, dev.Lost(), 0)
+
+	err := dev.portError(&os.PathError{Op: "read", Path: "/dev/ttyUSB0", Err: syscall.EIO})
+	assert.ErrorIs(t, err, ErrDeviceLost)
+	require.Len(t, dev.Lost(), 1)
+	event := <-dev.Lost()
+	assert.ErrorIs(t, event.Err, syscall.EIO)
+
+	_, err = dev.Send(NoopCmd)
+	assert.ErrorIs(t, err, ErrDeviceLost)
+	dev.portError(syscall.ENODEV)
+	assert.Len(t, dev.Lost(), 0, "the event is emitted once")
+}
@@ -0,0 +1,145 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// ErrNotStored is returned by MarkProcessed when the message was not saved to the MessageStore.
+var ErrNotStored = errors.New("at: the message is not stored")
+
+// PersistedMessage is an incoming message saved by a MessageStore.
+type PersistedMessage struct {
+	ID       string
+	Message  sms.Message
+	Received time.Time
+}
+
+// MessageStore persists the incoming messages before they are delivered to the IncomingSms
+// channel, so they aren't lost if the consumer crashes after the messages are deleted from
+// the modem. List returns the messages that are not marked as processed yet.
+type MessageStore interface {
+	Save(msg PersistedMessage) error
+	List() ([]PersistedMessage, error)
+	MarkProcessed(id string) error
+}
+
+// persist saves the message to the MessageStore, if set, and remembers its ID.
+func (d *Device) persist(msg *sms.Message) error {
+	if d.MessageStore == nil {
+		return nil
+	}
+	stored := PersistedMessage{ID: RandomID(), Message: *msg, Received: time.Now()}
+	if err := d.MessageStore.Save(stored); err != nil {
+		return fmt.Errorf("at: unable to store the message: %w", err)
+	}
+	d.persistedMux.Lock()
+	defer d.persistedMux.Unlock()
+	if d.persisted == nil {
+		d.persisted = make(map[*sms.Message]string)
+	}
+	d.persisted[msg] = stored.ID
+	return nil
+}
+
+// MarkProcessed marks the message received from the IncomingSms channel as processed
+// in the MessageStore, so it's not redelivered. It's a no-op if the store is not set.
+func (d *Device) MarkProcessed(msg *sms.Message) error {
+	if d.MessageStore == nil {
+		return nil
+	}
+	d.persistedMux.Lock()
+	id, ok := d.persisted[msg]
+	delete(d.persisted, msg)
+	d.persistedMux.Unlock()
+	if !ok {
+		return ErrNotStored
+	}
+	return d.MessageStore.MarkProcessed(id)
+}
+
+// Redeliver delivers the messages left unprocessed in the MessageStore to the IncomingSms
+// channel again, i.e. after a restart of the application. They are classified again and
+// the stored parts of a concatenated message wait for the rest.
+func (d *Device) Redeliver(ctx context.Context) error {
+	if d.MessageStore == nil {
+		return nil
+	}
+	list, err := d.MessageStore.List()
+	if err != nil {
+		return fmt.Errorf("at: unable to list the stored messages: %w", err)
+	}
+	for _, stored := range list {
+		msg := stored.Message
+		d.persistedMux.Lock()
+		if d.persisted == nil {
+			d.persisted = make(map[*sms.Message]string)
+		}
+		d.persisted[&msg] = stored.ID
+		d.persistedMux.Unlock()
+		if d.partOf(&msg) {
+			err = d.reassembled(ctx, &msg)
+		} else {
+			err = d.deliver(ctx, &msg, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MessageQuery selects the persisted messages, i.e. for a search in a MessageStore
+// that archives them. The zero fields match any message.
+type MessageQuery struct {
+	Sender sms.PhoneNumber
+	// Text is matched as a case-insensitive substring of the text.
+	Text  string
+	Since time.Time
+	Until time.Time
+}
+
+// Match reports whether the message is selected by the query.
+func (q MessageQuery) Match(msg PersistedMessage) bool {
+	switch {
+	case q.Sender != "" && msg.Message.Address != q.Sender:
+		return false
+	case q.Text != "" && !strings.Contains(strings.ToLower(msg.Message.Text), strings.ToLower(q.Text)):
+		return false
+	case !q.Since.IsZero() && msg.Received.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !msg.Received.Before(q.Until):
+		return false
+	}
+	return true
+}
+
+// RetentionPolicy limits the messages kept by a MessageStore that archives them.
+type RetentionPolicy struct {
+	// MaxAge is the age after which a message is pruned, unlimited if zero.
+	MaxAge time.Duration
+	// MaxMessages is the number of the newest messages kept, unlimited if zero.
+	MaxMessages int
+}
+
+// Prune returns the messages to be removed from the list according to the policy.
+func (p RetentionPolicy) Prune(list []PersistedMessage, now time.Time) []PersistedMessage {
+	sorted := append([]PersistedMessage(nil), list...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Received.After(sorted[j].Received)
+	})
+	var pruned []PersistedMessage
+	for i, msg := range sorted {
+		if (p.MaxMessages > 0 && i >= p.MaxMessages) ||
+			(p.MaxAge > 0 && now.Sub(msg.Received) > p.MaxAge) {
+			pruned = append(pruned, msg)
+		}
+	}
+	return pruned
+}
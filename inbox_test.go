@@ -0,0 +1,127 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xlab/at/sms"
+)
+
+type memoryMessageStore struct {
+	mux       sync.Mutex
+	messages  []PersistedMessage
+	processed map[string]bool
+}
+
+func (s *memoryMessageStore) Save(msg PersistedMessage) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func (s *memoryMessageStore) List() (list []PersistedMessage, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, msg := range s.messages {
+		if !s.processed[msg.ID] {
+			list = append(list, msg)
+		}
+	}
+	return list, nil
+}
+
+func (s *memoryMessageStore) MarkProcessed(id string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.processed[id] = true
+	return nil
+}
+
+func TestMessageStore(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	store := &memoryMessageStore{processed: make(map[string]bool)}
+	dev, _ := newTestDevice(t, nil)
+	dev.MessageStore = store
+
+	require.NoError(t, dev.handleReport("+CMT: ,25\n"+pdu))
+	require.NoError(t, dev.handleReport("+CMT: ,25\n"+pdu))
+	first, second := <-dev.IncomingSms(), <-dev.IncomingSms()
+	require.NoError(t, dev.MarkProcessed(first))
+	assert.ErrorIs(t, dev.MarkProcessed(first), ErrNotStored)
+	list, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, *second, list[0].Message)
+
+	// the consumer has restarted
+	require.NoError(t, dev.Redeliver(context.Background()))
+	msg := <-dev.IncomingSms()
+	assert.Equal(t, second.Address, msg.Address)
+	require.NoError(t, dev.MarkProcessed(msg))
+	list, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestMessageRetention(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	list := []PersistedMessage{
+		{ID: "1", Message: sms.Message{Address: "+79269965690", Text: "Your code is 1234"}, Received: now.Add(-48 * time.Hour)},
+		{ID: "2", Message: sms.Message{Address: "+79261234567", Text: "Hello"}, Received: now.Add(-2 * time.Hour)},
+		{ID: "3", Message: sms.Message{Address: "+79269965690", Text: "Your CODE is 5678"}, Received: now.Add(-time.Hour)},
+	}
+	var found []string
+	query := MessageQuery{Sender: "+79269965690", Text: "code", Since: now.Add(-24 * time.Hour)}
+	for _, msg := range list {
+		if query.Match(msg) {
+			found = append(found, msg.ID)
+		}
+	}
+	assert.Equal(t, []string{"3"}, found)
+	assert.False(t, MessageQuery{Until: now.Add(-time.Hour)}.Match(list[2]))
+
+	pruned := RetentionPolicy{MaxAge: 24 * time.Hour}.Prune(list, now)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, "1", pruned[0].ID)
+
+	pruned = RetentionPolicy{MaxMessages: 1}.Prune(list, now)
+	require.Len(t, pruned, 2)
+	assert.Equal(t, "2", pruned[0].ID)
+	assert.Equal(t, "1", pruned[1].ID)
+}
+
+var errStoreDown = errors.New("the store is down")
+
+type failingMessageStore struct {
+	memoryMessageStore
+}
+
+func (s *failingMessageStore) Save(msg PersistedMessage) error {
+	return errStoreDown
+}
+
+func TestMessageStoreFailure(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGR=1": "+CMGR: 0,,25" + Sep + pdu + Sep + Sep + "OK",
+		// AT+CMGD would fail
+	})
+	dev.MessageStore = &failingMessageStore{}
+
+	// the message is not deleted from the storage if it can't be stored
+	assert.ErrorIs(t, dev.handleReport(`+CMTI: "SM",1`), errStoreDown)
+	assert.Empty(t, dev.IncomingSms())
+}
@@ -0,0 +1,49 @@
+package at
+
+import "time"
+
+// DefaultIndicationDelay is the delay of the batch fetch of the indicated messages.
+const DefaultIndicationDelay = 5 * time.Second
+
+// Indications fires when a new message is indicated and the IndicationPolicy is
+// IndicationPolicies.Notify, the application fetches the message itself then.
+// The indications are dropped if the channel is not consumed, the messages stay
+// in the storage until the next fetch.
+func (d *Device) Indications() <-chan MessageIndication {
+	return d.indications
+}
+
+// deferIndication applies the IndicationPolicy to the indicated message, it returns
+// false if the message should be fetched immediately.
+func (d *Device) deferIndication(indication MessageIndication) bool {
+	switch d.IndicationPolicy {
+	case IndicationPolicies.Notify:
+		select {
+		case d.indications <- indication:
+		default:
+		}
+		return true
+	case IndicationPolicies.Batch:
+		d.batchMux.Lock()
+		defer d.batchMux.Unlock()
+		if d.batch == nil {
+			// the messages indicated meanwhile are fetched together
+			delay := d.IndicationDelay
+			if delay <= 0 {
+				delay = DefaultIndicationDelay
+			}
+			d.batch = time.AfterFunc(delay, d.fetchBatch)
+		}
+		return true
+	}
+	return false
+}
+
+// fetchBatch fetches the inbox, the errors are ignored since the messages stay
+// in the storage until the next fetch.
+func (d *Device) fetchBatch() {
+	d.batchMux.Lock()
+	d.batch = nil
+	d.batchMux.Unlock()
+	d.Commands.FetchInbox()
+}
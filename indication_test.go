@@ -0,0 +1,61 @@
+package at
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageIndication(t *testing.T) {
+	t.Parallel()
+
+	var indication MessageIndication
+	require.NoError(t, indication.Parse(`+CMTI: "SM", 3`))
+	assert.Equal(t, MessageIndication{Memory: MemoryTypes.Sim, Index: 3}, indication)
+	require.NoError(t, indication.Parse(`"ME",12`))
+	assert.Equal(t, MessageIndication{Memory: MemoryTypes.NvRAM, Index: 12}, indication)
+	assert.ErrorIs(t, indication.Parse(`"XX",1`), ErrParseReport)
+}
+
+func TestIndicationPolicy(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, urc := newTestDevice(t, map[string]string{
+		"AT+CPMS?":    `+CPMS: "SM",2,5,"SM",2,5,"SM",2,5` + Sep + Sep + "OK",
+		"AT+CMGR=1":   "+CMGR: 0,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGR=2":   "+CMGR: 0,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGD=1,0": "OK",
+		"AT+CMGD=2,0": "OK",
+	})
+	dev.IndicationPolicy = IndicationPolicies.Notify
+	go dev.Watch()
+
+	io.WriteString(urc, Sep+`+CMTI: "SM",1`+Sep)
+	select {
+	case indication := <-dev.Indications():
+		assert.EqualValues(t, 1, indication.Index)
+	case <-time.After(time.Second):
+		t.Fatal("the message was not indicated")
+	}
+	for i := 0; i <= cap(dev.indications); i++ {
+		assert.True(t, dev.deferIndication(MessageIndication{Index: 1}), "the full channel should not block")
+	}
+	for len(dev.indications) > 0 {
+		<-dev.indications
+	}
+
+	dev.IndicationPolicy = IndicationPolicies.Batch
+	dev.IndicationDelay = 10 * time.Millisecond
+	io.WriteString(urc, Sep+`+CMTI: "SM",1`+Sep+`+CMTI: "SM",2`+Sep)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-dev.IncomingSms():
+		case <-time.After(time.Second):
+			t.Fatal("the batch was not fetched")
+		}
+	}
+}
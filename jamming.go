@@ -0,0 +1,86 @@
+package at
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JammingConfig configures the jamming detection of the modem.
+type JammingConfig struct {
+	// Enable turns the detection and its reports on.
+	Enable bool
+	// Params are the vendor-specific detection parameters (i.e. the RSSI thresholds)
+	// appended to the command as is, the modem defaults are used if empty.
+	Params []int
+}
+
+// Jamming is reported when the modem detects the radio jamming or when it stops.
+type Jamming struct {
+	// Jammed is true while the jamming is detected.
+	Jammed bool
+	// Time when the report has been received.
+	Time time.Time
+}
+
+// jammingDetector is implemented by profiles that are able to detect the radio jamming.
+type jammingDetector interface {
+	JDC(cfg JammingConfig) (err error)
+}
+
+// JDC sends AT^JDC to the device, that configures the jamming detection of Huawei modules.
+// The changes of the jamming state are reported with ^JDC.
+func (p *DefaultProfile) JDC(cfg JammingConfig) (err error) {
+	_, err = p.dev.Send(jammingCommand(`AT^JDC`, cfg))
+	return
+}
+
+// jammingCommand formats the command with the enable flag and the parameters.
+func jammingCommand(cmd string, cfg JammingConfig) string {
+	args := []string{"0"}
+	if cfg.Enable {
+		args[0] = "1"
+	}
+	for _, param := range cfg.Params {
+		args = append(args, strconv.Itoa(param))
+	}
+	return cmd + "=" + strings.Join(args, ",")
+}
+
+// DetectJamming configures the jamming detection, the changes are reported
+// to the JammingDetected channel.
+func (d *Device) DetectJamming(cfg JammingConfig) error {
+	p, ok := d.Commands.(jammingDetector)
+	if !ok || !d.Supports(Features.Jamming) {
+		return ErrUnsupported
+	}
+	if err := p.JDC(cfg); err != nil {
+		return fmt.Errorf("at: unable to configure the jamming detection: %w", err)
+	}
+	return nil
+}
+
+// JammingDetected fires when the modem detects the radio jamming or when it stops,
+// see DetectJamming. The reports are dropped if the channel is not consumed.
+func (d *Device) JammingDetected() <-chan Jamming {
+	return d.jamming
+}
+
+// jammingReport represents the jamming state of ^JDC (Huawei) or +QJDR (Quectel):
+// a number (0 or 1) or a string like "JAMMED" and "NO JAMMING".
+type jammingReport bool
+
+func (j *jammingReport) Parse(str string) error {
+	fields := strings.Split(str, ",")
+	state := strings.ToUpper(strings.Trim(strings.TrimSpace(fields[0]), `"`))
+	switch state {
+	case "1", "JAMMED", "JAMMING":
+		*j = true
+	case "0", "NO JAMMING", "NOJAMMING", "DETECTING":
+		*j = false
+	default:
+		return ErrParseReport
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJammingReport(t *testing.T) {
+	t.Parallel()
+
+	for str, jammed := range map[string]bool{
+		"1":              true,
+		`"JAMMED"`:       true,
+		"0,2":            false,
+		`"NO JAMMING",0`: false,
+	} {
+		var report jammingReport
+		require.NoError(t, report.Parse(str), str)
+		assert.Equal(t, jammed, bool(report), str)
+	}
+	var report jammingReport
+	assert.ErrorIs(t, report.Parse("maybe"), ErrParseReport)
+}
+
+func TestDetectJamming(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT^JDC=1,-95,10": "OK",
+	})
+	require.NoError(t, dev.DetectJamming(JammingConfig{Enable: true, Params: []int{-95, 10}}))
+	assert.Error(t, dev.DetectJamming(JammingConfig{}), "AT^JDC=0 would fail")
+
+	require.NoError(t, dev.handleReport("^JDC: 1"))
+	require.NoError(t, dev.handleReport(`+QJDR: "NO JAMMING"`))
+	assert.True(t, (<-dev.JammingDetected()).Jammed)
+	assert.False(t, (<-dev.JammingDetected()).Jammed)
+}
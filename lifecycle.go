@@ -0,0 +1,89 @@
+package at
+
+import "time"
+
+var lifecycleStates = stringOpts{
+	{"closed", "The ports are closed"},
+	{"opening", "The ports are being opened"},
+	{"initializing", "The profile's Init is running"},
+	{"ready", "Initialized and responsive"},
+	{"degraded", "The device is lost, failing or its Init has failed"},
+	{"reconnecting", "The Watchdog is reopening the ports"},
+	{"closing", "The ports are being closed"},
+}
+
+// LifecycleStates represent the states of a Device during its lifetime:
+//
+//	Closed -> Opening -> Initializing -> Ready -> Closing -> Closed
+//	Ready -> Degraded -> Reconnecting -> Initializing -> Ready
+//
+// The Degraded device may also return to Ready once an operation succeeds.
+var LifecycleStates = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	Closed       StringOpt
+	Opening      StringOpt
+	Initializing StringOpt
+	Ready        StringOpt
+	Degraded     StringOpt
+	Reconnecting StringOpt
+	Closing      StringOpt
+}{
+	func(str string) StringOpt { return lifecycleStates.Resolve(str) },
+	lifecycleStatesValues,
+
+	lifecycleStates[0], lifecycleStates[1], lifecycleStates[2], lifecycleStates[3],
+	lifecycleStates[4], lifecycleStates[5], lifecycleStates[6],
+}
+
+// LifecycleEvent is reported on each transition between the LifecycleStates.
+type LifecycleEvent struct {
+	From StringOpt
+	To   StringOpt
+	// Err is the cause of the transition to Degraded or of the failed Open, if any.
+	Err  error
+	Time time.Time
+}
+
+// Lifecycle returns the current state of the device, see LifecycleStates.
+func (d *Device) Lifecycle() StringOpt {
+	d.lifecycleMux.Lock()
+	defer d.lifecycleMux.Unlock()
+	if d.lifecycle == (StringOpt{}) {
+		return LifecycleStates.Closed
+	}
+	return d.lifecycle
+}
+
+// LifecycleEvents returns the channel of the transitions between the states of the device.
+// The channel is available before Open, so the transitions of the opening are reported too.
+func (d *Device) LifecycleEvents() <-chan LifecycleEvent {
+	d.lifecycleMux.Lock()
+	defer d.lifecycleMux.Unlock()
+	if d.lifecycleEvents == nil {
+		d.lifecycleEvents = make(chan LifecycleEvent, 100)
+	}
+	return d.lifecycleEvents
+}
+
+// transition changes the state of the device and reports the change, if any.
+func (d *Device) transition(to StringOpt, err error) {
+	d.lifecycleMux.Lock()
+	defer d.lifecycleMux.Unlock()
+	from := d.lifecycle
+	if from == (StringOpt{}) {
+		from = LifecycleStates.Closed
+	}
+	if from == to {
+		return
+	}
+	d.lifecycle = to
+	if d.lifecycleEvents == nil {
+		d.lifecycleEvents = make(chan LifecycleEvent, 100)
+	}
+	select {
+	case d.lifecycleEvents <- LifecycleEvent{From: from, To: to, Err: err, Time: time.Now()}:
+	default:
+	}
+}
@@ -0,0 +1,47 @@
+package at
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle(t *testing.T) {
+	t.Parallel()
+
+	td := &testDialer{}
+	dev := &Device{
+		CommandPort: "cmd",
+		NotifyPort:  "notify",
+		Dial:        td.Dial,
+		Timeout:     time.Second,
+		Watchdog:    &Watchdog{RetryInterval: time.Millisecond},
+	}
+	assert.Equal(t, LifecycleStates.Closed, dev.Lifecycle())
+	events := dev.LifecycleEvents()
+	expect := func(states ...StringOpt) {
+		t.Helper()
+		for _, state := range states {
+			select {
+			case event := <-events:
+				assert.Equal(t, state, event.To)
+			case <-time.After(time.Second):
+				t.Fatalf("transition to %s was not reported", state.ID)
+			}
+		}
+	}
+
+	require.NoError(t, dev.Open())
+	require.NoError(t, dev.Init(&noopProfile{}))
+	expect(LifecycleStates.Opening, LifecycleStates.Initializing, LifecycleStates.Ready)
+	assert.Equal(t, LifecycleStates.Ready, dev.Lifecycle())
+	go dev.Watch()
+
+	td.urc(0).Close()
+	expect(LifecycleStates.Degraded, LifecycleStates.Reconnecting, LifecycleStates.Initializing, LifecycleStates.Ready)
+
+	require.NoError(t, dev.Close())
+	expect(LifecycleStates.Closing, LifecycleStates.Closed)
+}
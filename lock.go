@@ -0,0 +1,138 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cardLockReport is the reply to AT^CARDLOCK? (Huawei): ^CARDLOCK: <status>,<times>,<operator>.
+type cardLockReport struct {
+	State    Opt
+	Attempts int
+}
+
+func (c *cardLockReport) Parse(str string) error {
+	fields := strings.Split(strings.TrimSpace(strings.TrimPrefix(str, "^CARDLOCK:")), ",")
+	if len(fields) < 2 {
+		return ErrParseReport
+	}
+	status, err := parseUint8(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return err
+	}
+	switch status {
+	case 1:
+		c.State = LockStates.Locked
+	case 2:
+		c.State = LockStates.Unlocked
+	case 3:
+		c.State = LockStates.Permanently
+	default:
+		return ErrParseReport
+	}
+	attempts, err := parseUint8(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return err
+	}
+	c.Attempts = int(attempts)
+	return nil
+}
+
+// CarrierLock gets the network personalization (SIMLOCK) state of the modem and the number
+// of remaining attempts to enter the unlock code. AT^CARDLOCK? is tried first (Huawei),
+// then AT+CLCK="PN",2 which doesn't tell the attempts, so -1 is returned instead.
+func (p *DefaultProfile) CarrierLock() (state Opt, attempts int, err error) {
+	if reply, err := p.dev.Send(`AT^CARDLOCK?`); err == nil {
+		var report cardLockReport
+		if err = report.Parse(reply); err != nil {
+			return UnknownOpt, -1, err
+		}
+		return report.State, report.Attempts, nil
+	}
+	return p.networkLock()
+}
+
+// networkLock gets the network personalization state with the standard AT+CLCK="PN",2,
+// the attempts are not reported, so -1 is returned instead.
+func (p *DefaultProfile) networkLock() (state Opt, attempts int, err error) {
+	reply, err := p.dev.Send(`AT+CLCK="PN",2`)
+	if err != nil {
+		return UnknownOpt, -1, err
+	}
+	status, err := parseUint8(strings.TrimSpace(strings.TrimPrefix(reply, "+CLCK:")))
+	if err != nil {
+		return UnknownOpt, -1, err
+	}
+	if status == 1 {
+		return LockStates.Locked, -1, nil
+	}
+	return LockStates.Unlocked, -1, nil
+}
+
+// UnlockInterval is the minimum period between the unlock attempts of a device,
+// the modems tend to lock permanently after a series of wrong codes.
+var UnlockInterval = 30 * time.Second
+
+// Unlock errors.
+var (
+	ErrUnlockCode        = errors.New("at: the unlock code must contain only digits")
+	ErrUnlockRateLimited = errors.New("at: too frequent unlock attempts")
+	ErrNoUnlockAttempts  = errors.New("at: no unlock attempts left")
+)
+
+// carrierLocker is implemented by profiles that are able to manage the carrier lock.
+type carrierLocker interface {
+	CarrierLock() (state Opt, attempts int, err error)
+	CardUnlock(code string) (err error)
+}
+
+// CardUnlock sends AT^CARDLOCK with the given network unlock code (NCK) to the device (Huawei).
+func (p *DefaultProfile) CardUnlock(code string) (err error) {
+	req := fmt.Sprintf(`AT^CARDLOCK="%s"`, code)
+	_, err = p.dev.Send(req)
+	return
+}
+
+// Unlock enters the network unlock code and refreshes the lock state of the device.
+// The attempts are limited by UnlockInterval, the code is not sent if no attempts are left
+// or the modem is locked permanently. Returns the error if the code was rejected.
+func (d *Device) Unlock(code string) error {
+	p, ok := d.Commands.(carrierLocker)
+	if !ok {
+		return ErrUnsupported
+	}
+	if len(code) == 0 {
+		return ErrUnlockCode
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return ErrUnlockCode
+		}
+	}
+
+	d.unlockMux.Lock()
+	defer d.unlockMux.Unlock()
+	if !d.lastUnlock.IsZero() && time.Since(d.lastUnlock) < UnlockInterval {
+		return ErrUnlockRateLimited
+	}
+	state, attempts, err := p.CarrierLock()
+	if err != nil {
+		return fmt.Errorf("at: unable to read the lock state: %w", err)
+	}
+	switch {
+	case state == LockStates.Unlocked:
+		return nil
+	case state == LockStates.Permanently, attempts == 0:
+		return ErrNoUnlockAttempts
+	}
+
+	d.lastUnlock = time.Now()
+	unlockErr := p.CardUnlock(code)
+	if state, attempts, err = p.CarrierLock(); err == nil && d.State != nil {
+		d.State.CarrierLock = state
+		d.State.UnlockAttempts = attempts
+	}
+	return unlockErr
+}
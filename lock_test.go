@@ -0,0 +1,44 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardLockReport(t *testing.T) {
+	t.Parallel()
+
+	var report cardLockReport
+	require.NoError(t, report.Parse("^CARDLOCK: 1,10,0"))
+	assert.Equal(t, LockStates.Locked, report.State)
+	assert.Equal(t, 10, report.Attempts)
+	require.NoError(t, report.Parse("^CARDLOCK: 2,10,0"))
+	assert.Equal(t, LockStates.Unlocked, report.State)
+	assert.Error(t, report.Parse("^CARDLOCK: 5"))
+}
+
+func TestCarrierLock(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		`AT+CLCK="PN",2`: "+CLCK: 1" + Sep + Sep + "OK",
+	})
+	state, attempts, err := dev.Commands.(*noopProfile).CarrierLock()
+	require.NoError(t, err)
+	assert.Equal(t, LockStates.Locked, state)
+	assert.Equal(t, -1, attempts)
+}
+
+func TestUnlock(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		`AT^CARDLOCK?`:          "^CARDLOCK: 1,9,0" + Sep + Sep + "OK",
+		`AT^CARDLOCK="1234567"`: "OK",
+	})
+	assert.ErrorIs(t, dev.Unlock("12a"), ErrUnlockCode)
+	assert.NoError(t, dev.Unlock("1234567"))
+	assert.ErrorIs(t, dev.Unlock("1234567"), ErrUnlockRateLimited)
+}
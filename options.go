@@ -0,0 +1,174 @@
+package at
+
+import "fmt"
+
+// InitOption tweaks the Init sequence of a profile derived from the DefaultProfile.
+type InitOption func(p *DefaultProfile)
+
+// WithStorage selects the message storage instead of picking it automatically.
+func WithStorage(mem StringOpt) InitOption {
+	return func(p *DefaultProfile) {
+		p.Storage = mem
+	}
+}
+
+// WithCNMI sets the only AT+CNMI settings to apply on Init.
+func WithCNMI(mode, mt, bm, ds, bfr int) InitOption {
+	return func(p *DefaultProfile) {
+		p.NotificationConfigs = []CNMIConfig{{Mode: mode, MT: mt, BM: bm, DS: ds, BFR: bfr}}
+	}
+}
+
+// WithTextMode switches the message format to the text mode, see DefaultProfile.TextMode.
+func WithTextMode() InitOption {
+	return func(p *DefaultProfile) {
+		p.TextMode = true
+	}
+}
+
+// WithoutCLIP skips turning on the caller ID notifications.
+func WithoutCLIP() InitOption {
+	return func(p *DefaultProfile) {
+		p.DisableCallerID = true
+	}
+}
+
+// WithoutBootHandshake leaves replying to the ^BOOT reports to the application.
+func WithoutBootHandshake() InitOption {
+	return func(p *DefaultProfile) {
+		p.DisableBootHandshake = true
+	}
+}
+
+// WithoutCNMA skips acknowledging the messages routed directly to the terminal.
+func WithoutCNMA() InitOption {
+	return func(p *DefaultProfile) {
+		p.DisableMessageAck = true
+	}
+}
+
+// WithoutCSMS skips the negotiation of the message service, see DefaultProfile.DisableCSMS.
+func WithoutCSMS() InitOption {
+	return func(p *DefaultProfile) {
+		p.DisableCSMS = true
+	}
+}
+
+// WithIMS routes the messages received over IMS directly to the terminal, see DefaultProfile.IMS.
+func WithIMS() InitOption {
+	return func(p *DefaultProfile) {
+		p.IMS = true
+	}
+}
+
+// WithInboxPolicy sets what happens to the stored messages after they were fetched.
+func WithInboxPolicy(policy StringOpt) InitOption {
+	return func(p *DefaultProfile) {
+		p.InboxPolicy = policy
+	}
+}
+
+// WithMessageListParser overrides the parsing of the AT+CMGL replies.
+func WithMessageListParser(parser MessageListParser) InitOption {
+	return func(p *DefaultProfile) {
+		p.MessageListParser = parser
+	}
+}
+
+// WithTerminators registers the non-standard final responses of the firmware.
+func WithTerminators(terminators ...Terminator) InitOption {
+	return func(p *DefaultProfile) {
+		p.Terminators = append(p.Terminators, terminators...)
+	}
+}
+
+// WithExtraInitCommands appends the commands that are sent at the end of Init.
+func WithExtraInitCommands(cmds ...string) InitOption {
+	return func(p *DefaultProfile) {
+		p.InitCommands = append(p.InitCommands, cmds...)
+	}
+}
+
+// InitHook is invoked by Device.Init before or after the profile's Init, i.e. to send
+// the vendor-specific setup commands.
+type InitHook func(d *Device) error
+
+// WithBeforeInit appends the hook that is invoked before the profile's Init,
+// the command echo may be still on at that point.
+func WithBeforeInit(hook InitHook) InitOption {
+	return func(p *DefaultProfile) {
+		p.BeforeInit = append(p.BeforeInit, hook)
+	}
+}
+
+// WithAfterInit appends the hook that is invoked after the profile's Init has succeeded,
+// including the Init steps of the profiles embedding the DefaultProfile.
+func WithAfterInit(hook InitHook) InitOption {
+	return func(p *DefaultProfile) {
+		p.AfterInit = append(p.AfterInit, hook)
+	}
+}
+
+// initHooker is implemented by profiles that have the Init hooks.
+type initHooker interface {
+	initHooks() (before, after []InitHook)
+}
+
+func (p *DefaultProfile) initHooks() (before, after []InitHook) {
+	return p.BeforeInit, p.AfterInit
+}
+
+// initProfile invokes the profile's Init with its hooks.
+func (d *Device) initProfile(profile DeviceProfile) (err error) {
+	d.transition(LifecycleStates.Initializing, nil)
+	defer func() {
+		if err != nil {
+			d.transition(LifecycleStates.Degraded, err)
+		} else {
+			d.transition(LifecycleStates.Ready, nil)
+		}
+	}()
+	d.terminators = nil
+	if t, ok := profile.(terminatorProvider); ok {
+		d.terminators = t.terminators()
+	}
+	h, ok := profile.(initHooker)
+	if !ok {
+		return profile.Init(d)
+	}
+	before, after := h.initHooks()
+	for _, hook := range before {
+		if err := hook(d); err != nil {
+			return fmt.Errorf("at init: before init hook: %w", err)
+		}
+	}
+	if err := profile.Init(d); err != nil {
+		return err
+	}
+	for _, hook := range after {
+		if err := hook(d); err != nil {
+			return fmt.Errorf("at init: after init hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// Apply applies the options to the profile.
+func (p *DefaultProfile) Apply(opts ...InitOption) {
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+// WithOptions applies the options to the profile derived from the DefaultProfile
+// and returns it, i.e.
+//
+//	dev.Init(WithOptions(DeviceE173(), WithStorage(MemoryTypes.Sim), WithoutCLIP()))
+//
+// The options are ignored if the profile doesn't embed the DefaultProfile.
+func WithOptions(profile DeviceProfile, opts ...InitOption) DeviceProfile {
+	if p, ok := profile.(interface{ Apply(...InitOption) }); ok {
+		p.Apply(opts...)
+	}
+	return profile
+}
@@ -0,0 +1,82 @@
+package at
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitOptions(t *testing.T) {
+	t.Parallel()
+
+	profile := WithOptions(DeviceGeneric(),
+		WithStorage(MemoryTypes.Sim),
+		WithCNMI(2, 2, 0, 1, 0),
+		WithoutCLIP(),
+		WithExtraInitCommands("AT+CMEE=2"),
+	)
+	dev, _ := newProfileDevice(t, profile, map[string]string{
+		"AT+COPS=0,0":            "OK",
+		"AT+CREG?":               "+CREG: 0,1" + Sep + Sep + "OK",
+		"AT+CPIN?":               "+CPIN: READY" + Sep + Sep + "OK",
+		"AT+COPS?":               `+COPS: 0,0,"MTS",2` + Sep + Sep + "OK",
+		"AT+GMM":                 "Unknown" + Sep + Sep + "OK",
+		"AT+GSN":                 "351234567890123" + Sep + Sep + "OK",
+		"AT+CMGF=0":              "OK",
+		`AT+CPMS="SM","SM","SM"`: "+CPMS: 0,30,0,30,0,30" + Sep + Sep + "OK",
+		"AT+CNMI=2,2,0,1,0":      "OK",
+		"AT+CNMI?":               "+CNMI: 2,2,0,1,0" + Sep + Sep + "OK",
+		"AT+CMEE=2":              "OK",
+		"AT+CPMS?":               `+CPMS: "SM",0,20,"SM",0,20,"SM",0,20` + Sep + Sep + "OK",
+		"AT+CSQ":                 "+CSQ: 17,99" + Sep + Sep + "OK",
+		// AT+CLIP=1 and AT+CPMS=? would fail
+	})
+
+	assert.Equal(t, MemoryTypes.Sim, dev.State.MessageStorage)
+}
+
+func TestInitHooks(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	profile := WithOptions(DeviceGeneric(),
+		WithStorage(MemoryTypes.Sim),
+		WithCNMI(2, 2, 0, 1, 0),
+		WithoutCLIP(),
+		WithBeforeInit(func(d *Device) error {
+			calls = append(calls, "before")
+			assert.Nil(t, d.State)
+			_, err := d.Send(`AT+QURCCFG="urcport","uart1"`)
+			return err
+		}),
+		WithAfterInit(func(d *Device) error {
+			calls = append(calls, "after")
+			assert.Equal(t, "MTS", d.State.OperatorName)
+			return nil
+		}),
+	)
+	dev, _ := newProfileDevice(t, profile, map[string]string{
+		`AT+QURCCFG="urcport","uart1"`: "OK",
+		"AT+COPS=0,0":                  "OK",
+		"AT+CREG?":                     "+CREG: 0,1" + Sep + Sep + "OK",
+		"AT+CPIN?":                     "+CPIN: READY" + Sep + Sep + "OK",
+		"AT+COPS?":                     `+COPS: 0,0,"MTS",2` + Sep + Sep + "OK",
+		"AT+GMM":                       "Unknown" + Sep + Sep + "OK",
+		"AT+GSN":                       "351234567890123" + Sep + Sep + "OK",
+		"AT+CMGF=0":                    "OK",
+		`AT+CPMS="SM","SM","SM"`:       "+CPMS: 0,30,0,30,0,30" + Sep + Sep + "OK",
+		"AT+CNMI=2,2,0,1,0":            "OK",
+		"AT+CNMI?":                     "+CNMI: 2,2,0,1,0" + Sep + Sep + "OK",
+		"AT+CPMS?":                     `+CPMS: "SM",0,20,"SM",0,20,"SM",0,20` + Sep + Sep + "OK",
+		"AT+CSQ":                       "+CSQ: 17,99" + Sep + Sep + "OK",
+	})
+	assert.Equal(t, []string{"before", "after"}, calls)
+
+	p := dev.Commands.(*GenericProfile)
+	p.BeforeInit = nil
+	p.AfterInit = []InitHook{func(*Device) error {
+		return errors.New("failed")
+	}}
+	assert.EqualError(t, dev.initProfile(dev.Commands), "at init: after init hook: failed")
+}
@@ -1,6 +1,9 @@
 package at
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 // Opt represents a numerical option.
 type Opt struct {
@@ -32,6 +35,18 @@ const KillCmd = "AT_KILL"
 // NoopCmd is like a ping command that signals that the device is responsive.
 const NoopCmd = "AT"
 
+// String returns the human-readable description of an option.
+func (o Opt) String() string {
+	return o.Description
+}
+
+// String returns the human-readable description of a string option.
+func (s StringOpt) String() string {
+	return s.Description
+}
+
+//go:generate go run gen_opts.go
+
 type (
 	optMap     map[int]Opt
 	stringOpts []StringOpt
@@ -62,24 +77,97 @@ type DeviceState struct {
 	SystemMode     Opt
 	SystemSubmode  Opt
 	SimState       Opt
+	MessageStorage StringOpt
+	// MessageService is the service negotiated with AT+CSMS, UnknownOpt if not supported.
+	MessageService Opt
+	CarrierLock    Opt
 	ModelName      string
 	OperatorName   string
 	IMEI           string
+	// SignalStrength is the RSSI as in AT+CSQ, 99 if unknown.
 	SignalStrength int
+	// UnlockAttempts is the number of remaining attempts to enter the unlock code, -1 if unknown.
+	UnlockAttempts int
+	// StoredMessages is the number of the messages in the storage as of the last
+	// inbox check, -1 if unknown.
+	StoredMessages int
 }
 
 // NewDeviceState returns a clean state with unknown options.
 func NewDeviceState() *DeviceState {
 	return &DeviceState{
-		ServiceState:  UnknownOpt,
-		ServiceDomain: UnknownOpt,
-		RoamingState:  UnknownOpt,
-		SystemMode:    UnknownOpt,
-		SystemSubmode: UnknownOpt,
-		SimState:      UnknownOpt,
+		ServiceState:   UnknownOpt,
+		ServiceDomain:  UnknownOpt,
+		RoamingState:   UnknownOpt,
+		SystemMode:     UnknownOpt,
+		SystemSubmode:  UnknownOpt,
+		SimState:       UnknownOpt,
+		MessageStorage: UnknownStringOpt,
+		MessageService: UnknownOpt,
+		CarrierLock:    UnknownOpt,
+		SignalStrength: 99,
+		UnlockAttempts: -1,
+		StoredMessages: -1,
 	}
 }
 
+// known reports whether the option has been reported by the device.
+func known(opt Opt) bool {
+	return opt != UnknownOpt && opt.Description != ""
+}
+
+// Summary returns a concise human-readable status line, i.e.
+// "Registered (home) on Vodafone, LTE, RSSI -75 dBm, SIM ready, 3 msgs stored".
+// The parts that haven't been reported by the device are omitted.
+func (s *DeviceState) Summary() string {
+	var service string
+	switch s.ServiceState {
+	case ServiceStates.Valid:
+		service = "Registered"
+		switch s.RoamingState {
+		case RoamingStates.NotRoaming:
+			service += " (home)"
+		case RoamingStates.Roaming:
+			service += " (roaming)"
+		}
+	case ServiceStates.None:
+		service = "No service"
+	case ServiceStates.Restricted, ServiceStates.RestrictedRegional:
+		service = "Limited service"
+	case ServiceStates.PowerSaving:
+		service = "Power saving"
+	default:
+		service = "Unknown service"
+	}
+	if len(s.OperatorName) > 0 {
+		service += " on " + s.OperatorName
+	}
+	parts := []string{service}
+
+	switch {
+	case known(s.SystemSubmode) && s.SystemSubmode != SystemSubmodes.NoService:
+		parts = append(parts, s.SystemSubmode.Description)
+	case known(s.SystemMode) && s.SystemMode != SystemModes.NoService:
+		parts = append(parts, s.SystemMode.Description)
+	}
+	// the RSSI is reported as in AT+CSQ: 0 is -113 dBm or less, 31 is -51 dBm or more
+	if s.SignalStrength >= 0 && s.SignalStrength <= 31 {
+		parts = append(parts, "RSSI "+strconv.Itoa(-113+2*s.SignalStrength)+" dBm")
+	}
+	switch {
+	case s.SimState == SimStates.Valid:
+		parts = append(parts, "SIM ready")
+	case s.SimState == SimStates.NoCard:
+		parts = append(parts, "no SIM")
+	case known(s.SimState):
+		parts = append(parts, s.SimState.Description)
+	}
+	if s.StoredMessages >= 0 {
+		parts = append(parts, strconv.Itoa(s.StoredMessages)+" msgs stored")
+	}
+	return strings.Join(parts, ", ")
+}
+
 var sim = optMap{
 	0:   Opt{0, "Invalid USIM card or pin code locked"},
 	1:   Opt{1, "Valid USIM card"},
@@ -91,7 +179,8 @@ var sim = optMap{
 
 // SimStates represent the possible data card states.
 var SimStates = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	Invalid     Opt
 	Valid       Opt
@@ -101,6 +190,7 @@ var SimStates = struct {
 	NoCard      Opt
 }{
 	func(id int) Opt { return sim.Resolve(id) },
+	simStatesValues,
 
 	sim[0], sim[1], sim[2], sim[3], sim[4], sim[255],
 }
@@ -115,7 +205,8 @@ var service = optMap{
 
 // ServiceStates represent the possible service states.
 var ServiceStates = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	None               Opt
 	Restricted         Opt
@@ -124,6 +215,7 @@ var ServiceStates = struct {
 	PowerSaving        Opt
 }{
 	func(id int) Opt { return service.Resolve(id) },
+	serviceStatesValues,
 
 	service[0], service[1], service[2], service[3], service[4],
 }
@@ -138,7 +230,8 @@ var domain = optMap{
 
 // ServiceDomains represent the possible service domains.
 var ServiceDomains = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	None               Opt
 	Restricted         Opt
@@ -147,6 +240,7 @@ var ServiceDomains = struct {
 	PowerSaving        Opt
 }{
 	func(id int) Opt { return domain.Resolve(id) },
+	serviceDomainsValues,
 
 	domain[0], domain[1],
 	domain[2], domain[3], domain[4],
@@ -159,12 +253,14 @@ var roaming = optMap{
 
 // RoamingStates represent the state of roaming.
 var RoamingStates = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	NotRoaming Opt
 	Roaming    Opt
 }{
 	func(id int) Opt { return roaming.Resolve(id) },
+	roamingStatesValues,
 
 	roaming[0], roaming[1],
 }
@@ -180,11 +276,13 @@ var mode = optMap{
 	7:  Opt{7, "GSM/WCDMA"},
 	8:  Opt{8, "CDMA/HDR HYBRID"},
 	15: Opt{15, "TD-SCDMA"},
+	17: Opt{17, "LTE"},
 }
 
 // SystemModes represent the possible system operating modes.
 var SystemModes = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	NoService Opt
 	AMPS      Opt
@@ -196,11 +294,14 @@ var SystemModes = struct {
 	GsmWcdma  Opt
 	CdmaHdr   Opt
 	SCDMA     Opt
+	LTE       Opt
 }{
 	func(id int) Opt { return mode.Resolve(id) },
+	systemModesValues,
 
 	mode[0], mode[1], mode[2], mode[3], mode[4],
 	mode[5], mode[6], mode[7], mode[8], mode[15],
+	mode[17],
 }
 
 var submode = optMap{
@@ -220,7 +321,8 @@ var submode = optMap{
 
 // SystemSubmodes represent the possible system operating submodes.
 var SystemSubmodes = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	NoService  Opt
 	GSM        Opt
@@ -236,6 +338,7 @@ var SystemSubmodes = struct {
 	HspaMIMO   Opt
 }{
 	func(id int) Opt { return submode.Resolve(id) },
+	systemSubmodesValues,
 
 	submode[0], submode[1], submode[2], submode[3],
 	submode[4], submode[5], submode[6], submode[7],
@@ -261,7 +364,8 @@ var result = stringOpts{
 
 // FinalResults represent the possible replies from a modem.
 var FinalResults = struct {
-	Resolve func(string) StringOpt
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
 
 	Noop              StringOpt
 	Ok                StringOpt
@@ -279,6 +383,7 @@ var FinalResults = struct {
 	Timeout           StringOpt
 }{
 	func(str string) StringOpt { return result.Resolve(str) },
+	finalResultsValues,
 
 	result[0], result[1], result[2], result[3],
 	result[4], result[5], result[6], result[7],
@@ -294,13 +399,15 @@ var resultReporting = optMap{
 
 // UssdResultReporting represents the available options of USSD reporting.
 var UssdResultReporting = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	Disable Opt
 	Enable  Opt
 	Exit    Opt
 }{
 	func(id int) Opt { return resultReporting.Resolve(id) },
+	ussdResultReportingValues,
 
 	resultReporting[0],
 	resultReporting[1],
@@ -317,26 +424,51 @@ var reports = stringOpts{
 	{"^SIMST:", "Sim state"},
 	{"^STIN:", "STIN"},
 	{"+CLIP:", "Incoming Caller ID"},
+	{"+CDSI:", "Status report"},
+	{"#QSS:", "Telit SIM status"},
+	{"+ZPAS:", "ZTE network status"},
+	{"+CDS:", "Status report PDU"},
+	{"+CMT:", "Incoming SMS PDU"},
+	{"^JDC:", "Jamming detection"},
+	{"+QJDR:", "Quectel jamming detection"},
+	{"+CBM:", "Cell broadcast PDU"},
+	{"+CIEV:", "Indicator event"},
+	{"+CREC:", "Call recording"},
 }
 
 // Reports represent the possible state reports from a modem.
 var Reports = struct {
-	Resolve func(string) StringOpt
-
-	Ussd           StringOpt
-	Message        StringOpt
-	SignalStrength StringOpt
-	BootHandshake  StringOpt
-	Mode           StringOpt
-	ServiceState   StringOpt
-	SimState       StringOpt
-	Stin           StringOpt
-	CallerID       StringOpt
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	Ussd            StringOpt
+	Message         StringOpt
+	SignalStrength  StringOpt
+	BootHandshake   StringOpt
+	Mode            StringOpt
+	ServiceState    StringOpt
+	SimState        StringOpt
+	Stin            StringOpt
+	CallerID        StringOpt
+	StatusReport    StringOpt
+	QuerySimStatus  StringOpt
+	NetworkStatus   StringOpt
+	StatusReportPDU StringOpt
+	MessagePDU      StringOpt
+	Jamming         StringOpt
+	QuectelJamming  StringOpt
+	BroadcastPDU    StringOpt
+	Indicator       StringOpt
+	Recording       StringOpt
 }{
 	func(str string) StringOpt { return reports.Resolve(str) },
+	reportsValues,
 
 	reports[0], reports[1], reports[2], reports[3],
 	reports[4], reports[5], reports[6], reports[7], reports[8],
+	reports[9], reports[10], reports[11], reports[12], reports[13],
+	reports[14], reports[15], reports[16], reports[17],
+	reports[18],
 }
 
 var mem = stringOpts{
@@ -348,7 +480,8 @@ var mem = stringOpts{
 
 // MemoryTypes represent the available options of message storage.
 var MemoryTypes = struct {
-	Resolve func(string) StringOpt
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
 
 	NvRAM       StringOpt
 	Associated  StringOpt
@@ -356,6 +489,7 @@ var MemoryTypes = struct {
 	StateReport StringOpt
 }{
 	func(str string) StringOpt { return mem.Resolve(str) },
+	memoryTypesValues,
 
 	mem[0], mem[1], mem[2], mem[3],
 }
@@ -368,9 +502,29 @@ var delOpts = optMap{
 	4: Opt{4, "Delete all messages"},
 }
 
+var msgServices = optMap{
+	0: Opt{0, "Phase 2, the messages are acknowledged by the ME"},
+	1: Opt{1, "Phase 2+, the routed messages are acknowledged with AT+CNMA"},
+}
+
+// MessageServices represent the message services selected with AT+CSMS (3GPP TS 27.005).
+var MessageServices = struct {
+	Resolve   func(int) Opt
+	AllValues func() []Opt
+
+	Phase2     Opt
+	Phase2Plus Opt
+}{
+	func(id int) Opt { return msgServices.Resolve(id) },
+	messageServicesValues,
+
+	msgServices[0], msgServices[1],
+}
+
 // DeleteOptions represent the available options of message deletion masks.
 var DeleteOptions = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	Index            Opt
 	AllReadNotMO     Opt
@@ -378,7 +532,8 @@ var DeleteOptions = struct {
 	AllNotUnread     Opt
 	All              Opt
 }{
-	func(id int) Opt { return resultReporting.Resolve(id) },
+	func(id int) Opt { return delOpts.Resolve(id) },
+	deleteOptionsValues,
 
 	delOpts[0], delOpts[1], delOpts[2], delOpts[3], delOpts[4],
 }
@@ -393,7 +548,8 @@ var msgFlags = optMap{
 
 // MessageFlags represent the available states of messages in memory.
 var MessageFlags = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	Unread Opt
 	Read   Opt
@@ -401,11 +557,54 @@ var MessageFlags = struct {
 	Sent   Opt
 	Any    Opt
 }{
-	func(id int) Opt { return resultReporting.Resolve(id) },
+	func(id int) Opt { return msgFlags.Resolve(id) },
+	messageFlagsValues,
 
 	msgFlags[0], msgFlags[1], msgFlags[2], msgFlags[3], msgFlags[4],
 }
 
+var inboxPolicies = stringOpts{
+	{"delete", "Delete after reading"},
+	{"mark-read", "Keep marked as read"},
+	{"leave", "Keep in the storage"},
+}
+
+// InboxPolicies represent what happens to the stored messages after they were fetched.
+var InboxPolicies = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	Delete   StringOpt
+	MarkRead StringOpt
+	Leave    StringOpt
+}{
+	func(str string) StringOpt { return inboxPolicies.Resolve(str) },
+	inboxPoliciesValues,
+
+	inboxPolicies[0], inboxPolicies[1], inboxPolicies[2],
+}
+
+var indicationPolicies = stringOpts{
+	{"fetch", "Fetch the message immediately"},
+	{"batch", "Fetch the inbox after a delay"},
+	{"notify", "Notify the application"},
+}
+
+// IndicationPolicies represent what happens when a new message is indicated with +CMTI.
+var IndicationPolicies = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	Fetch  StringOpt
+	Batch  StringOpt
+	Notify StringOpt
+}{
+	func(str string) StringOpt { return indicationPolicies.Resolve(str) },
+	indicationPoliciesValues,
+
+	indicationPolicies[0], indicationPolicies[1], indicationPolicies[2],
+}
+
 var callerIDType = optMap{
 	129: Opt{129, "Network Specific Caller ID"},
 	145: Opt{145, "International Caller ID"},
@@ -413,12 +612,14 @@ var callerIDType = optMap{
 
 // CallerIDTypes represent the possible caller id types.
 var CallerIDTypes = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	NetworkSpecific Opt
 	International   Opt
 }{
 	func(id int) Opt { return callerIDType.Resolve(id) },
+	callerIDTypesValues,
 
 	callerIDType[129], callerIDType[145],
 }
@@ -431,13 +632,57 @@ var callerIDValidity = optMap{
 
 // CallerIDValidityStates represent the possible caller id validity states.
 var CallerIDValidityStates = struct {
-	Resolve func(int) Opt
+	Resolve   func(int) Opt
+	AllValues func() []Opt
 
 	Valid    Opt
 	Rejected Opt
 	Denied   Opt
 }{
 	func(id int) Opt { return callerIDValidity.Resolve(id) },
+	callerIDValidityStatesValues,
 
 	callerIDValidity[0], callerIDValidity[1], callerIDValidity[2],
 }
+
+var funLevels = optMap{
+	0: Opt{0, "Minimum functionality"},
+	1: Opt{1, "Full functionality"},
+	4: Opt{4, "Transmit and receive RF circuits disabled"},
+}
+
+// FunctionalityLevels represent the levels of the modem functionality set by AT+CFUN.
+var FunctionalityLevels = struct {
+	Resolve   func(int) Opt
+	AllValues func() []Opt
+
+	Minimum Opt
+	Full    Opt
+	Flight  Opt
+}{
+	func(id int) Opt { return funLevels.Resolve(id) },
+	functionalityLevelsValues,
+
+	funLevels[0], funLevels[1], funLevels[4],
+}
+
+var lockStates = optMap{
+	0: Opt{0, "Not locked"},
+	1: Opt{1, "Locked to a network, unlock code required"},
+	2: Opt{2, "Locked permanently"},
+}
+
+// LockStates represent the network personalization (SIMLOCK) states of the modem.
+var LockStates = struct {
+	Resolve   func(int) Opt
+	AllValues func() []Opt
+
+	Unlocked    Opt
+	Locked      Opt
+	Permanently Opt
+}{
+	func(id int) Opt { return lockStates.Resolve(id) },
+	lockStatesValues,
+
+	lockStates[0], lockStates[1], lockStates[2],
+}
@@ -23,4 +23,42 @@ func TestResolve(t *testing.T) {
 	assert.Equal(t, opt, ServiceStates.Resolve(1))
 }
 
+// Test the enumeration and stringification of an opt set.
+func TestAllValues(t *testing.T) {
+	t.Parallel()
+
+	values := DeleteOptions.AllValues()
+	assert.Len(t, values, 5)
+	assert.Equal(t, DeleteOptions.Index, values[0])
+	assert.Equal(t, DeleteOptions.All, values[4])
+	assert.Equal(t, MessageFlags.Any, MessageFlags.Resolve(4))
+	assert.Equal(t, "HSPA+", SystemSubmodes.HspaPlus.String())
+	assert.Equal(t, MemoryTypes.NvRAM, MemoryTypes.AllValues()[0])
+}
+
 // TODO: complete this suite in case of 100% coverage needed.
+
+func TestDeviceStateSummary(t *testing.T) {
+	t.Parallel()
+
+	state := NewDeviceState()
+	assert.Equal(t, "Unknown service", state.Summary(), "the signal strength is not reported")
+
+	state.ServiceState = ServiceStates.Valid
+	state.RoamingState = RoamingStates.NotRoaming
+	state.OperatorName = "Vodafone"
+	state.SystemMode = SystemModes.LTE
+	state.SystemSubmode = UnknownOpt
+	state.SignalStrength = 19
+	state.SimState = SimStates.Valid
+	assert.Equal(t, "Registered (home) on Vodafone, LTE, RSSI -75 dBm, SIM ready", state.Summary())
+
+	state.ServiceState = ServiceStates.None
+	state.OperatorName = ""
+	state.SystemMode = SystemModes.NoService
+	state.SimState = SimStates.Invalid
+	assert.Equal(t, "No service, RSSI -75 dBm, Invalid USIM card or pin code locked", state.Summary())
+
+	state.StoredMessages = 3
+	assert.Equal(t, "No service, RSSI -75 dBm, Invalid USIM card or pin code locked, 3 msgs stored", state.Summary())
+}
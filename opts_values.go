@@ -0,0 +1,298 @@
+// Code generated by gen_opts.go; DO NOT EDIT.
+
+package at
+
+// featuresValues returns the options of Features in the order of its fields.
+func featuresValues() []StringOpt {
+	return []StringOpt{
+		features[0],  // SMS
+		features[1],  // USSD
+		features[2],  // Voice
+		features[3],  // Data
+		features[4],  // Power
+		features[5],  // BaudRate
+		features[6],  // CarrierLock
+		features[7],  // OwnNumber
+		features[8],  // Recording
+		features[9],  // Boot
+		features[10], // GNSS
+		features[11], // TCP
+		features[12], // Jamming
+	}
+}
+
+// verdictsValues returns the options of Verdicts in the order of its fields.
+func verdictsValues() []StringOpt {
+	return []StringOpt{
+		verdicts[0], // Deliver
+		verdicts[1], // Tag
+		verdicts[2], // Divert
+		verdicts[3], // Drop
+	}
+}
+
+// holdEventsValues returns the options of HoldEvents in the order of its fields.
+func holdEventsValues() []StringOpt {
+	return []StringOpt{
+		holdEvents[0], // Queued
+		holdEvents[1], // Flushed
+		holdEvents[2], // Expired
+	}
+}
+
+// lifecycleStatesValues returns the options of LifecycleStates in the order of its fields.
+func lifecycleStatesValues() []StringOpt {
+	return []StringOpt{
+		lifecycleStates[0], // Closed
+		lifecycleStates[1], // Opening
+		lifecycleStates[2], // Initializing
+		lifecycleStates[3], // Ready
+		lifecycleStates[4], // Degraded
+		lifecycleStates[5], // Reconnecting
+		lifecycleStates[6], // Closing
+	}
+}
+
+// simStatesValues returns the options of SimStates in the order of its fields.
+func simStatesValues() []Opt {
+	return []Opt{
+		sim[0],   // Invalid
+		sim[1],   // Valid
+		sim[2],   // InvalidCS
+		sim[3],   // InvalidPS
+		sim[4],   // InvalidCSPS
+		sim[255], // NoCard
+	}
+}
+
+// serviceStatesValues returns the options of ServiceStates in the order of its fields.
+func serviceStatesValues() []Opt {
+	return []Opt{
+		service[0], // None
+		service[1], // Restricted
+		service[2], // Valid
+		service[3], // RestrictedRegional
+		service[4], // PowerSaving
+	}
+}
+
+// serviceDomainsValues returns the options of ServiceDomains in the order of its fields.
+func serviceDomainsValues() []Opt {
+	return []Opt{
+		domain[0], // None
+		domain[1], // Restricted
+		domain[2], // Valid
+		domain[3], // RestrictedRegional
+		domain[4], // PowerSaving
+	}
+}
+
+// roamingStatesValues returns the options of RoamingStates in the order of its fields.
+func roamingStatesValues() []Opt {
+	return []Opt{
+		roaming[0], // NotRoaming
+		roaming[1], // Roaming
+	}
+}
+
+// systemModesValues returns the options of SystemModes in the order of its fields.
+func systemModesValues() []Opt {
+	return []Opt{
+		mode[0],  // NoService
+		mode[1],  // AMPS
+		mode[2],  // CDMA
+		mode[3],  // GsmGprs
+		mode[4],  // HDR
+		mode[5],  // WCDMA
+		mode[6],  // GPS
+		mode[7],  // GsmWcdma
+		mode[8],  // CdmaHdr
+		mode[15], // SCDMA
+		mode[17], // LTE
+	}
+}
+
+// systemSubmodesValues returns the options of SystemSubmodes in the order of its fields.
+func systemSubmodesValues() []Opt {
+	return []Opt{
+		submode[0],  // NoService
+		submode[1],  // GSM
+		submode[2],  // GPRS
+		submode[3],  // EDGE
+		submode[4],  // WCDMA
+		submode[5],  // HSDPA
+		submode[6],  // HSUPA
+		submode[7],  // HsdpaHsupa
+		submode[8],  // SCDMA
+		submode[9],  // HspaPlus
+		submode[17], // Hspa64QAM
+		submode[18], // HspaMIMO
+	}
+}
+
+// finalResultsValues returns the options of FinalResults in the order of its fields.
+func finalResultsValues() []StringOpt {
+	return []StringOpt{
+		result[0],  // Noop
+		result[1],  // Ok
+		result[2],  // Connect
+		result[3],  // Ring
+		result[4],  // NoCarrier
+		result[5],  // Error
+		result[6],  // NoDialtone
+		result[7],  // Busy
+		result[8],  // NoAnswer
+		result[9],  // CmeError
+		result[10], // CmsError
+		result[11], // NotSupported
+		result[12], // TooManyParameters
+		result[13], // Timeout
+	}
+}
+
+// ussdResultReportingValues returns the options of UssdResultReporting in the order of its fields.
+func ussdResultReportingValues() []Opt {
+	return []Opt{
+		resultReporting[0], // Disable
+		resultReporting[1], // Enable
+		resultReporting[2], // Exit
+	}
+}
+
+// reportsValues returns the options of Reports in the order of its fields.
+func reportsValues() []StringOpt {
+	return []StringOpt{
+		reports[0],  // Ussd
+		reports[1],  // Message
+		reports[2],  // SignalStrength
+		reports[3],  // BootHandshake
+		reports[4],  // Mode
+		reports[5],  // ServiceState
+		reports[6],  // SimState
+		reports[7],  // Stin
+		reports[8],  // CallerID
+		reports[9],  // StatusReport
+		reports[10], // QuerySimStatus
+		reports[11], // NetworkStatus
+		reports[12], // StatusReportPDU
+		reports[13], // MessagePDU
+		reports[14], // Jamming
+		reports[15], // QuectelJamming
+		reports[16], // BroadcastPDU
+		reports[17], // Indicator
+		reports[18], // Recording
+	}
+}
+
+// memoryTypesValues returns the options of MemoryTypes in the order of its fields.
+func memoryTypesValues() []StringOpt {
+	return []StringOpt{
+		mem[0], // NvRAM
+		mem[1], // Associated
+		mem[2], // Sim
+		mem[3], // StateReport
+	}
+}
+
+// messageServicesValues returns the options of MessageServices in the order of its fields.
+func messageServicesValues() []Opt {
+	return []Opt{
+		msgServices[0], // Phase2
+		msgServices[1], // Phase2Plus
+	}
+}
+
+// deleteOptionsValues returns the options of DeleteOptions in the order of its fields.
+func deleteOptionsValues() []Opt {
+	return []Opt{
+		delOpts[0], // Index
+		delOpts[1], // AllReadNotMO
+		delOpts[2], // AllReadNotUnsent
+		delOpts[3], // AllNotUnread
+		delOpts[4], // All
+	}
+}
+
+// messageFlagsValues returns the options of MessageFlags in the order of its fields.
+func messageFlagsValues() []Opt {
+	return []Opt{
+		msgFlags[0], // Unread
+		msgFlags[1], // Read
+		msgFlags[2], // Unsent
+		msgFlags[3], // Sent
+		msgFlags[4], // Any
+	}
+}
+
+// inboxPoliciesValues returns the options of InboxPolicies in the order of its fields.
+func inboxPoliciesValues() []StringOpt {
+	return []StringOpt{
+		inboxPolicies[0], // Delete
+		inboxPolicies[1], // MarkRead
+		inboxPolicies[2], // Leave
+	}
+}
+
+// indicationPoliciesValues returns the options of IndicationPolicies in the order of its fields.
+func indicationPoliciesValues() []StringOpt {
+	return []StringOpt{
+		indicationPolicies[0], // Fetch
+		indicationPolicies[1], // Batch
+		indicationPolicies[2], // Notify
+	}
+}
+
+// callerIDTypesValues returns the options of CallerIDTypes in the order of its fields.
+func callerIDTypesValues() []Opt {
+	return []Opt{
+		callerIDType[129], // NetworkSpecific
+		callerIDType[145], // International
+	}
+}
+
+// callerIDValidityStatesValues returns the options of CallerIDValidityStates in the order of its fields.
+func callerIDValidityStatesValues() []Opt {
+	return []Opt{
+		callerIDValidity[0], // Valid
+		callerIDValidity[1], // Rejected
+		callerIDValidity[2], // Denied
+	}
+}
+
+// functionalityLevelsValues returns the options of FunctionalityLevels in the order of its fields.
+func functionalityLevelsValues() []Opt {
+	return []Opt{
+		funLevels[0], // Minimum
+		funLevels[1], // Full
+		funLevels[4], // Flight
+	}
+}
+
+// lockStatesValues returns the options of LockStates in the order of its fields.
+func lockStatesValues() []Opt {
+	return []Opt{
+		lockStates[0], // Unlocked
+		lockStates[1], // Locked
+		lockStates[2], // Permanently
+	}
+}
+
+// outboxStatesValues returns the options of OutboxStates in the order of its fields.
+func outboxStatesValues() []StringOpt {
+	return []StringOpt{
+		outboxStates[0], // Queued
+		outboxStates[1], // Sent
+		outboxStates[2], // Delivered
+		outboxStates[3], // Failed
+	}
+}
+
+// operationsValues returns the options of Operations in the order of its fields.
+func operationsValues() []StringOpt {
+	return []StringOpt{
+		operations[0], // DeleteAll
+		operations[1], // Reset
+		operations[2], // FactoryReset
+		operations[3], // FacilityLock
+	}
+}
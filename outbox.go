@@ -0,0 +1,504 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+var outboxStates = stringOpts{
+	{"queued", "Waiting to be sent"},
+	{"sent", "Accepted by the SMSC"},
+	{"delivered", "Confirmed by a status report"},
+	{"failed", "Not sent or not delivered"},
+}
+
+// OutboxStates represent the states of the messages in an Outbox.
+var OutboxStates = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	Queued    StringOpt
+	Sent      StringOpt
+	Delivered StringOpt
+	Failed    StringOpt
+}{
+	func(str string) StringOpt { return outboxStates.Resolve(str) },
+	outboxStatesValues,
+
+	outboxStates[0], outboxStates[1], outboxStates[2], outboxStates[3],
+}
+
+// errNoService is the last error of the messages queued while the modem has no service.
+var errNoService = errors.New("at: modem has no service")
+
+// errMessageCanceled is the last error of the messages canceled before they were sent.
+var errMessageCanceled = errors.New("at: message is canceled")
+
+// DefaultOutboxRetry is the retry policy of an Outbox unless specified otherwise.
+var DefaultOutboxRetry = RetryPolicy{
+	Attempts:   5,
+	Backoff:    30 * time.Second,
+	MaxBackoff: 30 * time.Minute,
+}
+
+// OutboxMessage is a message queued in an Outbox.
+type OutboxMessage struct {
+	// ID is the correlation ID of the message, see SendOptions.CorrelationID.
+	ID      string
+	Address sms.PhoneNumber
+	Text    string
+	Options SendOptions
+	// Long sends the message with SendLongSMS.
+	Long bool
+	// Parts is the number of the parts the message is sent in.
+	Parts int
+	// Reference is the reference number of the concatenated message, the retried parts keep it.
+	Reference byte
+	// Sent is the number of the parts accepted by the SMSC, a retry sends the rest of them.
+	Sent int
+	// Reports is the number of the parts confirmed by the status reports.
+	Reports int
+	State   StringOpt
+	// Attempts is the number of the failed attempts to send the message,
+	// the deferrals are not counted.
+	Attempts int
+	// NextAttempt is the time the queued message is sent at.
+	NextAttempt time.Time
+	// LastError is the error of the last attempt.
+	LastError string
+	Queued    time.Time
+	Updated   time.Time
+}
+
+// Final reports whether the message will not change its state anymore.
+func (m *OutboxMessage) Final() bool {
+	switch m.State {
+	case OutboxStates.Delivered, OutboxStates.Failed:
+		return true
+	case OutboxStates.Sent:
+		return !m.Options.StatusReport
+	}
+	return false
+}
+
+// OutboxStore persists the messages of an Outbox, so the queue survives restarts.
+// Save is called on each change of a message, Delete once the message reaches its final state.
+type OutboxStore interface {
+	Save(msg OutboxMessage) error
+	Load() ([]OutboxMessage, error)
+	Delete(id string) error
+}
+
+// Outbox queues the messages and sends them with the Device, the ones of a higher
+// SendOptions.Priority first. The messages that failed with a temporary CMS error
+// (i.e. a network timeout), a plain ERROR, a timeout or a lost device are retried with
+// the backoff from the failed part, the deferred messages are sent once their routes
+// allow it. The outbox bypasses the Device's HoldPolicy: while the modem has no service
+// the messages stay queued and persisted, and are checked again after the backoff.
+type Outbox struct {
+	Device *Device
+	// Store persists the queue, the messages are kept in memory only if nil.
+	Store OutboxStore
+	// Retry configures the retries of the failed messages, DefaultOutboxRetry is used if zero.
+	Retry RetryPolicy
+
+	mux      sync.Mutex
+	messages map[string]*OutboxMessage
+	wake     chan struct{}
+	updates  chan OutboxMessage
+}
+
+// NewOutbox constructs an Outbox for the device and loads the messages from the store, if given.
+func NewOutbox(d *Device, store OutboxStore) (*Outbox, error) {
+	o := &Outbox{
+		Device:   d,
+		Store:    store,
+		messages: make(map[string]*OutboxMessage),
+		wake:     make(chan struct{}, 1),
+		updates:  make(chan OutboxMessage, 100),
+	}
+	if store == nil {
+		return o, nil
+	}
+	stored, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range stored {
+		msg := stored[i]
+		o.messages[msg.ID] = &msg
+	}
+	return o, nil
+}
+
+// Enqueue queues the message and returns its ID, which is the correlation ID of the options
+// or a generated one. The message is sent by Run.
+func (o *Outbox) Enqueue(text string, address sms.PhoneNumber, long bool, opts ...SendOptions) (string, error) {
+	var so SendOptions
+	if len(opts) > 0 {
+		so = opts[0]
+	}
+	if so.CorrelationID == "" {
+		so.CorrelationID = GenerateID()
+	}
+	parts := 1
+	var ref byte
+	if long {
+		ref = byte(atomic.AddUint32(&o.Device.concatRef, 1))
+		split, err := so.Message(text, address).Split(ref)
+		if err != nil {
+			return "", err
+		}
+		parts = len(split)
+	}
+	now := time.Now()
+	msg := &OutboxMessage{
+		ID:          so.CorrelationID,
+		Address:     address,
+		Text:        text,
+		Options:     so,
+		Long:        long,
+		Parts:       parts,
+		Reference:   ref,
+		State:       OutboxStates.Queued,
+		NextAttempt: now,
+		Queued:      now,
+		Updated:     now,
+	}
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	if _, ok := o.messages[msg.ID]; ok {
+		return "", errors.New("at: message is already queued: " + msg.ID)
+	}
+	if err := o.save(msg); err != nil {
+		return "", err
+	}
+	o.messages[msg.ID] = msg
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return msg.ID, nil
+}
+
+// Message returns the message with the given ID.
+func (o *Outbox) Message(id string) (msg OutboxMessage, ok bool) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	if m, ok := o.messages[id]; ok {
+		return *m, true
+	}
+	return
+}
+
+// Messages returns all messages of the outbox in the order they were queued.
+func (o *Outbox) Messages() []OutboxMessage {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	list := make([]OutboxMessage, 0, len(o.messages))
+	for _, m := range o.messages {
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Queued.Before(list[j].Queued) })
+	return list
+}
+
+// Remove forgets the message in a final state, it reports whether the message was removed.
+func (o *Outbox) Remove(id string) bool {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	if m, ok := o.messages[id]; ok && m.Final() {
+		delete(o.messages, id)
+		return true
+	}
+	return false
+}
+
+// Cancel fails the queued message, its command is canceled if it's waiting for
+// the command port. It reports whether the message was still queued.
+func (o *Outbox) Cancel(id string) (bool, error) {
+	o.mux.Lock()
+	m, ok := o.messages[id]
+	if !ok || m.State != OutboxStates.Queued {
+		o.mux.Unlock()
+		return false, nil
+	}
+	m.State = OutboxStates.Failed
+	m.LastError = errMessageCanceled.Error()
+	m.Updated = time.Now()
+	err := o.save(m)
+	msg := *m
+	o.mux.Unlock()
+	if err != nil {
+		return true, err
+	}
+	for _, cmd := range o.Device.Pending() {
+		if cmd.CorrelationID == id {
+			o.Device.CancelPending(cmd.ID)
+		}
+	}
+	o.publish(msg)
+	return true, nil
+}
+
+// Flush cancels all the queued messages and returns their number.
+func (o *Outbox) Flush() (n int, err error) {
+	for _, m := range o.Messages() {
+		if m.State != OutboxStates.Queued {
+			continue
+		}
+		ok, err := o.Cancel(m.ID)
+		if err != nil {
+			return n, err
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Updates fires when a message changes its state or is rescheduled.
+// The updates are dropped if the channel is not consumed.
+func (o *Outbox) Updates() <-chan OutboxMessage {
+	return o.updates
+}
+
+// Run sends the queued messages until the context is done or the store fails.
+// It subscribes to the Device's deliveries to track the messages sent with a status report:
+// the message is delivered once all of its parts are, or failed if the SMSC gives up on any.
+func (o *Outbox) Run(ctx context.Context) error {
+	deliveries := o.Device.SubscribeDeliveries()
+	defer o.Device.UnsubscribeDeliveries(deliveries)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		next, err := o.sendDue(ctx)
+		if err != nil {
+			return err
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !next.IsZero() {
+			timer.Reset(time.Until(next))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-o.wake:
+		case <-timer.C:
+		case delivery := <-deliveries:
+			if err = o.delivered(delivery); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendDue sends the messages that are due and returns the time of the next attempt.
+func (o *Outbox) sendDue(ctx context.Context) (next time.Time, err error) {
+	now := time.Now()
+	var due []OutboxMessage
+	o.mux.Lock()
+	for _, m := range o.messages {
+		if m.State != OutboxStates.Queued {
+			continue
+		}
+		if !m.NextAttempt.After(now) {
+			due = append(due, *m)
+		} else if next.IsZero() || m.NextAttempt.Before(next) {
+			next = m.NextAttempt
+		}
+	}
+	o.mux.Unlock()
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].Options.Priority != due[j].Options.Priority {
+			return due[i].Options.Priority > due[j].Options.Priority
+		}
+		return due[i].NextAttempt.Before(due[j].NextAttempt)
+	})
+
+	for _, m := range due {
+		if ctx.Err() != nil {
+			return next, nil
+		}
+		opts := m.Options
+		opts.CorrelationID = m.ID
+		var refs []byte
+		parts, sendErr := m.parts()
+		switch {
+		case sendErr != nil:
+		case !o.Device.inService():
+			sendErr = errNoService
+		default:
+			// the Device's hold is memory-only, the outbox keeps the messages itself
+			refs, sendErr = o.Device.sendParts(parts[m.Sent:], opts)
+		}
+		attempt, err := o.update(m.ID, func(msg *OutboxMessage) {
+			msg.Sent += len(refs)
+			if msg.State == OutboxStates.Queued {
+				// unless it's been canceled meanwhile
+				o.result(msg, sendErr)
+			}
+		})
+		if err != nil {
+			return next, err
+		}
+		if attempt.State == OutboxStates.Queued && (next.IsZero() || attempt.NextAttempt.Before(next)) {
+			next = attempt.NextAttempt
+		}
+	}
+	return next, nil
+}
+
+// parts returns the parts the message is sent in.
+func (m *OutboxMessage) parts() ([]sms.Message, error) {
+	msg := m.Options.Message(m.Text, m.Address)
+	if !m.Long {
+		return []sms.Message{msg}, nil
+	}
+	parts, err := msg.Split(m.Reference)
+	if err == nil && len(parts) != m.Parts {
+		err = fmt.Errorf("at: message is split in %d parts instead of %d", len(parts), m.Parts)
+	}
+	return parts, err
+}
+
+// result updates the message with the outcome of the attempt to send it.
+func (o *Outbox) result(msg *OutboxMessage, err error) {
+	var deferred *DeferredError
+	retry := o.Retry
+	if retry == (RetryPolicy{}) {
+		retry = DefaultOutboxRetry
+	}
+	switch {
+	case err == nil:
+		msg.State = OutboxStates.Sent
+		msg.LastError = ""
+		return
+	case errors.Is(err, errNoService):
+		// not an attempt, the message waits for the service
+		msg.NextAttempt = time.Now().Add(retry.delay(0))
+	case errors.As(err, &deferred):
+		msg.NextAttempt = deferred.Until
+	case retryable(err):
+		msg.Attempts++
+		if msg.Attempts > retry.Attempts {
+			msg.State = OutboxStates.Failed
+		} else {
+			msg.NextAttempt = time.Now().Add(retry.delay(msg.Attempts - 1))
+		}
+	default:
+		msg.Attempts++
+		msg.State = OutboxStates.Failed
+	}
+	msg.LastError = err.Error()
+}
+
+// retryableCMS are the CMS errors of the temporary failures (see 3GPP TS 27.005 and TS 24.011),
+// i.e. the network congestion or timeout, the rest of them are permanent: an unknown
+// subscriber, a barred call, an invalid PDU or a missing SIM.
+var retryableCMS = map[int]bool{
+	27:  true, // destination out of service
+	38:  true, // network out of order
+	41:  true, // temporary failure
+	42:  true, // congestion
+	47:  true, // resources unavailable
+	300: true, // ME failure
+	314: true, // SIM busy
+	331: true, // no network service
+	332: true, // network timeout
+	500: true, // unknown error
+}
+
+// retryable reports whether the message that failed with the error may be sent again.
+// The errors of the concatenated messages are wrapped, so the CMS errors are looked up
+// within the text. The manufacturer specific CMS errors (512 and above) and the verbose
+// ones are retried.
+func retryable(err error) bool {
+	text := err.Error()
+	if i := strings.Index(text, FinalResults.CmsError.ID); i >= 0 {
+		code, convErr := strconv.Atoi(strings.TrimSpace(text[i+len(FinalResults.CmsError.ID):]))
+		return convErr != nil || code >= 512 || retryableCMS[code]
+	}
+	return errors.Is(err, errFinalError) ||
+		errors.Is(err, ErrTimeout) || errors.Is(err, ErrWriteFailed) ||
+		errors.Is(err, ErrClosed) || errors.Is(err, ErrDeviceLost)
+}
+
+// delivered updates the message confirmed by the status report.
+func (o *Outbox) delivered(delivery Delivery) error {
+	o.mux.Lock()
+	m, ok := o.messages[delivery.CorrelationID]
+	ok = ok && m.State == OutboxStates.Sent
+	o.mux.Unlock()
+	if !ok {
+		return nil
+	}
+	_, err := o.update(delivery.CorrelationID, func(msg *OutboxMessage) {
+		switch delivery.Status.Category() {
+		case sms.StatusCategories.Complete:
+			if msg.Reports++; msg.Reports >= msg.Parts {
+				msg.State = OutboxStates.Delivered
+			}
+		case sms.StatusCategories.PermanentError, sms.StatusCategories.FinalError:
+			msg.State = OutboxStates.Failed
+			msg.LastError = fmt.Sprintf("at: message is not delivered: status 0x%02X", byte(delivery.Status))
+		}
+	})
+	return err
+}
+
+// update applies the change to the message, persists it and publishes the update.
+func (o *Outbox) update(id string, change func(msg *OutboxMessage)) (OutboxMessage, error) {
+	o.mux.Lock()
+	m, ok := o.messages[id]
+	if !ok {
+		o.mux.Unlock()
+		return OutboxMessage{}, nil
+	}
+	change(m)
+	m.Updated = time.Now()
+	err := o.save(m)
+	msg := *m
+	o.mux.Unlock()
+	if err != nil {
+		return msg, err
+	}
+	o.publish(msg)
+	return msg, nil
+}
+
+// publish sends the update unless the channel is full.
+func (o *Outbox) publish(msg OutboxMessage) {
+	select {
+	case o.updates <- msg:
+	default:
+	}
+}
+
+// save persists the message, or deletes it from the store once it's final.
+// The caller must hold the lock.
+func (o *Outbox) save(msg *OutboxMessage) error {
+	switch {
+	case o.Store == nil:
+		return nil
+	case msg.Final():
+		return o.Store.Delete(msg.ID)
+	default:
+		return o.Store.Save(*msg)
+	}
+}
@@ -0,0 +1,314 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+)
+
+type memoryStore struct {
+	mux      sync.Mutex
+	messages map[string]OutboxMessage
+}
+
+func (s *memoryStore) Save(msg OutboxMessage) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.messages[msg.ID] = msg
+	return nil
+}
+
+func (s *memoryStore) Load() (list []OutboxMessage, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, msg := range s.messages {
+		list = append(list, msg)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) Delete(id string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.messages, id)
+	return nil
+}
+
+func (s *memoryStore) has(id string) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	_, ok := s.messages[id]
+	return ok
+}
+
+func TestOutbox(t *testing.T) {
+	t.Parallel()
+
+	opts := SendOptions{StatusReport: true}
+	replies := make(map[string]string)
+	for address, reply := range map[sms.PhoneNumber]string{
+		"+79261234567": "+CMGS: 10" + Sep + Sep + "OK",
+		"+79267654321": "+CMS ERROR: 500",
+	} {
+		msg := opts.Message("hello", address)
+		n, octets, err := msg.PDU()
+		require.NoError(t, err)
+		replies[fmt.Sprintf("AT+CMGS=%d", n)] = "> "
+		replies[fmt.Sprintf("%02X", octets)+Sub] = reply
+	}
+	dev, _ := newTestDevice(t, replies)
+
+	store := &memoryStore{messages: map[string]OutboxMessage{
+		"restored": {
+			ID:      "restored",
+			Address: "+79261234567",
+			Text:    "hello",
+			Options: opts,
+			Parts:   1,
+			State:   OutboxStates.Queued,
+			Queued:  time.Now(),
+		},
+	}}
+	outbox, err := NewOutbox(dev, store)
+	require.NoError(t, err)
+	outbox.Retry = RetryPolicy{Attempts: 1, Backoff: 10 * time.Millisecond}
+	msg, ok := outbox.Message("restored")
+	require.True(t, ok)
+	assert.Equal(t, OutboxStates.Queued, msg.State)
+
+	failing, err := outbox.Enqueue("hello", "+79267654321", false, opts)
+	require.NoError(t, err)
+	assert.True(t, store.has(failing))
+	_, err = outbox.Enqueue("hello", "+79267654321", false, SendOptions{CorrelationID: failing})
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- outbox.Run(ctx) }()
+
+	final := make(map[string]OutboxMessage)
+	for len(final) < 2 {
+		select {
+		case msg := <-outbox.Updates():
+			if msg.State == OutboxStates.Sent {
+				dev.publishDelivery(Delivery{CorrelationID: msg.ID, Status: 0x00})
+			}
+			if msg.Final() {
+				final[msg.ID] = msg
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("the messages are not final")
+		}
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	assert.Equal(t, OutboxStates.Delivered, final["restored"].State)
+	assert.Equal(t, 1, final["restored"].Reports)
+	assert.Equal(t, OutboxStates.Failed, final[failing].State)
+	assert.Equal(t, 2, final[failing].Attempts)
+	assert.Contains(t, final[failing].LastError, "+CMS ERROR: 500")
+	assert.False(t, store.has("restored"))
+	assert.False(t, store.has(failing))
+
+	assert.Len(t, outbox.Messages(), 2)
+	assert.True(t, outbox.Remove(failing))
+	_, ok = outbox.Message(failing)
+	assert.False(t, ok)
+}
+
+func TestOutboxParts(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("long text ", 20)
+	msg := SendOptions{}.Message(text, "+79261234567")
+	parts, err := msg.Split(1)
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	replies := make(map[string]string)
+	for i, part := range parts {
+		n, octets, err := part.PDU()
+		require.NoError(t, err)
+		replies[fmt.Sprintf("AT+CMGS=%d", n)] = "> "
+		replies[fmt.Sprintf("%02X", octets)+Sub] = []string{"+CMGS: 10" + Sep + Sep + "OK", "+CMS ERROR: 332"}[i]
+	}
+	dev, _ := newTestDevice(t, replies)
+
+	outbox, err := NewOutbox(dev, nil)
+	require.NoError(t, err)
+	outbox.Retry = RetryPolicy{Attempts: 1, Backoff: 10 * time.Millisecond}
+	id, err := outbox.Enqueue(text, "+79261234567", true)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go outbox.Run(ctx)
+	for {
+		select {
+		case msg := <-outbox.Updates():
+			if !msg.Final() {
+				assert.Equal(t, 1, msg.Sent)
+				continue
+			}
+			assert.Equal(t, id, msg.ID)
+			assert.Equal(t, OutboxStates.Failed, msg.State)
+			assert.Equal(t, 2, msg.Attempts)
+			assert.Equal(t, 1, msg.Sent, "the sent part should not be sent again")
+			assert.Contains(t, msg.LastError, "part 2 of 2")
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("the message is not final")
+		}
+	}
+}
+
+func TestOutboxPriority(t *testing.T) {
+	t.Parallel()
+
+	replies := make(map[string]string)
+	for _, text := range []string{"low", "high"} {
+		msg := SendOptions{}.Message(text, "+79261234567")
+		n, octets, err := msg.PDU()
+		require.NoError(t, err)
+		replies[fmt.Sprintf("AT+CMGS=%d", n)] = "> "
+		replies[fmt.Sprintf("%02X", octets)+Sub] = "+CMGS: 10" + Sep + Sep + "OK"
+	}
+	dev, _ := newTestDevice(t, replies)
+
+	outbox, err := NewOutbox(dev, nil)
+	require.NoError(t, err)
+	low, err := outbox.Enqueue("low", "+79261234567", false)
+	require.NoError(t, err)
+	high, err := outbox.Enqueue("high", "+79261234567", false, SendOptions{Priority: PriorityHigh})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go outbox.Run(ctx)
+	var order []string
+	for len(order) < 2 {
+		select {
+		case msg := <-outbox.Updates():
+			order = append(order, msg.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the messages are not sent")
+		}
+	}
+	assert.Equal(t, []string{high, low}, order)
+}
+
+func TestOutboxNoService(t *testing.T) {
+	t.Parallel()
+
+	msg := SendOptions{}.Message("hello", "+79261234567")
+	n, octets, err := msg.PDU()
+	require.NoError(t, err)
+	dev, _ := newTestDevice(t, map[string]string{
+		fmt.Sprintf("AT+CMGS=%d", n):      "> ",
+		fmt.Sprintf("%02X", octets) + Sub: "+CMGS: 10" + Sep + Sep + "OK",
+	})
+	dev.Hold = &HoldPolicy{}
+	dev.State = &DeviceState{ServiceState: ServiceStates.None}
+
+	store := &memoryStore{messages: make(map[string]OutboxMessage)}
+	outbox, err := NewOutbox(dev, store)
+	require.NoError(t, err)
+	outbox.Retry = RetryPolicy{Attempts: 1, Backoff: 10 * time.Millisecond}
+	id, err := outbox.Enqueue("hello", "+79261234567", false)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go outbox.Run(ctx)
+	select {
+	case msg := <-outbox.Updates():
+		assert.Equal(t, OutboxStates.Queued, msg.State)
+		assert.Zero(t, msg.Attempts)
+		assert.Equal(t, errNoService.Error(), msg.LastError)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message is not checked")
+	}
+	assert.True(t, store.has(id), "the message survives restarts")
+	assert.Zero(t, dev.HeldMessages(), "the message is not handed to the Device's hold")
+
+	require.NoError(t, dev.handleReport("^SRVST: 2"))
+	for {
+		select {
+		case msg := <-outbox.Updates():
+			if msg.State == OutboxStates.Queued {
+				continue
+			}
+			assert.Equal(t, OutboxStates.Sent, msg.State)
+			assert.Zero(t, msg.Attempts)
+			assert.False(t, store.has(id))
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("the message is not sent")
+		}
+	}
+}
+
+func TestOutboxCancel(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, nil)
+	store := &memoryStore{messages: make(map[string]OutboxMessage)}
+	outbox, err := NewOutbox(dev, store)
+	require.NoError(t, err)
+	first, err := outbox.Enqueue("first", "+79261234567", false)
+	require.NoError(t, err)
+	_, err = outbox.Enqueue("second", "+79261234567", false)
+	require.NoError(t, err)
+	_, err = outbox.Enqueue("third", "+79261234567", false)
+	require.NoError(t, err)
+
+	ok, err := outbox.Cancel(first)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	msg := <-outbox.Updates()
+	assert.Equal(t, first, msg.ID)
+	assert.Equal(t, OutboxStates.Failed, msg.State)
+	assert.Equal(t, errMessageCanceled.Error(), msg.LastError)
+	assert.False(t, store.has(first))
+
+	ok, err = outbox.Cancel(first)
+	require.NoError(t, err)
+	assert.False(t, ok, "the message is not queued anymore")
+	ok, err = outbox.Cancel("unknown")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	n, err := outbox.Flush()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, msg := range outbox.Messages() {
+		assert.Equal(t, OutboxStates.Failed, msg.State)
+	}
+	assert.Empty(t, store.messages)
+}
+
+func TestRetryable(t *testing.T) {
+	t.Parallel()
+
+	for err, expected := range map[error]bool{
+		errors.New("+CMS ERROR: 332"):                                   true,
+		fmt.Errorf("at: part 2 of 3: %w", errors.New("+CMS ERROR: 42")): true,
+		errors.New("+CMS ERROR: 1"):                                     false,
+		errors.New("+CMS ERROR: 304"):                                   false,
+		errors.New("+CMS ERROR: 530"):                                   true,
+		errors.New("+CMS ERROR: network timeout"):                       true,
+		errFinalError:                true,
+		ErrTimeout:                   true,
+		errors.New("+CME ERROR: 10"): false,
+	} {
+		assert.Equal(t, expected, retryable(err), err.Error())
+	}
+}
@@ -0,0 +1,59 @@
+package at
+
+import (
+	"errors"
+	"time"
+)
+
+// errFinalError is returned when the device replies with a plain ERROR.
+var errFinalError = errors.New(FinalResults.Error.Description)
+
+// RetryPolicy configures the retries of the commands that were replied with a plain ERROR,
+// which some modules do when a command comes too early. The CME and CMS errors are not retried.
+type RetryPolicy struct {
+	// Attempts is the number of retries after the first failure.
+	Attempts int
+	// Backoff is the delay before the first retry, it's doubled for each next one.
+	Backoff time.Duration
+	// MaxBackoff limits the delay, if set.
+	MaxBackoff time.Duration
+}
+
+// delay returns the backoff before the given retry (starting from zero).
+func (r *RetryPolicy) delay(retry int) time.Duration {
+	d := r.Backoff
+	for i := 0; i < retry; i++ {
+		d *= 2
+		if r.MaxBackoff > 0 && d >= r.MaxBackoff {
+			return r.MaxBackoff
+		}
+	}
+	return d
+}
+
+// commandGuarder is implemented by profiles that need a delay between the commands.
+type commandGuarder interface {
+	CommandGuard() time.Duration
+}
+
+// CommandGuard returns the profile's GuardTime.
+func (p *DefaultProfile) CommandGuard() time.Duration {
+	return p.GuardTime
+}
+
+// pace waits until the CommandDelay or the profile's guard time, whichever is longer,
+// passes since the previous command has completed. The caller must hold the command port lock.
+func (d *Device) pace() {
+	delay := d.CommandDelay
+	if p, ok := d.Commands.(commandGuarder); ok {
+		if guard := p.CommandGuard(); guard > delay {
+			delay = guard
+		}
+	}
+	if delay <= 0 {
+		return
+	}
+	if wait := delay - time.Since(d.lastCommand); wait > 0 {
+		time.Sleep(wait)
+	}
+}
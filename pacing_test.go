@@ -0,0 +1,46 @@
+package at
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	r := &RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	assert.Equal(t, time.Second, r.delay(0))
+	assert.Equal(t, 2*time.Second, r.delay(1))
+	assert.Equal(t, 3*time.Second, r.delay(2))
+}
+
+func TestPacing(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{"AT+CSQ": "ERROR"})
+	dev.CommandDelay = 20 * time.Millisecond
+	start := time.Now()
+	dev.Send(NoopCmd)
+	dev.Send(NoopCmd)
+	assert.GreaterOrEqual(t, time.Since(start), dev.CommandDelay)
+
+	dev.CommandDelay = 0
+	dev.Retry = &RetryPolicy{Attempts: 2, Backoff: 10 * time.Millisecond}
+	start = time.Now()
+	_, err := dev.Send(`AT+CSQ`)
+	assert.ErrorIs(t, err, errFinalError)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestGuardTime(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, nil)
+	dev.Commands.(*noopProfile).GuardTime = 20 * time.Millisecond
+	start := time.Now()
+	dev.Send(NoopCmd)
+	dev.Send(NoopCmd)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
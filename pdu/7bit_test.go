@@ -11,7 +11,11 @@ import (
 func TestIs7BitEncodable(t *testing.T) {
 	t.Parallel()
 
-	for _, r := range gsmTable {
+	for i, r := range gsmTable {
+		if byte(i) == Esc {
+			assert.False(t, Is7BitEncodable(string(r)), "'%c' is decoded from the escape code", r)
+			continue
+		}
 		ok := Is7BitEncodable(string(r))
 		assert.True(t, ok, "'%c' should be 7bit-encodable, but wasn't", r)
 	}
@@ -38,6 +42,22 @@ func TestEncode7Bit(t *testing.T) {
 	}
 }
 
+func TestEncode7BitHeader(t *testing.T) {
+	t.Parallel()
+
+	header := []byte{0x05, 0x00, 0x03, 0xCC, 0x02, 0x01}
+	octets := Encode7BitHeader(header, "hello[world]")
+	assert.Equal(t, header, octets[:len(header)])
+	assert.Zero(t, octets[len(header)]&0x01, "the fill bit should be zero")
+	str, n, err := Decode7BitHeader(octets)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, n)
+	assert.Equal(t, "hello[world]", str)
+
+	_, _, err = Decode7BitHeader([]byte{0x05, 0x00, 0x03})
+	assert.ErrorIs(t, err, ErrHeaderLength)
+}
+
 func TestDecode7Bit(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,58 @@
+package pdu
+
+// Alphabet is a GSM 7-bit alphabet (3GPP TS 23.038): a table of 128 characters and
+// an extension table of the characters that are reached through the escape code.
+// The tables are generated from the data files in the alphabets directory.
+type Alphabet struct {
+	Name string
+
+	basic runeTable
+	shift escapeTable
+}
+
+// Alphabets returns all the known alphabets.
+func Alphabets() []*Alphabet {
+	return append([]*Alphabet(nil), alphabets...)
+}
+
+// languages maps the national language identifiers (3GPP TS 23.038 section 6.2.1.2.4)
+// to the alphabets that hold their shift tables.
+var languages = map[byte]*Alphabet{
+	0x00: DefaultAlphabet,
+	0x01: TurkishAlphabet,
+	0x02: SpanishAlphabet,
+	0x03: PortugueseAlphabet,
+}
+
+// LanguageAlphabet returns the alphabet of the national language identifier,
+// i.e. of the national language shift information elements of the user data header.
+// The default alphabet is returned if the language is not known.
+func LanguageAlphabet(id byte) *Alphabet {
+	if a, ok := languages[id]; ok {
+		return a
+	}
+	return DefaultAlphabet
+}
+
+// Language returns the national language identifier of the alphabet.
+func (a *Alphabet) Language() byte {
+	for id, alphabet := range languages {
+		if alphabet == a {
+			return id
+		}
+	}
+	return 0x00
+}
+
+// CharCost returns the number of septets taken by r in the alphabet: 1 for
+// the characters of the main table, 2 for the characters of the extension table
+// and 0 if r cannot be encoded.
+func (a *Alphabet) CharCost(r rune) int {
+	return Tables{Locking: a, Single: a}.CharCost(r)
+}
+
+// Cost returns the number of septets taken by s in the alphabet and reports
+// whether all of its characters could be encoded.
+func (a *Alphabet) Cost(s string) (septets int, ok bool) {
+	return Tables{Locking: a, Single: a}.Cost(s)
+}
@@ -0,0 +1,68 @@
+package pdu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlphabetCost(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []*Alphabet{DefaultAlphabet, PortugueseAlphabet, SpanishAlphabet, TurkishAlphabet}, Alphabets())
+	assert.Equal(t, 1, DefaultAlphabet.CharCost('@'))
+	assert.Equal(t, 2, DefaultAlphabet.CharCost('€'))
+	assert.Equal(t, 0, DefaultAlphabet.CharCost('ы'))
+
+	n, ok := DefaultAlphabet.Cost("hello[world]!")
+	assert.True(t, ok)
+	assert.Equal(t, 15, n)
+	n, ok = DefaultAlphabet.Cost("ы?")
+	assert.False(t, ok)
+	assert.Equal(t, 2, n)
+}
+
+func TestNationalTables(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, TurkishAlphabet, LanguageAlphabet(0x01))
+	assert.Equal(t, DefaultAlphabet, LanguageAlphabet(0x0F))
+	assert.EqualValues(t, 0x03, PortugueseAlphabet.Language())
+
+	const text = "Şifreniz: ığüşöç"
+	assert.False(t, Is7BitEncodable(text))
+	turkish := Tables{Locking: TurkishAlphabet, Single: TurkishAlphabet}
+	assert.True(t, turkish.Encodable(text))
+	str, err := turkish.Decode(turkish.Encode(text))
+	assert.NoError(t, err)
+	assert.Equal(t, text, str)
+
+	// the single shift table alone is enough, but takes more septets
+	single := Tables{Single: TurkishAlphabet}
+	assert.True(t, single.Encodable(text))
+	n, ok := single.Cost(text)
+	assert.True(t, ok)
+	assert.Equal(t, 20, n)
+	header := []byte{0x03, 0x24, 0x01, 0x01}
+	str, headerSeptets, err := single.DecodeHeader(single.EncodeHeader(header, text))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, headerSeptets)
+	assert.Equal(t, text, str)
+
+	// the default tables mangle the text
+	str, err = Decode7Bit(single.Encode(text))
+	assert.NoError(t, err)
+	assert.NotEqual(t, text, str)
+
+	tables, ok := SelectTables(text)
+	assert.True(t, ok)
+	assert.Equal(t, Tables{Locking: TurkishAlphabet}, tables)
+	tables, ok = SelectTables("Mañana, canción")
+	assert.True(t, ok)
+	assert.Equal(t, Tables{Single: SpanishAlphabet}, tables)
+	tables, ok = SelectTables("hello")
+	assert.True(t, ok)
+	assert.Equal(t, Tables{}, tables)
+	_, ok = SelectTables("привет")
+	assert.False(t, ok)
+}
@@ -0,0 +1,88 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnencodable is returned in the Strict mode for the characters that the 7-bit tables lack.
+var ErrUnencodable = errors.New("7bit encode: the character is not encodable")
+
+// EncodeMode controls the characters that can't be encoded with the 7-bit tables.
+type EncodeMode byte
+
+// The modes of the 7-bit encoding.
+const (
+	// Replace leaves the characters to the encoder, they are replaced with "?".
+	Replace EncodeMode = iota
+	// Strict fails the text with ErrUnencodable.
+	Strict
+	// Lossy transliterates the characters to the nearest ones, i.e. "ą" to "a"
+	// and the typographic quotes to the plain ones, the rest are replaced with "?".
+	Lossy
+)
+
+// Convert7Bit prepares the text for the 7-bit encoding with the default alphabet
+// according to the mode, see Tables.Convert.
+func Convert7Bit(str string, mode EncodeMode) (string, error) {
+	return Tables{}.Convert(str, mode)
+}
+
+// Convert prepares the text for the encoding with the tables according to the mode:
+// the text is returned as is in the Replace mode, an error is returned in the Strict mode
+// if the text is not encodable and the text is transliterated in the Lossy mode.
+func (t Tables) Convert(str string, mode EncodeMode) (string, error) {
+	if mode == Replace || t.Encodable(str) {
+		return str, nil
+	}
+	var buf strings.Builder
+	for i, r := range str {
+		switch {
+		case t.CharCost(r) > 0:
+			buf.WriteRune(r)
+		case mode == Strict:
+			return "", fmt.Errorf("%w: %q at %d", ErrUnencodable, r, i)
+		default:
+			if s, ok := transliterations[r]; ok && t.Encodable(s) {
+				buf.WriteString(s)
+			} else {
+				buf.WriteRune(unknown)
+			}
+		}
+	}
+	return buf.String(), nil
+}
+
+// transliterations map the Latin letters with the diacritics and the typographic
+// characters to their nearest equivalents of the default alphabet.
+var transliterations = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE", 'Ç': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ð': "D", 'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y", 'Þ': "TH", 'ß': "ss", 'à': "a",
+	'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae", 'ç': "c", 'è': "e",
+	'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d",
+	'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ù': "u",
+	'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'þ': "th", 'ÿ': "y", 'Ā': "A", 'ā': "a",
+	'Ă': "A", 'ă': "a", 'Ą': "A", 'ą': "a", 'Ć': "C", 'ć': "c", 'Ĉ': "C", 'ĉ': "c",
+	'Ċ': "C", 'ċ': "c", 'Č': "C", 'č': "c", 'Ď': "D", 'ď': "d", 'Đ': "D", 'đ': "d",
+	'Ē': "E", 'ē': "e", 'Ĕ': "E", 'ĕ': "e", 'Ė': "E", 'ė': "e", 'Ę': "E", 'ę': "e",
+	'Ě': "E", 'ě': "e", 'Ĝ': "G", 'ĝ': "g", 'Ğ': "G", 'ğ': "g", 'Ġ': "G", 'ġ': "g",
+	'Ģ': "G", 'ģ': "g", 'Ĥ': "H", 'ĥ': "h", 'Ħ': "H", 'ħ': "h", 'Ĩ': "I", 'ĩ': "i",
+	'Ī': "I", 'ī': "i", 'Ĭ': "I", 'ĭ': "i", 'Į': "I", 'į': "i", 'İ': "I", 'ı': "i",
+	'Ĳ': "IJ", 'ĳ': "ij", 'Ĵ': "J", 'ĵ': "j", 'Ķ': "K", 'ķ': "k", 'ĸ': "k", 'Ĺ': "L",
+	'ĺ': "l", 'Ļ': "L", 'ļ': "l", 'Ľ': "L", 'ľ': "l", 'Ŀ': "L", 'ŀ': "l", 'Ł': "L",
+	'ł': "l", 'Ń': "N", 'ń': "n", 'Ņ': "N", 'ņ': "n", 'Ň': "N", 'ň': "n", 'Ŋ': "N",
+	'ŋ': "n", 'Ō': "O", 'ō': "o", 'Ŏ': "O", 'ŏ': "o", 'Ő': "O", 'ő': "o", 'Œ': "OE",
+	'œ': "oe", 'Ŕ': "R", 'ŕ': "r", 'Ŗ': "R", 'ŗ': "r", 'Ř': "R", 'ř': "r", 'Ś': "S",
+	'ś': "s", 'Ŝ': "S", 'ŝ': "s", 'Ş': "S", 'ş': "s", 'Š': "S", 'š': "s", 'Ţ': "T",
+	'ţ': "t", 'Ť': "T", 'ť': "t", 'Ŧ': "T", 'ŧ': "t", 'Ũ': "U", 'ũ': "u", 'Ū': "U",
+	'ū': "u", 'Ŭ': "U", 'ŭ': "u", 'Ů': "U", 'ů': "u", 'Ű': "U", 'ű': "u", 'Ų': "U",
+	'ų': "u", 'Ŵ': "W", 'ŵ': "w", 'Ŷ': "Y", 'ŷ': "y", 'Ÿ': "Y", 'Ź': "Z", 'ź': "z",
+	'Ż': "Z", 'ż': "z", 'Ž': "Z", 'ž': "z", 'ſ': "s", 'Ș': "S", 'ș': "s", 'Ț': "T",
+	'ț': "t",
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '“': "\"", '”': "\"", '„': "\"", '‟': "\"",
+	'«': "\"", '»': "\"", '‹': "'", '›': "'", '‐': "-", '‑': "-", '–': "-", '—': "-",
+	'―': "-", '…': "...", '\u00A0': " ", '\u2009': " ", '\u202F': " ", '•': "*", '′': "'", '″': "\"",
+}
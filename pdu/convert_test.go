@@ -0,0 +1,36 @@
+package pdu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert7Bit(t *testing.T) {
+	t.Parallel()
+
+	const text = "Zażółć gęślą jaźń"
+	str, err := Convert7Bit(text, Replace)
+	require.NoError(t, err)
+	assert.Equal(t, text, str)
+
+	_, err = Convert7Bit(text, Strict)
+	assert.ErrorIs(t, err, ErrUnencodable)
+	str, err = Convert7Bit("hello[world]", Strict)
+	require.NoError(t, err)
+	assert.Equal(t, "hello[world]", str)
+
+	str, err = Convert7Bit(text, Lossy)
+	require.NoError(t, err)
+	assert.Equal(t, "Zazolc gesla jazn", str)
+	str, err = Convert7Bit("“Déjà vu” — naïve… привет", Lossy)
+	require.NoError(t, err)
+	assert.Equal(t, "\"Déjà vu\" - naive... ??????", str)
+	assert.True(t, Is7BitEncodable(str))
+
+	// the characters of the national tables are kept
+	str, err = Tables{Single: TurkishAlphabet}.Convert("Şifre ą", Lossy)
+	require.NoError(t, err)
+	assert.Equal(t, "Şifre a", str)
+}
@@ -4,4 +4,7 @@
 //  - GSM 7-Bit text encoding,
 //  - UCS2 (UTF-16) text encoding, and
 //  - semi-octet encoding of integers.
+//
+// The GSM 7-bit alphabets are generated from the data files in the alphabets
+// directory by running go generate.
 package pdu
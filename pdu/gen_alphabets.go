@@ -0,0 +1,155 @@
+//go:build ignore
+// +build ignore
+
+// This program generates alphabets.go from the tables in the alphabets directory.
+// Each <name>.txt file holds the 128 characters of an alphabet and the optional
+// <name>_shift.txt file holds its extension table reached through the escape code.
+// The national languages without a locking shift table have only the <name>_shift.txt
+// file, the characters of the default alphabet are used for them.
+//
+// Run it with go generate.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	shiftSuffix  = "_shift"
+	defaultTable = "default"
+)
+
+type entry struct {
+	septet byte
+	code   rune
+	name   string
+}
+
+func main() {
+	files, err := filepath.Glob(filepath.Join("alphabets", "*.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_alphabets.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package pdu\n\n")
+	var names []string
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".txt"), shiftSuffix)
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		file := filepath.Join("alphabets", name+".txt")
+		locking := true
+		if _, err := os.Stat(file); err != nil {
+			file, locking = filepath.Join("alphabets", defaultTable+".txt"), false
+		}
+		basic, err := readTable(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(basic) != 0x80 {
+			log.Fatalf("%s: want 128 septets, got %d", file, len(basic))
+		}
+		shiftFile := filepath.Join("alphabets", name+shiftSuffix+".txt")
+		var shift []entry
+		if _, err := os.Stat(shiftFile); err == nil {
+			if shift, err = readTable(shiftFile); err != nil {
+				log.Fatal(err)
+			}
+		}
+		writeAlphabet(&buf, name, basic, shift, locking)
+	}
+	buf.WriteString("// alphabets lists all the known alphabets.\n")
+	buf.WriteString("var alphabets = []*Alphabet{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%s,\n", varName(name))
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("alphabets.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func varName(name string) string {
+	return strings.Title(name) + "Alphabet"
+}
+
+func writeAlphabet(buf *bytes.Buffer, name string, basic, shift []entry, locking bool) {
+	if locking {
+		fmt.Fprintf(buf, "// %s is the GSM 7-bit %s alphabet.\n", varName(name), name)
+	} else {
+		fmt.Fprintf(buf, "// %s is the GSM 7-bit %s alphabet, it has no locking shift table.\n", varName(name), name)
+	}
+	fmt.Fprintf(buf, "var %s = &Alphabet{\n", varName(name))
+	fmt.Fprintf(buf, "\tName: %q,\n", name)
+	buf.WriteString("\tbasic: runeTable{\n")
+	for i, e := range basic {
+		if int(e.septet) != i {
+			log.Fatalf("%s: septet 0x%02X is out of order", name, e.septet)
+		}
+		fmt.Fprintf(buf, "\t\t/* 0x%02X */ 0x%04X, /* %s */\n", e.septet, e.code, e.name)
+	}
+	buf.WriteString("\t},\n")
+	buf.WriteString("\tshift: escapeTable{\n")
+	for _, e := range shift {
+		fmt.Fprintf(buf, "\t\t{0x%02X, 0x%04X}, /* %s */\n", e.septet, e.code, e.name)
+	}
+	buf.WriteString("\t},\n")
+	buf.WriteString("}\n\n")
+}
+
+// readTable reads the lines of "<septet> <code point> <name>", skipping
+// the blank lines and comments.
+func readTable(file string) ([]entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var table []entry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%s:%d: malformed line", file, n)
+		}
+		septet, err := strconv.ParseUint(fields[0], 0, 7)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+		}
+		code, err := strconv.ParseUint(fields[1], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+		}
+		table = append(table, entry{
+			septet: byte(septet),
+			code:   rune(code),
+			name:   strings.Join(fields[2:], " "),
+		})
+	}
+	return table, scanner.Err()
+}
@@ -1,6 +1,6 @@
 package pdu
 
-import "fmt"
+import "strings"
 
 // Swap semi-octets in octet.
 func Swap(octet byte) byte {
@@ -64,17 +64,46 @@ func DecodeSemi(octets []byte) []int {
 	return chunks
 }
 
+// EncodeSemiAddress packs the digits of a phone number in a semi-octet representation,
+// unlike EncodeSemi the leading zeros are kept. The characters other than digits,
+// '*' and '#' are skipped.
+func EncodeSemiAddress(str string) []byte {
+	digits := make([]byte, 0, len(str))
+	for _, r := range str {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r-'0'))
+		case r == '*' || r == '#':
+			digits = append(digits, byte(strings.IndexRune(semiDigits, r)))
+		}
+	}
+	octets := make([]byte, 0, len(digits)/2+1)
+	for i := 0; i < len(digits); i += 2 {
+		if len(digits)-i < 2 {
+			octets = append(octets, 0xF0|digits[i])
+			break
+		}
+		octets = append(octets, digits[i+1]<<4|digits[i])
+	}
+	return octets
+}
+
+// semiDigits are the characters of the address semi-octets, 3GPP TS 23.040 section 9.1.2.3.
+const semiDigits = "0123456789*#abc"
+
 // DecodeSemiAddress unpacks phone numbers from the given semi-octet encoded data.
 // This method is different from DecodeSemi because a 0x00 byte should be interpreted as
 // two distinct digits. There 0x00 will be "00".
 func DecodeSemiAddress(octets []byte) (str string) {
 	for _, oct := range octets {
-		half := oct >> 4
-		if half == 0xF {
-			str += fmt.Sprintf("%d", oct&0x0F)
+		lo, hi := oct&0x0F, oct>>4
+		if lo < 0xF {
+			str += string(semiDigits[lo])
+		}
+		if hi == 0xF {
 			return
 		}
-		str += fmt.Sprintf("%d%d", oct&0x0F, half)
+		str += string(semiDigits[hi])
 	}
 	return
 }
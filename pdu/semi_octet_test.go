@@ -22,3 +22,11 @@ func TestDecodeSemi(t *testing.T) {
 	exp := []int{14, 6, 26, 21, 36, 30, 16}
 	assert.Equal(t, exp, out)
 }
+
+func TestSemiAddress(t *testing.T) {
+	t.Parallel()
+
+	oct := EncodeSemiAddress("0012*#5")
+	assert.Equal(t, []byte{0x00, 0x21, 0xBA, 0xF5}, oct)
+	assert.Equal(t, "0012*#5", DecodeSemiAddress(oct))
+}
@@ -0,0 +1,40 @@
+package at
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPortableBuild checks that the module builds without cgo for the router targets
+// with no FPU, i.e. the OpenWrt ones.
+func TestPortableBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("cross-compiling is slow")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not available")
+	}
+	targets := []struct {
+		goarch string
+		env    string
+	}{
+		{"arm", "GOARM=5"},
+		{"mips", "GOMIPS=softfloat"},
+		{"mipsle", "GOMIPS=softfloat"},
+	}
+	for _, target := range targets {
+		target := target
+		t.Run(target.goarch, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := exec.Command(goBin, "build", "./...")
+			cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH="+target.goarch, target.env)
+			out, err := cmd.CombinedOutput()
+			assert.NoError(t, err, string(out))
+		})
+	}
+}
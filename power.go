@@ -0,0 +1,151 @@
+package at
+
+import (
+	"fmt"
+	"time"
+)
+
+// powerCommander is implemented by profiles that are able to control the modem's power modes.
+type powerCommander interface {
+	CFUN(level Opt) (err error)
+	CSCLK(enable bool) (err error)
+}
+
+// CFUN sends AT+CFUN with the given level to the device, see FunctionalityLevels.
+func (p *DefaultProfile) CFUN(level Opt) (err error) {
+	req := fmt.Sprintf(`AT+CFUN=%d`, level.ID)
+	_, err = p.dev.Send(req)
+	return
+}
+
+// CSCLK sends AT+CSCLK with the given value to the device. When the slow clock is enabled,
+// the module enters the sleep mode if the DTR line is high and the port is idle (SIMCom modules).
+func (p *DefaultProfile) CSCLK(enable bool) (err error) {
+	var flag int
+	if enable {
+		flag = 1
+	}
+	req := fmt.Sprintf(`AT+CSCLK=%d`, flag)
+	_, err = p.dev.Send(req)
+	return
+}
+
+// SleepConfig describes how the modem is put into the low power mode.
+type SleepConfig struct {
+	// Level is the functionality level to set, i.e. FunctionalityLevels.Minimum.
+	// The zero value keeps the current level.
+	Level Opt
+	// SlowClock enables the slow clock mode, see CSCLK.
+	SlowClock bool
+	// WakeOn wakes up the device when the modem signals its activity, i.e. with
+	// the RingIndicator. The device sleeps until Wake is called if not set.
+	WakeOn WakeSource
+}
+
+// Sleep puts the modem into the low power mode and closes the ports. Watch keeps
+// waiting while the device sleeps and continues after Wake.
+func (d *Device) Sleep(cfg SleepConfig) error {
+	p, ok := d.Commands.(powerCommander)
+	if !ok {
+		return ErrUnsupported
+	}
+	if cfg.Level.Description != "" {
+		if err := p.CFUN(cfg.Level); err != nil {
+			return fmt.Errorf("at sleep: unable to set the functionality level: %w", err)
+		}
+	}
+	if cfg.SlowClock {
+		if err := p.CSCLK(true); err != nil {
+			return fmt.Errorf("at sleep: unable to enable the slow clock: %w", err)
+		}
+	}
+	d.powerMux.Lock()
+	d.awake = make(chan struct{})
+	awake := d.awake
+	d.powerMux.Unlock()
+	if cfg.WakeOn != nil {
+		defer func() {
+			go d.watchWake(cfg.WakeOn, awake)
+		}()
+	}
+
+	d.cmdMux.Lock()
+	defer d.cmdMux.Unlock()
+	return d.closePorts()
+}
+
+// Wake reopens the ports of a sleeping device, restores the full functionality
+// and runs the profile's Init again.
+func (d *Device) Wake() error {
+	d.cmdMux.Lock()
+	err := d.Open()
+	d.cmdMux.Unlock()
+	if err != nil {
+		return err
+	}
+	if p, ok := d.Commands.(powerCommander); ok {
+		p.CSCLK(false) // may be unsupported, ignore errors
+		if err = p.CFUN(FunctionalityLevels.Full); err != nil {
+			return fmt.Errorf("at wake: unable to restore the functionality level: %w", err)
+		}
+	}
+	if err = d.initProfile(d.Commands); err != nil {
+		return err
+	}
+
+	d.resetFailures()
+	d.powerMux.Lock()
+	if d.awake != nil {
+		close(d.awake)
+		d.awake = nil
+	}
+	d.powerMux.Unlock()
+	return nil
+}
+
+// asleep returns a channel that is closed when the device wakes up,
+// or nil if the device is not sleeping.
+func (d *Device) asleep() <-chan struct{} {
+	d.powerMux.Lock()
+	defer d.powerMux.Unlock()
+	return d.awake
+}
+
+// DutyCycle runs the device periodically: it stays awake for the given period, then sleeps
+// until the interval passes, the trigger fires (the trigger may be nil) or the modem
+// signals its activity (see SleepConfig.WakeOn).
+// Blocks until the device is closed, or returns the error if sleep or wake fails.
+func (d *Device) DutyCycle(awake, asleep time.Duration, cfg SleepConfig, trigger <-chan struct{}) error {
+	t := time.NewTimer(awake)
+	defer t.Stop()
+	for {
+		select {
+		case <-d.closed:
+			return nil
+		case <-t.C:
+		}
+		if err := d.Sleep(cfg); err != nil {
+			return err
+		}
+		t.Reset(asleep)
+		woken := d.asleep()
+		select {
+		case <-d.closed:
+			return nil
+		case <-t.C:
+		case <-trigger:
+			if !t.Stop() {
+				<-t.C
+			}
+		case <-woken:
+			// woken up by the modem, see SleepConfig.WakeOn
+			if !t.Stop() {
+				<-t.C
+			}
+		}
+		if err := d.wakeIfAsleep(); err != nil {
+			return err
+		}
+		t.Reset(awake)
+	}
+}
@@ -0,0 +1,84 @@
+package at
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSleepWake(t *testing.T) {
+	t.Parallel()
+
+	td := &testDialer{replies: map[string]string{
+		"AT+CFUN=0": "OK",
+		"AT+CFUN=1": "OK",
+	}}
+	dev := &Device{
+		CommandPort: "cmd",
+		NotifyPort:  "notify",
+		Dial:        td.Dial,
+		Timeout:     time.Second,
+	}
+	require.NoError(t, dev.Open())
+	require.NoError(t, dev.Init(&noopProfile{}))
+	defer dev.Close()
+	watching := make(chan struct{})
+	go func() {
+		dev.Watch()
+		close(watching)
+	}()
+
+	require.NoError(t, dev.Sleep(SleepConfig{Level: FunctionalityLevels.Minimum}))
+	_, err := dev.Send(NoopCmd)
+	assert.Error(t, err)
+
+	require.NoError(t, dev.Wake())
+	_, err = dev.Send(NoopCmd)
+	assert.NoError(t, err)
+
+	select {
+	case <-watching:
+		t.Fatal("watch has stopped during sleep")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestWakeOn(t *testing.T) {
+	t.Parallel()
+
+	td := &testDialer{replies: map[string]string{
+		"AT+CFUN=1": "OK",
+	}}
+	dev := &Device{
+		CommandPort: "cmd",
+		NotifyPort:  "notify",
+		Dial:        td.Dial,
+		Timeout:     time.Second,
+	}
+	require.NoError(t, dev.Open())
+	require.NoError(t, dev.Init(&noopProfile{}))
+	defer dev.Close()
+
+	ring := make(chan struct{})
+	require.NoError(t, dev.Sleep(SleepConfig{
+		WakeOn: WakeFunc(func(stop <-chan struct{}) error {
+			select {
+			case <-ring:
+			case <-stop:
+			}
+			return nil
+		}),
+	}))
+	awake := dev.asleep()
+	require.NotNil(t, awake)
+	close(ring)
+	select {
+	case <-awake:
+	case <-time.After(time.Second):
+		t.Fatal("the device hasn't been woken up by the ring")
+	}
+	_, err := dev.Send(NoopCmd)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,120 @@
+package at
+
+import (
+	"strings"
+	"time"
+
+	"github.com/xlab/at/pdu"
+)
+
+// PromptStage is a step of an interactive command: the payload is sent once
+// the prompt has been received.
+type PromptStage struct {
+	// Prompt is the expected reply, i.e. ">" or "CONNECT".
+	Prompt string
+	// Payload is sent after the prompt has been received.
+	Payload string
+	// Terminator is appended to the payload, i.e. Ctrl+Z. May be empty if the command
+	// expects the payload of a known length.
+	Terminator string
+}
+
+// interaction describes a command that requires a prompt to be received before
+// the payload could be entered, i.e. AT+CMGS replies with '>' and waits for the PDU.
+type interaction struct {
+	// cmd is the command that starts the interaction.
+	cmd string
+	// stages are passed in order, the device replies to the last payload
+	// as to a regular command.
+	stages []PromptStage
+	// timeout overrides the device's timeout for the whole interaction.
+	timeout time.Duration
+	// priority of the interaction in the command queue.
+	priority Priority
+	// correlationID of the message the interaction belongs to, if any.
+	correlationID string
+}
+
+// SendInteractive sends the command that requires a prompt to be received before
+// the payload could be entered. The stages are passed in order: each payload is sent
+// after its prompt has been received, i.e. the parts of a certificate chain, and
+// the reply to the last payload is parsed the same way as in Send. The intermediate
+// payloads are sent as is, without the line break.
+func (d *Device) SendInteractive(cmd string, stages ...PromptStage) (reply string, err error) {
+	if len(stages) == 0 {
+		return d.Send(cmd)
+	}
+	return d.sendInteractive(interaction{cmd: cmd, stages: stages, priority: PriorityNormal})
+}
+
+// sendInteractive is a special case of Send, but this one is used whether
+// a prompt should be received first (i.e. when sending SMS, the PDU should be
+// entered after the device replied with '>') and then the payload should be sent,
+// the reply to the payload is parsed the same way as in Send.
+func (d *Device) sendInteractive(i interaction) (reply string, err error) {
+	if err = d.sanityCheck(true); err != nil {
+		return
+	}
+	if err = d.guard(i.cmd); err != nil {
+		return
+	}
+	if err = d.cmdMux.acquire(i.priority, i.cmd, i.correlationID); err != nil {
+		return
+	}
+	defer d.cmdMux.Unlock()
+	d.pace()
+	defer func() { d.lastCommand = time.Now() }()
+
+	timeout := i.timeout
+	if timeout == 0 {
+		timeout = d.commandTimeout(i.cmd)
+	}
+	err = d.withTimeout(timeout, func() error {
+		_, err := d.cmdPort.Write([]byte(i.cmd + Sep))
+		if err != nil {
+			return d.portError(err)
+		}
+		for n, stage := range i.stages {
+			if err = d.readPrompt(stage.Prompt); err != nil {
+				// send control character to exit interactive mode
+				d.cmdPort.Write([]byte{pdu.Esc})
+				return err
+			}
+			if n == len(i.stages)-1 {
+				reply, err = d.exchange(stage.Payload + stage.Terminator)
+				return err
+			}
+			if _, err = d.cmdPort.Write([]byte(stage.Payload + stage.Terminator)); err != nil {
+				return d.portError(err)
+			}
+		}
+		return nil
+	})
+
+	return reply, err
+}
+
+// readPrompt reads the command port until the given prompt is met, the device
+// may reply with an error instead, i.e. +CMS ERROR: 304, then it's returned.
+func (d *Device) readPrompt(prompt string) error {
+	var buf, line strings.Builder
+	for {
+		b, err := d.cmdReader.ReadByte()
+		if err != nil {
+			return d.portError(err)
+		}
+		buf.WriteByte(b)
+		if strings.HasSuffix(buf.String(), prompt) {
+			return nil
+		}
+		if b != '\r' && b != '\n' {
+			line.WriteByte(b)
+			continue
+		}
+		text := strings.TrimSpace(line.String())
+		line.Reset()
+		if err = resultError(FinalResults.Resolve(text), text); err != nil {
+			return err
+		}
+	}
+}
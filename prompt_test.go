@@ -0,0 +1,36 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendInteractive(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		`AT+QFUPL="chain.pem",2`: "> ",
+		"-----CERT 1-----":       "> ",
+		"-----CERT 2-----" + Sub: "+QFUPL: 32" + Sep + Sep + "OK",
+	})
+	reply, err := dev.SendInteractive(`AT+QFUPL="chain.pem",2`,
+		PromptStage{Prompt: ">", Payload: "-----CERT 1-----", Terminator: "\r"},
+		PromptStage{Prompt: ">", Payload: "-----CERT 2-----", Terminator: Sub},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "+QFUPL: 32", reply)
+}
+
+func TestSendInteractiveError(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		`AT+QFUPL="chain.pem",2`: "+CMS ERROR: 304",
+	})
+	_, err := dev.SendInteractive(`AT+QFUPL="chain.pem",2`,
+		PromptStage{Prompt: ">", Payload: "-----CERT 1-----", Terminator: Sub},
+	)
+	assert.EqualError(t, err, "+CMS ERROR: 304")
+}
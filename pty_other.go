@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package at
+
+import "os"
+
+// openPTY is not implemented on this platform.
+func openPTY() (*os.File, string, error) {
+	return nil, "", ErrUnsupported
+}
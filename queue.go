@@ -0,0 +1,234 @@
+package at
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Priority defines the order in which the pending commands get the command port.
+// The commands of the same priority are sent in FIFO order.
+type Priority int
+
+// Command priorities, the default one is PriorityNormal.
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// MaxPreemptions is the number of times the pending commands of a lower priority could be
+// passed by the higher priority ones, then the oldest of them is sent next. It protects
+// the bulk traffic from the starvation.
+var MaxPreemptions = 10
+
+// ErrCanceled happens when a pending command is canceled before it was sent.
+var ErrCanceled = errors.New("at: command is canceled")
+
+// PendingCommand describes a command waiting for the command port.
+type PendingCommand struct {
+	// ID identifies the command in the queue.
+	ID       uint64
+	Request  string
+	Priority Priority
+	// Position is the place of the command in the queue, zero is the next to be sent.
+	Position int
+	// Queued is the time when the command was queued.
+	Queued time.Time
+	// CorrelationID of the message the command belongs to, if any.
+	CorrelationID string
+}
+
+// Age returns how long the command has been waiting.
+func (c PendingCommand) Age() time.Duration {
+	return time.Since(c.Queued)
+}
+
+// waiter is a pending holder of the command queue.
+type waiter struct {
+	ready chan struct{}
+	// err is set before the ready is closed if the waiter has been canceled.
+	err error
+	cmd PendingCommand
+}
+
+// cmdQueue is a mutex that grants the lock to the waiters with the highest priority first,
+// so the urgent commands (i.e. a hang up) don't wait behind a long series of sends.
+type cmdQueue struct {
+	mux     sync.Mutex
+	busy    bool
+	waiters [PriorityHigh - PriorityLow + 1][]*waiter
+	// preempted counts how many times the waiters of each priority have been passed.
+	preempted [PriorityHigh - PriorityLow + 1]int
+	lastID    uint64
+}
+
+// lane returns the index of the waiters of the priority, clamped to the known ones.
+func lane(prio Priority) int {
+	if prio < PriorityLow {
+		prio = PriorityLow
+	} else if prio > PriorityHigh {
+		prio = PriorityHigh
+	}
+	return int(prio - PriorityLow)
+}
+
+// Lock acquires the queue with the normal priority.
+func (q *cmdQueue) Lock() {
+	q.LockPriority(PriorityNormal)
+}
+
+// LockPriority acquires the queue, waiting behind the holders of the same or higher priority.
+func (q *cmdQueue) LockPriority(prio Priority) {
+	// the waiters without a request can't be canceled
+	q.acquire(prio, "", "")
+}
+
+// acquire is like LockPriority, but the waiter is listed as a pending command with
+// the request, unless it's empty. It returns ErrCanceled if the command was canceled,
+// the queue is not acquired then.
+func (q *cmdQueue) acquire(prio Priority, req, correlationID string) error {
+	q.mux.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mux.Unlock()
+		return nil
+	}
+	q.lastID++
+	w := &waiter{
+		ready: make(chan struct{}),
+		cmd: PendingCommand{
+			ID:            q.lastID,
+			Request:       req,
+			Priority:      prio,
+			Queued:        time.Now(),
+			CorrelationID: correlationID,
+		},
+	}
+	i := lane(prio)
+	q.waiters[i] = append(q.waiters[i], w)
+	q.mux.Unlock()
+	<-w.ready
+	return w.err
+}
+
+// Unlock passes the queue to the next waiter with the highest priority, unless
+// a lower priority has been preempted MaxPreemptions times in a row.
+func (q *cmdQueue) Unlock() {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	next := -1
+	for i := len(q.waiters) - 1; i >= 0; i-- {
+		if len(q.waiters[i]) == 0 {
+			continue
+		}
+		if next < 0 {
+			next = i
+		}
+		if q.preempted[i] >= MaxPreemptions {
+			// the starving lanes are checked after the first non-empty, the lowest one wins
+			next = i
+		}
+	}
+	if next < 0 {
+		q.busy = false
+		return
+	}
+	for i := range q.waiters {
+		if i < next && len(q.waiters[i]) > 0 {
+			q.preempted[i]++
+		}
+	}
+	q.preempted[next] = 0
+	waiters := q.waiters[next]
+	q.waiters[next] = waiters[1:]
+	close(waiters[0].ready) // the lock is handed over, it stays busy
+}
+
+// pending lists the waiters with a request from the next to be sent, regardless
+// of the starvation protection.
+func (q *cmdQueue) pending() []PendingCommand {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	var cmds []PendingCommand
+	for i := len(q.waiters) - 1; i >= 0; i-- {
+		for _, w := range q.waiters[i] {
+			if w.cmd.Request == "" {
+				continue
+			}
+			cmd := w.cmd
+			cmd.Position = len(cmds)
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// remove takes the waiter with the request and the id out of the queue.
+// The caller must hold the mux.
+func (q *cmdQueue) remove(id uint64) *waiter {
+	for i, waiters := range q.waiters {
+		for j, w := range waiters {
+			if w.cmd.ID == id && w.cmd.Request != "" {
+				q.waiters[i] = append(waiters[:j:j], waiters[j+1:]...)
+				return w
+			}
+		}
+	}
+	return nil
+}
+
+// cancel fails the pending command with ErrCanceled.
+func (q *cmdQueue) cancel(id uint64) bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	w := q.remove(id)
+	if w == nil {
+		return false
+	}
+	w.err = ErrCanceled
+	close(w.ready)
+	return true
+}
+
+// reprioritize moves the pending command to the end of the priority's lane.
+func (q *cmdQueue) reprioritize(id uint64, prio Priority) bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	w := q.remove(id)
+	if w == nil {
+		return false
+	}
+	w.cmd.Priority = prio
+	i := lane(prio)
+	q.waiters[i] = append(q.waiters[i], w)
+	return true
+}
+
+// Pending lists the commands waiting for the command port, in the order they're going
+// to be sent. The internal holders of the port, i.e. the watchdog, are not listed.
+func (d *Device) Pending() []PendingCommand {
+	return d.cmdMux.pending()
+}
+
+// CancelPending cancels the pending command, it fails with ErrCanceled.
+// It returns false if the command is not pending anymore.
+func (d *Device) CancelPending(id uint64) bool {
+	return d.cmdMux.cancel(id)
+}
+
+// Reprioritize changes the priority of the pending command, it's queued after the
+// commands of the new priority. It returns false if the command is not pending anymore.
+func (d *Device) Reprioritize(id uint64, prio Priority) bool {
+	return d.cmdMux.reprioritize(id, prio)
+}
+
+// FlushPending cancels all the pending commands and returns their number.
+func (d *Device) FlushPending() (n int) {
+	for _, cmd := range d.cmdMux.pending() {
+		if d.cmdMux.cancel(cmd.ID) {
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,114 @@
+package at
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdQueue(t *testing.T) {
+	t.Parallel()
+
+	var q cmdQueue
+	var order []Priority
+	var orderMux sync.Mutex
+	var wg sync.WaitGroup
+
+	q.Lock()
+	for i, prio := range []Priority{PriorityLow, PriorityNormal, PriorityLow, PriorityHigh} {
+		wg.Add(1)
+		go func(prio Priority) {
+			defer wg.Done()
+			q.LockPriority(prio)
+			orderMux.Lock()
+			order = append(order, prio)
+			orderMux.Unlock()
+			q.Unlock()
+		}(prio)
+		// wait until the goroutine is queued
+		for queued := 0; queued <= i; {
+			time.Sleep(time.Millisecond)
+			q.mux.Lock()
+			queued = 0
+			for _, waiters := range q.waiters {
+				queued += len(waiters)
+			}
+			q.mux.Unlock()
+		}
+	}
+	q.Unlock()
+	wg.Wait()
+
+	assert.Equal(t, []Priority{PriorityHigh, PriorityNormal, PriorityLow, PriorityLow}, order)
+}
+
+func TestCmdQueueStarvation(t *testing.T) {
+	t.Parallel()
+
+	var q cmdQueue
+	q.Lock()
+	low := &waiter{ready: make(chan struct{})}
+	q.waiters[0] = append(q.waiters[0], low)
+	high := make([]*waiter, MaxPreemptions+1)
+	for i := range high {
+		high[i] = &waiter{ready: make(chan struct{})}
+	}
+	q.waiters[PriorityHigh-PriorityLow] = append(q.waiters[PriorityHigh-PriorityLow], high...)
+
+	closed := func(w *waiter) bool {
+		select {
+		case <-w.ready:
+			return true
+		default:
+			return false
+		}
+	}
+	for i := 0; i < MaxPreemptions; i++ {
+		q.Unlock()
+		assert.True(t, closed(high[i]))
+		assert.False(t, closed(low))
+	}
+	q.Unlock()
+	assert.True(t, closed(low), "the low priority waiter starves")
+	assert.False(t, closed(high[MaxPreemptions]))
+}
+
+func TestPendingCommands(t *testing.T) {
+	t.Parallel()
+
+	d := &Device{}
+	d.cmdMux.Lock()
+	results := make(chan error, 3)
+	for i, req := range []string{"AT+CSQ", "AT+CMGS=23", "AT+COPS?"} {
+		go func(req string) {
+			err := d.cmdMux.acquire(PriorityNormal, req, req+"-id")
+			if err == nil {
+				d.cmdMux.Unlock()
+			}
+			results <- err
+		}(req)
+		for len(d.Pending()) <= i {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	pending := d.Pending()
+	assert.Equal(t, "AT+CSQ", pending[0].Request)
+	assert.Equal(t, "AT+CMGS=23-id", pending[1].CorrelationID)
+	assert.Equal(t, 2, pending[2].Position)
+	assert.True(t, d.Reprioritize(pending[2].ID, PriorityHigh))
+	pending = d.Pending()
+	assert.Equal(t, "AT+COPS?", pending[0].Request)
+	assert.Equal(t, PriorityHigh, pending[0].Priority)
+
+	assert.True(t, d.CancelPending(pending[1].ID))
+	assert.ErrorIs(t, <-results, ErrCanceled)
+	assert.False(t, d.CancelPending(pending[1].ID))
+	assert.Equal(t, 2, d.FlushPending())
+	assert.ErrorIs(t, <-results, ErrCanceled)
+	assert.ErrorIs(t, <-results, ErrCanceled)
+	assert.Empty(t, d.Pending())
+	d.cmdMux.Unlock()
+}
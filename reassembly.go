@@ -0,0 +1,120 @@
+package at
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// DefaultReassemblyTimeout is the time the parts of a concatenated message are waited for.
+const DefaultReassemblyTimeout = 2 * time.Minute
+
+// partsKey identifies a concatenated message.
+type partsKey struct {
+	address sms.PhoneNumber
+	tag     int
+	total   int
+}
+
+// partial is a concatenated message waiting for its parts.
+type partial struct {
+	parts    []*sms.Message
+	received int
+	timer    *time.Timer
+}
+
+// partOf reports whether the message is a part of a concatenated message to be reassembled.
+func (d *Device) partOf(msg *sms.Message) bool {
+	udh := msg.UserDataHeader
+	return d.ReassemblyTimeout >= 0 && msg.Type == sms.MessageTypes.Deliver && msg.UserDataStartsWithHeader &&
+		udh.TotalNumber >= 2 && udh.Sequence >= 1 && udh.Sequence <= udh.TotalNumber
+}
+
+// reassemble buffers the part of a concatenated message and returns the message along
+// with its parts once all of them are received. The other messages are returned as is.
+func (d *Device) reassemble(msg *sms.Message) (*sms.Message, []*sms.Message) {
+	if !d.partOf(msg) {
+		return msg, nil
+	}
+	udh := msg.UserDataHeader
+	key := partsKey{address: msg.Address, tag: udh.Tag, total: udh.TotalNumber}
+
+	d.partsMux.Lock()
+	defer d.partsMux.Unlock()
+	if d.partials == nil {
+		d.partials = make(map[partsKey]*partial)
+	}
+	p, ok := d.partials[key]
+	if !ok {
+		timeout := d.ReassemblyTimeout
+		if timeout == 0 {
+			timeout = DefaultReassemblyTimeout
+		}
+		p = &partial{parts: make([]*sms.Message, udh.TotalNumber)}
+		p.timer = time.AfterFunc(timeout, func() { d.expireParts(key, p) })
+		d.partials[key] = p
+	}
+	if p.parts[udh.Sequence-1] == nil {
+		p.received++
+	}
+	p.parts[udh.Sequence-1] = msg
+	if p.received < len(p.parts) {
+		return nil, nil
+	}
+	p.timer.Stop()
+	delete(d.partials, key)
+
+	texts := make([]string, len(p.parts))
+	var data []byte
+	for i, part := range p.parts {
+		texts[i] = part.Text
+		data = append(data, part.Data...)
+	}
+	complete := *p.parts[0]
+	complete.Text = strings.Join(texts, "")
+	complete.Data = data
+	// the application ports are kept, i.e. for a WAP Push
+	complete.UserDataStartsWithHeader = udh.DestinationPort != 0
+	complete.UserDataHeader = sms.UserDataHeader{
+		DestinationPort: udh.DestinationPort,
+		SourcePort:      udh.SourcePort,
+	}
+	return &complete, p.parts
+}
+
+// reassembled buffers the part, which is already persisted, and delivers the message once
+// it's complete. The complete message is persisted and the parts are marked as processed then.
+func (d *Device) reassembled(ctx context.Context, part *sms.Message) error {
+	complete, parts := d.reassemble(part)
+	if complete == nil {
+		return nil
+	}
+	if err := d.persist(complete); err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if err := d.MarkProcessed(part); err != nil {
+			return err
+		}
+	}
+	return d.deliver(ctx, complete, nil)
+}
+
+// expireParts delivers the received parts of the incomplete message separately. The parts
+// that fail are left in the MessageStore, they're delivered again by Redeliver.
+func (d *Device) expireParts(key partsKey, p *partial) {
+	d.partsMux.Lock()
+	if d.partials[key] != p {
+		d.partsMux.Unlock()
+		return
+	}
+	delete(d.partials, key)
+	d.partsMux.Unlock()
+	for _, part := range p.parts {
+		if part != nil {
+			d.deliver(d.session(), part, nil)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package at
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+)
+
+// complete drops the parts returned by reassemble.
+func complete(msg *sms.Message, _ []*sms.Message) *sms.Message {
+	return msg
+}
+
+func TestReassemble(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("long text ", 40)
+	msg := sms.Message{
+		Type:     sms.MessageTypes.Deliver,
+		Encoding: sms.Encodings.Gsm7Bit,
+		Address:  "+79269965690",
+		Text:     text,
+	}
+	parts, err := msg.Split(5)
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+
+	d := &Device{messages: make(chan *sms.Message, 10)}
+	assert.Nil(t, complete(d.reassemble(&parts[2])))
+	assert.Nil(t, complete(d.reassemble(&parts[0])))
+	assert.Nil(t, complete(d.reassemble(&parts[0])), "the duplicates should be ignored")
+	long, received := d.reassemble(&parts[1])
+	if assert.NotNil(t, long) {
+		assert.Equal(t, text, long.Text)
+		assert.False(t, long.UserDataStartsWithHeader)
+		assert.Equal(t, []*sms.Message{&parts[0], &parts[1], &parts[2]}, received)
+	}
+	assert.Empty(t, d.partials)
+
+	single := &sms.Message{Type: sms.MessageTypes.Deliver, Text: "hello"}
+	assert.Equal(t, single, complete(d.reassemble(single)))
+}
+
+func TestReassembleData(t *testing.T) {
+	t.Parallel()
+
+	part := func(seq int, data string) *sms.Message {
+		return &sms.Message{
+			Type:                     sms.MessageTypes.Deliver,
+			Encoding:                 sms.Encodings.Data8Bit,
+			Address:                  "+79269965690",
+			Data:                     []byte(data),
+			UserDataStartsWithHeader: true,
+			UserDataHeader: sms.UserDataHeader{
+				Tag: 3, TotalNumber: 2, Sequence: seq,
+				DestinationPort: sms.WAPPushPort, SourcePort: 9200,
+			},
+		}
+	}
+	d := &Device{messages: make(chan *sms.Message, 10)}
+	assert.Nil(t, complete(d.reassemble(part(2, "push"))))
+	msg := complete(d.reassemble(part(1, "wap ")))
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, []byte("wap push"), msg.Data)
+		assert.True(t, msg.UserDataStartsWithHeader)
+		assert.Equal(t, sms.UserDataHeader{DestinationPort: sms.WAPPushPort, SourcePort: 9200}, msg.UserDataHeader)
+	}
+}
+
+func TestReassemblyTimeout(t *testing.T) {
+	t.Parallel()
+
+	msg := sms.Message{
+		Type:     sms.MessageTypes.Deliver,
+		Encoding: sms.Encodings.Gsm7Bit,
+		Address:  "+79269965690",
+		Text:     strings.Repeat("long text ", 40),
+	}
+	parts, err := msg.Split(6)
+	require.NoError(t, err)
+
+	d := &Device{
+		ReassemblyTimeout: 10 * time.Millisecond,
+		messages:          make(chan *sms.Message, 10),
+	}
+	assert.Nil(t, complete(d.reassemble(&parts[0])))
+	assert.Nil(t, complete(d.reassemble(&parts[2])))
+	select {
+	case part := <-d.messages:
+		assert.Equal(t, parts[0].Text, part.Text)
+	case <-time.After(time.Second):
+		t.Fatal("the parts were not delivered")
+	}
+	assert.Equal(t, parts[2].Text, (<-d.messages).Text)
+}
+
+func TestReassemblyStore(t *testing.T) {
+	t.Parallel()
+
+	msg := sms.Message{
+		Type:     sms.MessageTypes.Deliver,
+		Encoding: sms.Encodings.Gsm7Bit,
+		Address:  "+79269965690",
+		Text:     strings.Repeat("long text ", 40),
+	}
+	parts, err := msg.Split(7)
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+
+	store := &memoryMessageStore{processed: make(map[string]bool)}
+	dev, _ := newTestDevice(t, nil)
+	dev.MessageStore = store
+	ctx := context.Background()
+
+	var commits int
+	commit := func() error {
+		commits++
+		return nil
+	}
+	require.NoError(t, dev.receiveMessage(ctx, &parts[0], commit))
+	require.NoError(t, dev.receiveMessage(ctx, &parts[1], commit))
+	assert.Equal(t, 2, commits, "the parts should be removed from the device once stored")
+	list, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	// a restart: the stored parts wait for the rest
+	restarted, _ := newTestDevice(t, nil)
+	restarted.MessageStore = store
+	require.NoError(t, restarted.Redeliver(ctx))
+	assert.Empty(t, restarted.IncomingSms())
+	require.NoError(t, restarted.receiveMessage(ctx, &parts[2], nil))
+	long := <-restarted.IncomingSms()
+	assert.Equal(t, msg.Text, long.Text)
+	require.NoError(t, restarted.MarkProcessed(long))
+	list, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
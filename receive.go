@@ -0,0 +1,76 @@
+package at
+
+import (
+	"context"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// receive is the common pipeline of the incoming messages, either read from the storage
+// after +CMTI, +CDSI or AT+CMGL, or routed directly to the terminal as +CMT and +CDS.
+// The duplicates are dropped, the status reports update the deliveries, the canaries
+// are caught, the message waiting indications are reported and the parts of a concatenated
+// message are delivered with the last one.
+// The messages and the parts are saved to the MessageStore first, if set, the delivered
+// messages are classified by the Classifier.
+//
+// The commit, if not nil, removes the message from the storage of the device. It's called
+// only once the message is handled, so a failed message stays in the storage.
+func (d *Device) receive(ctx context.Context, octets []byte, commit func() error) error {
+	var msg sms.Message
+	if _, err := msg.ReadFrom(octets); err != nil {
+		return err
+	}
+	return d.receiveMessage(ctx, &msg, commit)
+}
+
+// receiveMessage is like receive, but the message is already decoded, i.e. read
+// in the text mode.
+func (d *Device) receiveMessage(ctx context.Context, msg *sms.Message, commit func() error) error {
+	if d.duplicate(msg, time.Now()) {
+		return committed(commit)
+	}
+	var handled bool
+	err := d.handle(ctx, msg, func() error {
+		handled = true
+		return committed(commit)
+	})
+	if err != nil && !handled {
+		// the message stays in the storage and is read again
+		d.forget(msg)
+	}
+	return err
+}
+
+// handle passes the message that is not a duplicate through the pipeline.
+func (d *Device) handle(ctx context.Context, msg *sms.Message, commit func() error) error {
+	if msg.Type == sms.MessageTypes.StatusReport {
+		d.delivered(msg)
+	}
+	if caught(msg) {
+		return committed(commit)
+	}
+	if indications := msg.MessageWaiting(); len(indications) > 0 && !d.messageWaiting(msg, indications) {
+		return committed(commit)
+	}
+	if err := d.persist(msg); err != nil {
+		return err
+	}
+	if d.partOf(msg) {
+		// the part is kept in the MessageStore while it waits for the others
+		if err := committed(commit); err != nil {
+			return err
+		}
+		return d.reassembled(ctx, msg)
+	}
+	return d.deliver(ctx, msg, commit)
+}
+
+// committed calls the commit, if any.
+func committed(commit func() error) error {
+	if commit == nil {
+		return nil
+	}
+	return commit()
+}
@@ -0,0 +1,87 @@
+package at
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+	"github.com/xlab/at/util"
+)
+
+func TestReceive(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGR=1":   "+CMGR: 0,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGD=1,0": "OK",
+		"AT+CPMS?":    `+CPMS: "SM",1,5,"SM",1,5,"SM",1,5` + Sep + Sep + "OK",
+	})
+
+	require.NoError(t, dev.handleReport(`+CMTI: "SM",1`))
+	require.NoError(t, dev.handleReport("+CMT: ,25\n"+pdu))
+	require.NoError(t, dev.Commands.FetchInbox())
+	require.Len(t, dev.IncomingSms(), 3)
+	stored := <-dev.IncomingSms()
+	assert.Equal(t, stored, <-dev.IncomingSms(), "+CMTI and +CMT are handled the same")
+	assert.Equal(t, stored, <-dev.IncomingSms(), "+CMTI and FetchInbox are handled the same")
+}
+
+func TestDuplicates(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, nil)
+	dev.DuplicateWindow = time.Minute
+
+	require.NoError(t, dev.handleReport("+CMT: ,25\n"+pdu))
+	require.NoError(t, dev.handleReport("+CMT: ,25\n"+pdu))
+	assert.Len(t, dev.IncomingSms(), 1, "the duplicate is dropped")
+
+	var msg sms.Message
+	_, err := msg.ReadFrom(util.MustBytes(pdu))
+	require.NoError(t, err)
+	now := time.Now()
+	assert.True(t, dev.duplicate(&msg, now))
+	assert.False(t, dev.duplicate(&msg, now.Add(time.Minute)), "the window is over")
+	msg.UserDataStartsWithHeader = true
+	msg.UserDataHeader = sms.UserDataHeader{Tag: 1, TotalNumber: 2, Sequence: 2}
+	assert.False(t, dev.duplicate(&msg, now.Add(time.Minute)), "another part")
+}
+
+func TestDuplicatesFailedDelivery(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGR=1":   "+CMGR: 0,,25" + Sep + pdu + Sep + Sep + "OK",
+		"AT+CMGD=1,0": "OK",
+	})
+	dev.DuplicateWindow = time.Minute
+	dev.MessageStore = &failingMessageStore{}
+
+	assert.ErrorIs(t, dev.handleReport(`+CMTI: "SM",1`), errStoreDown)
+	assert.Empty(t, dev.IncomingSms())
+	dev.MessageStore = &memoryMessageStore{processed: make(map[string]bool)}
+	require.NoError(t, dev.handleReport(`+CMTI: "SM",1`))
+	assert.Len(t, dev.IncomingSms(), 1, "the re-read message is not a duplicate")
+}
+
+func TestReceiveInboxPolicy(t *testing.T) {
+	t.Parallel()
+
+	const pdu = "07919762020033F1040B919762995696F0000041606291401561066379180E8200"
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGR=1": "+CMGR: 0,,25" + Sep + pdu + Sep + Sep + "OK",
+		// AT+CMGD would fail
+	})
+	dev.Commands.(*noopProfile).InboxPolicy = InboxPolicies.Leave
+
+	require.NoError(t, dev.handleReport(`+CMTI: "SM",1`), "the message is left in the storage")
+	assert.Len(t, dev.IncomingSms(), 1)
+
+	dev.Commands.(*noopProfile).InboxPolicy = StringOpt{"archive", "Unknown"}
+	assert.ErrorIs(t, dev.handleReport(`+CMTI: "SM",1`), ErrUnsupportedOption)
+}
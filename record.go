@@ -0,0 +1,193 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrReplayMismatch happens when the data written to a replayed port differs from the capture.
+var ErrReplayMismatch = errors.New("at: the written data doesn't match the capture")
+
+// The directions of the captured traffic.
+const (
+	toModem   = ">"
+	fromModem = "<"
+)
+
+// RecordDialer wraps the dialer so the traffic of all the opened ports is written to w,
+// one chunk per line: the elapsed seconds, the port name, the direction and the quoted data.
+//
+//	0.015 /dev/ttyUSB0 > "AT+CSQ\r\n"
+//	0.042 /dev/ttyUSB0 < "\r\n+CSQ: 20,99\r\n\r\nOK\r\n"
+//
+// The capture could be played back with ReplayDialer.
+func RecordDialer(dial Dialer, w io.Writer) Dialer {
+	rec := &recorder{w: w, start: time.Now()}
+	return func(name string) (io.ReadWriteCloser, error) {
+		rwc, err := dial(name)
+		if err != nil {
+			return nil, err
+		}
+		return &recordedPort{ReadWriteCloser: rwc, name: name, rec: rec}, nil
+	}
+}
+
+type recorder struct {
+	mux   sync.Mutex
+	w     io.Writer
+	start time.Time
+}
+
+func (r *recorder) record(name, dir string, data []byte) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	fmt.Fprintf(r.w, "%.3f %s %s %s\n", time.Since(r.start).Seconds(), name, dir, strconv.Quote(string(data)))
+}
+
+type recordedPort struct {
+	io.ReadWriteCloser
+	name string
+	rec  *recorder
+}
+
+func (p *recordedPort) Read(b []byte) (n int, err error) {
+	n, err = p.ReadWriteCloser.Read(b)
+	if n > 0 {
+		p.rec.record(p.name, fromModem, b[:n])
+	}
+	return
+}
+
+func (p *recordedPort) Write(b []byte) (n int, err error) {
+	n, err = p.ReadWriteCloser.Write(b)
+	if n > 0 {
+		p.rec.record(p.name, toModem, b[:n])
+	}
+	return
+}
+
+type captureEntry struct {
+	dir  string
+	data string
+}
+
+// ReplayDialer reads the capture made by RecordDialer and returns a dialer that plays it back.
+// The data written to a port is checked against the capture, the captured replies become
+// available for reading once the preceding writes have been matched. The timing is not kept,
+// so the replay is deterministic.
+func ReplayDialer(r io.Reader) (Dialer, error) {
+	captures := make(map[string][]captureEntry)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) < 4 || (fields[2] != toModem && fields[2] != fromModem) {
+			return nil, fmt.Errorf("at replay: malformed line %d", n)
+		}
+		data, err := strconv.Unquote(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("at replay: malformed data on line %d: %w", n, err)
+		}
+		captures[fields[1]] = append(captures[fields[1]], captureEntry{dir: fields[2], data: data})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var mux sync.Mutex
+	return func(name string) (io.ReadWriteCloser, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		// each port is played back once, the ports without any traffic stay silent
+		p := &replayPort{entries: captures[name]}
+		p.cond = sync.NewCond(&p.mux)
+		p.advance()
+		delete(captures, name)
+		return p, nil
+	}, nil
+}
+
+// replayPort plays back the captured traffic of a single port.
+type replayPort struct {
+	mux     sync.Mutex
+	cond    *sync.Cond
+	entries []captureEntry
+	pos     int
+	offset  int // the matched part of the current write entry
+	pending []byte
+	closed  bool
+}
+
+// advance makes the captured replies up to the next write available for reading.
+func (p *replayPort) advance() {
+	for p.pos < len(p.entries) && p.entries[p.pos].dir == fromModem {
+		p.pending = append(p.pending, p.entries[p.pos].data...)
+		p.pos++
+	}
+	p.cond.Broadcast()
+}
+
+func (p *replayPort) Read(b []byte) (int, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	for len(p.pending) == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if len(p.pending) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+func (p *replayPort) Write(b []byte) (int, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		return 0, io.ErrClosedPipe
+	}
+	var written int
+	for written < len(b) {
+		if p.pos >= len(p.entries) {
+			return written, ErrReplayMismatch
+		}
+		expected := p.entries[p.pos].data[p.offset:]
+		n := len(expected)
+		if rest := len(b) - written; rest < n {
+			n = rest
+		}
+		if expected[:n] != string(b[written:written+n]) {
+			return written, ErrReplayMismatch
+		}
+		written += n
+		p.offset += n
+		if p.offset == len(p.entries[p.pos].data) {
+			p.pos++
+			p.offset = 0
+			p.advance()
+		}
+	}
+	return written, nil
+}
+
+func (p *replayPort) Close() error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.closed = true
+	p.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,52 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	session := func(dial Dialer) string {
+		dev := &Device{
+			CommandPort: "cmd",
+			NotifyPort:  "notify",
+			Dial:        dial,
+			Timeout:     time.Second,
+		}
+		require.NoError(t, dev.Open())
+		require.NoError(t, dev.Init(&noopProfile{}))
+		defer dev.Close()
+		reply, err := dev.Send(`AT+GMM`)
+		require.NoError(t, err)
+		return reply
+	}
+
+	td := &testDialer{replies: map[string]string{"AT+GMM": "E173" + Sep + Sep + "OK"}}
+	var capture bytes.Buffer
+	assert.Equal(t, "E173", session(RecordDialer(td.Dial, &capture)))
+	assert.Contains(t, capture.String(), ` cmd > "AT+GMM\r\n"`)
+
+	replay, err := ReplayDialer(&capture)
+	require.NoError(t, err)
+	assert.Equal(t, "E173", session(replay))
+
+	replay, err = ReplayDialer(strings.NewReader(`0.001 cmd > "AT\r\n"`))
+	require.NoError(t, err)
+	port, err := replay("cmd")
+	require.NoError(t, err)
+	_, err = port.Write([]byte("ATZ\r\n"))
+	assert.ErrorIs(t, err, ErrReplayMismatch)
+
+	_, err = ReplayDialer(strings.NewReader("garbage"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotRecording happens on StopRecording if there's no recording in progress.
+var ErrNotRecording = errors.New("at: no call recording in progress")
+
+// callRecorder is implemented by profiles that are able to record the call audio
+// into a file on the module's file system.
+type callRecorder interface {
+	StartRecording(file string) (err error)
+	StopRecording() (err error)
+}
+
+// Recording represents the call audio recorded into a file on the module.
+type Recording struct {
+	// File is the path of the recording on the module's file system.
+	File  string
+	Start time.Time
+	End   time.Time
+}
+
+// CREC sends AT+CREC to the device, it starts the recording of the call audio into
+// the given file if it's not empty, stops the recording otherwise (SIMCom SIM7600-series).
+// The module reports +CREC: crec stop once the recording is stopped and the file is ready.
+func (p *SIMComProfile) CREC(file string) (err error) {
+	req := `AT+CREC=0`
+	if len(file) > 0 {
+		req = fmt.Sprintf(`AT+CREC=1,"%s"`, file)
+	}
+	_, err = p.dev.Send(req)
+	return
+}
+
+// StartRecording starts the recording of the call audio into the file, i.e. "E:/call.wav".
+func (p *SIMComProfile) StartRecording(file string) error {
+	return p.CREC(file)
+}
+
+// StopRecording stops the recording of the call audio.
+func (p *SIMComProfile) StopRecording() error {
+	return p.CREC("")
+}
+
+// StartRecording starts the recording of the call audio into the file on the module,
+// the call should be established. The recording continues until StopRecording.
+func (d *Device) StartRecording(file string) error {
+	if err := d.sanityCheck(true); err != nil {
+		return err
+	}
+	p, ok := d.Commands.(callRecorder)
+	if !ok || !d.Supports(Features.Recording) {
+		return ErrUnsupported
+	}
+	if err := p.StartRecording(file); err != nil {
+		return fmt.Errorf("at recording: unable to start: %w", err)
+	}
+	d.recordMux.Lock()
+	d.recording = &Recording{File: file, Start: time.Now()}
+	d.recordMux.Unlock()
+	return nil
+}
+
+// StopRecording stops the recording and returns it, the recording is sent to the Recordings
+// channel once the module reports that the file is ready.
+func (d *Device) StopRecording() (*Recording, error) {
+	if err := d.sanityCheck(true); err != nil {
+		return nil, err
+	}
+	p, ok := d.Commands.(callRecorder)
+	if !ok || !d.Supports(Features.Recording) {
+		return nil, ErrUnsupported
+	}
+	d.recordMux.Lock()
+	defer d.recordMux.Unlock()
+	if d.recording == nil {
+		return nil, ErrNotRecording
+	}
+	if err := p.StopRecording(); err != nil {
+		return nil, fmt.Errorf("at recording: unable to stop: %w", err)
+	}
+	rec := d.recording
+	rec.End = time.Now()
+	d.recording = nil
+	d.stopping = rec
+	return rec, nil
+}
+
+// recordingStopped handles the completion report of the module, the recording is either
+// stopped by StopRecording or by the module itself, i.e. when the call is ended.
+func (d *Device) recordingStopped() {
+	d.recordMux.Lock()
+	rec := d.stopping
+	if rec == nil && d.recording != nil {
+		rec = d.recording
+		rec.End = time.Now()
+		d.recording = nil
+	}
+	d.stopping = nil
+	d.recordMux.Unlock()
+	if rec == nil {
+		return
+	}
+	select {
+	case d.recordings <- *rec:
+	default:
+	}
+}
+
+// Recordings fires when a call recording is stopped and the module reports its file
+// is ready. The recordings are dropped if the channel is not consumed.
+func (d *Device) Recordings() <-chan Recording {
+	return d.recordings
+}
@@ -0,0 +1,69 @@
+package at
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noopSIMComProfile is the SIMComProfile that doesn't configure the device on Init.
+type noopSIMComProfile struct {
+	SIMComProfile
+}
+
+func (p *noopSIMComProfile) Init(d *Device) error {
+	p.dev = d
+	_, err := d.Send(NoopCmd)
+	return err
+}
+
+func TestRecording(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := newProfileDevice(t, &noopSIMComProfile{SIMComProfile{CallRecording: true}}, map[string]string{
+		`AT+CREC=1,"E:/call.wav"`: "OK",
+		`AT+CREC=1,"E:/next.wav"`: "OK",
+		"AT+CREC=0":               "OK",
+	})
+	go dev.Watch()
+
+	_, err := dev.StopRecording()
+	assert.ErrorIs(t, err, ErrNotRecording)
+	require.NoError(t, dev.StartRecording("E:/call.wav"))
+	rec, err := dev.StopRecording()
+	require.NoError(t, err)
+	assert.Equal(t, "E:/call.wav", rec.File)
+	select {
+	case <-dev.Recordings():
+		t.Fatal("the recording is sent before the file is ready")
+	default:
+	}
+	io.WriteString(urc, Sep+"+CREC: crec stop"+Sep)
+	select {
+	case got := <-dev.Recordings():
+		assert.Equal(t, *rec, got)
+	case <-time.After(time.Second):
+		t.Fatal("the recording is not sent")
+	}
+
+	// stopped by the module when the call is ended
+	require.NoError(t, dev.StartRecording("E:/next.wav"))
+	io.WriteString(urc, Sep+"+CREC: crec stop"+Sep)
+	select {
+	case got := <-dev.Recordings():
+		assert.Equal(t, "E:/next.wav", got.File)
+	case <-time.After(time.Second):
+		t.Fatal("the recording is not sent")
+	}
+	_, err = dev.StopRecording()
+	assert.ErrorIs(t, err, ErrNotRecording)
+
+	sim800 := &Device{Commands: DeviceSIM800()}
+	assert.False(t, sim800.Supports(Features.Recording))
+
+	plain, _ := newTestDevice(t, nil)
+	assert.ErrorIs(t, plain.StartRecording("E:/call.wav"), ErrUnsupported)
+}
@@ -0,0 +1,84 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrBlocked happens when a destructive command is sent to a protected device.
+var ErrBlocked = errors.New("at: destructive command is blocked")
+
+var operations = stringOpts{
+	{"delete-all", "Deleting multiple messages"},
+	{"reset", "Resetting the module"},
+	{"factory-reset", "Restoring the factory settings"},
+	{"facility-lock", "Changing the facility locks and passwords"},
+}
+
+// Operations represent the kinds of the destructive commands blocked on the
+// protected devices, see Device.Protected.
+var Operations = struct {
+	Resolve   func(string) StringOpt
+	AllValues func() []StringOpt
+
+	DeleteAll    StringOpt
+	Reset        StringOpt
+	FactoryReset StringOpt
+	FacilityLock StringOpt
+}{
+	func(str string) StringOpt { return operations.Resolve(str) },
+	operationsValues,
+
+	operations[0], operations[1], operations[2], operations[3],
+}
+
+// destructiveOperation reports which destructive operation is performed by the command.
+func destructiveOperation(req string) (StringOpt, bool) {
+	cmd := strings.ToUpper(strings.TrimSpace(req))
+	args := func(prefix string) []string {
+		return strings.Split(strings.TrimPrefix(cmd, prefix), ",")
+	}
+	switch {
+	case strings.HasPrefix(cmd, "AT+CMGD="):
+		// AT+CMGD=<index>[,<delflag>], a non-zero flag deletes multiple messages
+		if fields := args("AT+CMGD="); len(fields) > 1 && strings.TrimSpace(fields[1]) != "0" {
+			return Operations.DeleteAll, true
+		}
+	case strings.HasPrefix(cmd, "AT+CFUN="):
+		// AT+CFUN=<fun>[,<rst>]
+		if fields := args("AT+CFUN="); len(fields) > 1 && strings.TrimSpace(fields[1]) == "1" {
+			return Operations.Reset, true
+		}
+	case cmd == "AT+CRESET", cmd == "AT^RESET", cmd == "AT+QRST=1,0":
+		return Operations.Reset, true
+	case strings.HasPrefix(cmd, "AT&F"):
+		return Operations.FactoryReset, true
+	case strings.HasPrefix(cmd, "AT+CLCK="):
+		// AT+CLCK=<fac>,<mode>[,<passwd>], the mode 2 only queries the status
+		if fields := args("AT+CLCK="); len(fields) > 1 && strings.TrimSpace(fields[1]) != "2" {
+			return Operations.FacilityLock, true
+		}
+	case strings.HasPrefix(cmd, "AT+CPWD="):
+		return Operations.FacilityLock, true
+	}
+	return UnknownStringOpt, false
+}
+
+// guard returns ErrBlocked if the device is protected and the command performs
+// a destructive operation that is not allowed.
+func (d *Device) guard(req string) error {
+	if !d.Protected {
+		return nil
+	}
+	op, ok := destructiveOperation(req)
+	if !ok {
+		return nil
+	}
+	for _, allowed := range d.Allowed {
+		if allowed == op {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s: %s", ErrBlocked, op.Description, req)
+}
@@ -0,0 +1,49 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestructiveOperation(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		req string
+		op  StringOpt
+	}{
+		{"AT+CMGD=1,4", Operations.DeleteAll},
+		{"AT+CMGD=1,0", UnknownStringOpt},
+		{"AT+CMGD=1", UnknownStringOpt},
+		{"AT+CFUN=1,1", Operations.Reset},
+		{"AT+CFUN=0", UnknownStringOpt},
+		{"AT+CRESET", Operations.Reset},
+		{"AT&F0", Operations.FactoryReset},
+		{`AT+CLCK="SC",1,"1234"`, Operations.FacilityLock},
+		{`AT+CLCK="SC",2`, UnknownStringOpt},
+		{`AT+CPWD="SC","1234","4321"`, Operations.FacilityLock},
+		{"AT+CMGL=4", UnknownStringOpt},
+	}
+	for _, tc := range testcases {
+		op, _ := destructiveOperation(tc.req)
+		assert.Equal(t, tc.op, op, tc.req)
+	}
+}
+
+func TestProtected(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGD=0,4": "OK",
+		"AT+CFUN=1,1": "OK",
+	})
+	dev.Protected = true
+	dev.Allowed = []StringOpt{Operations.DeleteAll}
+
+	_, err := dev.Send("AT+CFUN=1,1")
+	assert.ErrorIs(t, err, ErrBlocked)
+	assert.NoError(t, dev.Commands.CMGD(0, DeleteOptions.All))
+	_, err = dev.Send(NoopCmd)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,173 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// ErrDeferred happens when a message is sent within the quiet hours of its route
+// or over the daily limit of its destination, see Device.Routes.
+var ErrDeferred = errors.New("at: message is deferred")
+
+// SendRoute constrains the messages sent to the destinations with the given prefix.
+type SendRoute struct {
+	// Prefix of the destination numbers, i.e. "+33", the empty prefix matches any number.
+	// The route with the longest matching prefix is applied.
+	Prefix string
+	// QuietStart and QuietEnd are the offsets from the midnight, no messages are sent
+	// between them. The quiet hours may span the midnight, i.e. 21h to 8h.
+	QuietStart time.Duration
+	QuietEnd   time.Duration
+	// MaxPerDay limits the number of messages sent to a destination per day, if set.
+	MaxPerDay int
+	// Location of the quiet hours and the days, time.Local if nil.
+	Location *time.Location
+}
+
+// quiet reports whether the time is within the quiet hours and returns their end.
+func (r *SendRoute) quiet(now time.Time) (bool, time.Time) {
+	if r.QuietStart == r.QuietEnd {
+		return false, time.Time{}
+	}
+	midnight := r.midnight(now)
+	offset := now.Sub(midnight)
+	switch {
+	case r.QuietStart < r.QuietEnd:
+		if offset >= r.QuietStart && offset < r.QuietEnd {
+			return true, midnight.Add(r.QuietEnd)
+		}
+	case offset >= r.QuietStart:
+		return true, r.midnight(midnight.AddDate(0, 0, 1)).Add(r.QuietEnd)
+	case offset < r.QuietEnd:
+		return true, midnight.Add(r.QuietEnd)
+	}
+	return false, time.Time{}
+}
+
+// midnight returns the start of the day in the route's location.
+func (r *SendRoute) midnight(t time.Time) time.Time {
+	loc := r.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// DeferredError describes the message that was held back by its route,
+// it matches ErrDeferred with errors.Is.
+type DeferredError struct {
+	Address sms.PhoneNumber
+	// Until is the earliest time when the message may be sent.
+	Until time.Time
+	// Reason is either "quiet hours" or "daily limit".
+	Reason string
+	// CorrelationID of the deferred message.
+	CorrelationID string
+}
+
+func (e *DeferredError) Error() string {
+	return fmt.Sprintf("%v: %s to %s until %s", ErrDeferred, e.Reason, e.Address, e.Until.Format(time.RFC3339))
+}
+
+func (e *DeferredError) Unwrap() error {
+	return ErrDeferred
+}
+
+// route returns the route with the longest prefix matching the address.
+func (d *Device) route(address sms.PhoneNumber) *SendRoute {
+	var match *SendRoute
+	for i := range d.Routes {
+		r := &d.Routes[i]
+		if !strings.HasPrefix(string(address), r.Prefix) {
+			continue
+		}
+		if match == nil || len(r.Prefix) > len(match.Prefix) {
+			match = r
+		}
+	}
+	return match
+}
+
+// schedule returns a DeferredError if the message to the address can't be sent now.
+func (d *Device) schedule(address sms.PhoneNumber, id string, now time.Time) error {
+	r := d.route(address)
+	if r == nil {
+		return nil
+	}
+	if quiet, until := r.quiet(now); quiet {
+		return &DeferredError{Address: address, Until: until, Reason: "quiet hours", CorrelationID: id}
+	}
+	if r.MaxPerDay <= 0 {
+		return nil
+	}
+	midnight := r.midnight(now)
+	d.scheduleMux.Lock()
+	defer d.scheduleMux.Unlock()
+	if sent := d.sentToday[address]; sent.day(r, now).Equal(midnight) && sent.count >= r.MaxPerDay {
+		until := r.midnight(midnight.AddDate(0, 0, 1))
+		return &DeferredError{Address: address, Until: until, Reason: "daily limit", CorrelationID: id}
+	}
+	return nil
+}
+
+// dailyCount is the number of messages sent to a destination since the midnight.
+type dailyCount struct {
+	// last is the time of the last message, with the monotonic clock reading.
+	last  time.Time
+	count int
+}
+
+// day returns the start of the day when the messages were sent. It's derived from
+// the current wall clock and the monotonic time elapsed since the last message,
+// so the counts survive the wall clock jumps, i.e. an NTP step after the boot
+// with a wrong clock.
+func (c dailyCount) day(r *SendRoute, now time.Time) time.Time {
+	return r.midnight(rebase(now, c.last))
+}
+
+// rebase returns the time of the past event t by the current wall clock. The time
+// elapsed since t is measured with the monotonic clock if both t and now have
+// its reading (i.e. they are from time.Now), so the wall clock jumps in between
+// don't affect the result.
+func rebase(now, t time.Time) time.Time {
+	return now.Round(0).Add(-now.Sub(t))
+}
+
+// sent counts the message to the address towards its daily limit.
+func (d *Device) sent(address sms.PhoneNumber, now time.Time) {
+	r := d.route(address)
+	if r == nil || r.MaxPerDay <= 0 {
+		return
+	}
+	midnight := r.midnight(now)
+	d.scheduleMux.Lock()
+	defer d.scheduleMux.Unlock()
+	if d.sentToday == nil || now.Sub(d.sentPruned) > 24*time.Hour {
+		d.pruneSent(now)
+	}
+	sent := d.sentToday[address]
+	if sent.count == 0 || !sent.day(r, now).Equal(midnight) {
+		sent = dailyCount{}
+	}
+	sent.count++
+	sent.last = now
+	d.sentToday[address] = sent
+}
+
+// pruneSent drops the counts of the past days. The caller must hold the scheduleMux.
+func (d *Device) pruneSent(now time.Time) {
+	if d.sentToday == nil {
+		d.sentToday = make(map[sms.PhoneNumber]dailyCount)
+	}
+	for address, sent := range d.sentToday {
+		if now.Sub(sent.last) > 48*time.Hour {
+			delete(d.sentToday, address)
+		}
+	}
+	d.sentPruned = now
+}
@@ -0,0 +1,70 @@
+package at
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHours(t *testing.T) {
+	t.Parallel()
+
+	r := &SendRoute{QuietStart: 21 * time.Hour, QuietEnd: 8 * time.Hour, Location: time.UTC}
+	at := func(hour int) time.Time {
+		return time.Date(2014, 9, 7, hour, 30, 0, 0, time.UTC)
+	}
+	quiet, until := r.quiet(at(22))
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2014, 9, 8, 8, 0, 0, 0, time.UTC), until)
+	quiet, until = r.quiet(at(3))
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2014, 9, 7, 8, 0, 0, 0, time.UTC), until)
+	quiet, _ = r.quiet(at(12))
+	assert.False(t, quiet)
+
+	r = &SendRoute{QuietStart: 12 * time.Hour, QuietEnd: 14 * time.Hour, Location: time.UTC}
+	quiet, until = r.quiet(at(13))
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2014, 9, 7, 14, 0, 0, 0, time.UTC), until)
+	quiet, _ = r.quiet(at(14))
+	assert.False(t, quiet)
+}
+
+func TestSchedule(t *testing.T) {
+	t.Parallel()
+
+	d := &Device{Routes: []SendRoute{
+		{MaxPerDay: 2, Location: time.UTC},
+		{Prefix: "+33", QuietStart: 21 * time.Hour, QuietEnd: 8 * time.Hour, Location: time.UTC},
+	}}
+	now := time.Date(2014, 9, 7, 22, 0, 0, 0, time.UTC)
+
+	err := d.schedule("+33612345678", "otp-1", now)
+	assert.True(t, errors.Is(err, ErrDeferred))
+	var deferred *DeferredError
+	if assert.True(t, errors.As(err, &deferred)) {
+		assert.Equal(t, "quiet hours", deferred.Reason)
+		assert.Equal(t, "otp-1", deferred.CorrelationID)
+	}
+
+	assert.NoError(t, d.schedule("+79261234567", "", now))
+	d.sent("+79261234567", now)
+	d.sent("+79261234567", now)
+	err = d.schedule("+79261234567", "", now)
+	if assert.True(t, errors.As(err, &deferred)) {
+		assert.Equal(t, "daily limit", deferred.Reason)
+		assert.Equal(t, time.Date(2014, 9, 8, 0, 0, 0, 0, time.UTC), deferred.Until)
+	}
+	assert.NoError(t, d.schedule("+79261234567", "", deferred.Until))
+}
+
+func TestRebase(t *testing.T) {
+	t.Parallel()
+
+	last := time.Now()
+	assert.True(t, rebase(last.Add(time.Hour), last).Equal(last))
+	wall := time.Date(2014, 9, 7, 22, 0, 0, 0, time.UTC)
+	assert.Equal(t, wall, rebase(wall.Add(time.Hour), wall))
+}
@@ -0,0 +1,162 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultProbeTimeout limits the time spent on waiting for a reply to a probe.
+const DefaultProbeTimeout = 500 * time.Millisecond
+
+// AutoBaud is the Device.BaudRate value that enables the baud rate detection.
+const AutoBaud = -1
+
+// CommonBaudRates are the baud rates probed by DetectBaudRate, the most used go first.
+var CommonBaudRates = []int{115200, 9600, 57600, 38400, 19200, 230400, 460800, 921600}
+
+// ErrBaudRate happens when the baud rate is not supported or could not be detected.
+var ErrBaudRate = errors.New("at: unable to set or detect the baud rate")
+
+// SerialConfig holds the settings of a serial port.
+type SerialConfig struct {
+	// BaudRate of the port, the current rate is kept if zero.
+	BaudRate int
+	// FlowControl enables the RTS/CTS hardware flow control.
+	FlowControl bool
+}
+
+// Dialer returns a Dialer that opens the serial port in raw mode (8N1) with the config applied.
+func (c SerialConfig) Dialer() Dialer {
+	return func(name string) (io.ReadWriteCloser, error) {
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err = configure(f, c); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return f, nil
+	}
+}
+
+// SerialDialer returns a Dialer that opens the serial port in raw mode (8N1)
+// with the given baud rate.
+func SerialDialer(baud int) Dialer {
+	return SerialConfig{BaudRate: baud}.Dialer()
+}
+
+// DetectBaudRate opens the serial port at each of the given baud rates (CommonBaudRates
+// by default) and probes the modem with NoopCmd. Returns the first rate that works.
+func DetectBaudRate(name string, rates ...int) (int, error) {
+	if len(rates) == 0 {
+		rates = CommonBaudRates
+	}
+	for _, baud := range rates {
+		rwc, err := SerialDialer(baud)(name)
+		if err != nil {
+			if errors.Is(err, ErrBaudRate) {
+				continue
+			}
+			return 0, err
+		}
+		err = probe(rwc, DefaultProbeTimeout)
+		rwc.Close()
+		if err == nil {
+			return baud, nil
+		}
+	}
+	return 0, ErrBaudRate
+}
+
+// baudRateCommander is implemented by profiles that are able to change the baud rate.
+type baudRateCommander interface {
+	IPR(baud int) (err error)
+	ATW() (err error)
+}
+
+// SetBaudRate switches the modem to the given baud rate using AT+IPR and reopens the ports
+// at that rate. If persist is set, the rate is stored with AT&W to be used after a restart.
+// The rate can't be applied to the ports opened by a custom Dial, ErrUnsupported is returned.
+func (d *Device) SetBaudRate(baud int, persist bool) error {
+	p, ok := d.Commands.(baudRateCommander)
+	if !ok {
+		return ErrUnsupported
+	}
+	if d.Dial != nil {
+		// the modem would be switched, but the ports would be reopened at the old rate
+		return fmt.Errorf("%w: the baud rate can't be applied through a custom Dial", ErrUnsupported)
+	}
+	if err := p.IPR(baud); err != nil {
+		return fmt.Errorf("at: unable to set the baud rate: %w", err)
+	}
+	d.cmdMux.Lock()
+	d.closePorts()
+	d.BaudRate = baud
+	err := d.open()
+	d.resetFailures()
+	d.cmdMux.Unlock()
+	if err != nil {
+		return err
+	}
+	if persist {
+		// the modem replies at the new rate since AT+IPR
+		if err = p.ATW(); err != nil {
+			return fmt.Errorf("at: unable to store the baud rate: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetDTR raises or drops the DTR line of the command port, i.e. to wake up a module
+// that sleeps in the slow clock mode (see CSCLK). Works only with serial ports.
+func (d *Device) SetDTR(on bool) error {
+	return d.setModemLine(lineDTR, on)
+}
+
+// SetRTS raises or drops the RTS line of the command port, it shouldn't be used
+// when the hardware flow control is enabled. Works only with serial ports.
+func (d *Device) SetRTS(on bool) error {
+	return d.setModemLine(lineRTS, on)
+}
+
+func (d *Device) setModemLine(line int, on bool) error {
+	d.cmdMux.Lock()
+	defer d.cmdMux.Unlock()
+	f, ok := d.cmdPort.(*os.File)
+	if !ok {
+		return ErrUnsupported
+	}
+	return setModemLine(f, line, on)
+}
+
+// probe sends NoopCmd to the stream and waits for OK until the timeout exceeds.
+func probe(rwc io.ReadWriteCloser, timeout time.Duration) error {
+	p := newPort(rwc)
+	if err := p.SetDeadline(time.Now().Add(timeout)); err != nil {
+		// i.e. the file is not pollable, emulate the deadline
+		p = newDeadlinePort(rwc)
+		p.SetDeadline(time.Now().Add(timeout))
+	}
+	defer p.SetDeadline(time.Time{})
+
+	if _, err := p.Write([]byte(NoopCmd + "\r")); err != nil {
+		return err
+	}
+	var reply strings.Builder
+	buf := make([]byte, 64)
+	for {
+		n, err := p.Read(buf)
+		reply.Write(buf[:n])
+		if strings.Contains(reply.String(), FinalResults.Ok.ID) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,89 @@
+//go:build linux && !ppc64 && !ppc64le
+// +build linux,!ppc64,!ppc64le
+
+package at
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const cbaud = 0o10017
+
+var baudRates = map[int]uint32{
+	1200:   0o11,
+	2400:   0o13,
+	4800:   0o14,
+	9600:   0o15,
+	19200:  0o16,
+	38400:  0o17,
+	57600:  0o10001,
+	115200: 0o10002,
+	230400: 0o10003,
+	460800: 0o10004,
+	921600: 0o10007,
+}
+
+// crtscts enables the RTS/CTS hardware flow control.
+const crtscts = 0x80000000
+
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// configure switches the terminal into raw mode (8N1) with the given baud rate,
+// the current rate is kept if the BaudRate is zero.
+func configure(f *os.File, cfg SerialConfig) error {
+	speed, ok := baudRates[cfg.BaudRate]
+	if !ok && cfg.BaudRate != 0 {
+		return ErrBaudRate
+	}
+	var t syscall.Termios
+	if err := ioctl(f, syscall.TCGETS, unsafe.Pointer(&t)); err != nil {
+		return err
+	}
+	t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	t.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.CSTOPB | crtscts
+	t.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL
+	if cfg.BaudRate != 0 {
+		t.Cflag = t.Cflag&^cbaud | speed
+	}
+	if cfg.FlowControl {
+		t.Cflag |= crtscts
+	}
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+	return ioctl(f, syscall.TCSETS, unsafe.Pointer(&t))
+}
+
+// setModemLine raises or drops the given modem control line (TIOCM_DTR or TIOCM_RTS).
+func setModemLine(f *os.File, line int, on bool) error {
+	var req uintptr = syscall.TIOCMBIC
+	if on {
+		req = syscall.TIOCMBIS
+	}
+	// the kernel reads a C int, which is 32 bits wide on all the platforms
+	bits := int32(line)
+	return ioctl(f, req, unsafe.Pointer(&bits))
+}
+
+// modemLines returns the state of the modem control lines.
+func modemLines(f *os.File) (int, error) {
+	var lines int32
+	err := ioctl(f, syscall.TIOCMGET, unsafe.Pointer(&lines))
+	return int(lines), err
+}
+
+const (
+	lineDTR = syscall.TIOCM_DTR
+	lineRTS = syscall.TIOCM_RTS
+	lineRI  = syscall.TIOCM_RI
+)
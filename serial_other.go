@@ -0,0 +1,27 @@
+//go:build !linux || ppc64 || ppc64le
+// +build !linux ppc64 ppc64le
+
+package at
+
+import "os"
+
+// configure is not implemented on this platform, the port is used as configured by the OS.
+func configure(f *os.File, cfg SerialConfig) error {
+	return ErrBaudRate
+}
+
+// setModemLine is not implemented on this platform.
+func setModemLine(f *os.File, line int, on bool) error {
+	return ErrUnsupported
+}
+
+// modemLines is not implemented on this platform.
+func modemLines(f *os.File) (int, error) {
+	return 0, ErrUnsupported
+}
+
+const (
+	lineDTR = 0x002
+	lineRTS = 0x004
+	lineRI  = 0x080
+)
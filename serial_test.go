@@ -0,0 +1,54 @@
+package at
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	go serveModem(remote, map[string]string{"AT": "OK"})
+	assert.NoError(t, probe(local, time.Second))
+
+	silent, _ := net.Pipe()
+	defer silent.Close()
+	go silent.Read(make([]byte, 64)) // accept the probe, but never reply
+	assert.Error(t, probe(silent, 10*time.Millisecond))
+}
+
+func TestSetBaudRate(t *testing.T) {
+	t.Parallel()
+
+	td := &testDialer{}
+	dev := &Device{
+		CommandPort: "cmd",
+		NotifyPort:  "notify",
+		Dial:        td.Dial,
+		Timeout:     time.Second,
+	}
+	require.NoError(t, dev.Open())
+	require.NoError(t, dev.Init(&noopProfile{}))
+	defer dev.Close()
+
+	// the ports opened by the dialer can't be switched to the rate, so AT+IPR
+	// is not sent, it would fail with ERROR otherwise
+	assert.ErrorIs(t, dev.SetBaudRate(9600, true), ErrUnsupported)
+	assert.Zero(t, dev.BaudRate)
+	_, err := dev.Send(NoopCmd)
+	assert.NoError(t, err)
+}
+
+func TestSetDTR(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, nil)
+	assert.ErrorIs(t, dev.SetDTR(true), ErrUnsupported)
+	assert.ErrorIs(t, dev.SetRTS(false), ErrUnsupported)
+}
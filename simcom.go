@@ -0,0 +1,99 @@
+package at
+
+import "strings"
+
+// SIMComProfile is the DeviceProfile implementation for SIMCom modules, they don't
+// support the Huawei-specific AT^SYSINFO and AT^SYSCFG, so the standard commands
+// and the SIMCom's AT+CPSI? and AT+CNMP are used instead.
+type SIMComProfile struct {
+	DefaultProfile
+
+	// CallRecording is set for the modules that record the call audio with
+	// AT+CREC=1,<file> (SIM7600-series), the SIM800-series use another syntax.
+	CallRecording bool
+}
+
+// simcomNotificationConfigs are the AT+CNMI settings supported by SIMCom modules.
+var simcomNotificationConfigs = []CNMIConfig{
+	{Mode: 2, MT: 1, BM: 0, DS: 2, BFR: 0},
+	{Mode: 2, MT: 1, BM: 0, DS: 0, BFR: 0},
+	{Mode: 1, MT: 1, BM: 0, DS: 0, BFR: 0},
+}
+
+// DeviceSIM800 returns an instance of DeviceProfile implementation for SIMCom SIM800-series.
+func DeviceSIM800() DeviceProfile {
+	return newSIMComProfile()
+}
+
+// DeviceSIM7600 returns an instance of DeviceProfile implementation for SIMCom SIM7600-series.
+func DeviceSIM7600() DeviceProfile {
+	p := newSIMComProfile()
+	p.CallRecording = true
+	return p
+}
+
+func newSIMComProfile() *SIMComProfile {
+	p := &SIMComProfile{}
+	p.NotificationConfigs = simcomNotificationConfigs
+	return p
+}
+
+// cpsiReport represents the reply to AT+CPSI?: +CPSI: <system mode>,<operation mode>,...
+type cpsiReport struct {
+	SystemMode Opt
+	Online     bool
+}
+
+var cpsiModes = map[string]Opt{
+	"NO SERVICE": SystemModes.NoService,
+	"GSM":        SystemModes.GsmGprs,
+	"WCDMA":      SystemModes.WCDMA,
+	"TDS-CDMA":   SystemModes.SCDMA,
+	"CDMA":       SystemModes.CDMA,
+	"EVDO":       SystemModes.HDR,
+	"LTE":        SystemModes.LTE,
+}
+
+func (c *cpsiReport) Parse(str string) error {
+	fields := strings.Split(strings.TrimSpace(strings.TrimPrefix(str, "+CPSI:")), ",")
+	if len(fields) < 2 {
+		return ErrParseReport
+	}
+	mode, ok := cpsiModes[strings.TrimSpace(fields[0])]
+	if !ok {
+		return ErrParseReport
+	}
+	c.SystemMode = mode
+	c.Online = strings.TrimSpace(fields[1]) == "Online"
+	return nil
+}
+
+// SYSINFO collects the system info from AT+CPSI? (if supported), AT+CREG? and AT+CPIN?.
+func (p *SIMComProfile) SYSINFO() (info *SystemInfoReport, err error) {
+	if info, err = p.registration(); err != nil {
+		return nil, err
+	}
+	info.SimState = p.simState()
+
+	if reply, err := p.dev.Send(`AT+CPSI?`); err == nil {
+		var cpsi cpsiReport
+		if cpsi.Parse(reply) == nil {
+			info.SystemMode = cpsi.SystemMode
+			if !cpsi.Online {
+				info.ServiceState = ServiceStates.PowerSaving
+			}
+		}
+	} else if info.ServiceState == ServiceStates.Valid {
+		// SIM800 is a GSM-only module without AT+CPSI
+		info.SystemMode = SystemModes.GsmGprs
+	}
+	return info, nil
+}
+
+// SYSCFG sends AT+CNMP=2 to the device, that enables the automatic mode selection.
+// The roaming and domain are not configurable on SIMCom modules, SIM800 doesn't
+// support AT+CNMP at all.
+func (p *SIMComProfile) SYSCFG(roaming, cellular bool) (err error) {
+	_, err = p.dev.Send(`AT+CNMP=2`)
+	return
+}
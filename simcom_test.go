@@ -0,0 +1,34 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSIMComInit(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newProfileDevice(t, DeviceSIM7600(), map[string]string{
+		"AT+COPS=0,0":            "OK",
+		"AT+CREG?":               "+CREG: 0,1" + Sep + Sep + "OK",
+		"AT+CPIN?":               "+CPIN: READY" + Sep + Sep + "OK",
+		"AT+CPSI?":               "+CPSI: LTE,Online,250-01,0x1B3C,27447299,112,EUTRAN-BAND3" + Sep + Sep + "OK",
+		"AT+COPS?":               `+COPS: 0,0,"MTS",7` + Sep + Sep + "OK",
+		"AT+GMM":                 "SIMCOM_SIM7600E" + Sep + Sep + "OK",
+		"AT+GSN":                 "861234567890123" + Sep + Sep + "OK",
+		"AT+CMGF=0":              "OK",
+		"AT+CPMS=?":              `+CPMS: ("SM","ME"),("SM","ME"),("SM","ME")` + Sep + Sep + "OK",
+		`AT+CPMS="SM","SM","SM"`: "+CPMS: 0,30,0,30,0,30" + Sep + Sep + "OK",
+		"AT+CNMI=2,1,0,2,0":      "OK",
+		"AT+CNMI?":               "+CNMI: 2,1,0,2,0" + Sep + Sep + "OK",
+		"AT+CLIP=1":              "OK",
+		"AT+CPMS?":               `+CPMS: "SM",0,20,"SM",0,20,"SM",0,20` + Sep + Sep + "OK",
+	})
+
+	assert.Equal(t, SystemModes.LTE, dev.State.SystemMode)
+	assert.Equal(t, ServiceStates.Valid, dev.State.ServiceState)
+	assert.Equal(t, SimStates.Valid, dev.State.SimState)
+	assert.Equal(t, "MTS", dev.State.OperatorName)
+	assert.Equal(t, MemoryTypes.Sim, dev.State.MessageStorage)
+}
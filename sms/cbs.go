@@ -0,0 +1,109 @@
+package sms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlab/at/pdu"
+)
+
+// CellBroadcastLength is the length of a cell broadcast page, 3GPP TS 23.041 section 9.4.1.2.
+const CellBroadcastLength = 88
+
+// cbsLanguages are the languages of the 0000 and 0010 coding groups, 3GPP TS 23.038 section 5.
+var cbsLanguages = map[byte]string{
+	0x00: "de", 0x01: "en", 0x02: "it", 0x03: "fr", 0x04: "es", 0x05: "nl", 0x06: "sv", 0x07: "da",
+	0x08: "pt", 0x09: "fi", 0x0A: "no", 0x0B: "el", 0x0C: "tr", 0x0D: "hu", 0x0E: "pl",
+	0x20: "cs", 0x21: "he", 0x22: "ar", 0x23: "ru", 0x24: "is",
+}
+
+// CellBroadcast is a page of a cell broadcast message (CBS) received over GSM,
+// as specified in 3GPP TS 23.041 section 9.4.1.2. The pages of a multipage
+// message share the serial number and the message ID.
+type CellBroadcast struct {
+	// GeographicalScope, MessageCode and UpdateNumber make up the serial number.
+	GeographicalScope int
+	MessageCode       int
+	UpdateNumber      int
+	// MessageID is the channel of the message, i.e. 4370 for the presidential alerts.
+	MessageID int
+	Encoding  Encoding
+	// Language is the ISO 639 code of the language, if indicated.
+	Language string
+	Page     int
+	Pages    int
+	// Text is the text without the padding, the Data of the 8-bit messages is kept instead.
+	Text string
+	Data []byte
+}
+
+// ReadFrom constructs the page from the supplied PDU octets. Returns the number of bytes read.
+func (c *CellBroadcast) ReadFrom(octets []byte) (n int, err error) {
+	*c = CellBroadcast{}
+	if len(octets) < 7 {
+		return 0, fmt.Errorf("%w: cell broadcast of %d octets", ErrTruncated, len(octets))
+	}
+	if len(octets) > CellBroadcastLength {
+		octets = octets[:CellBroadcastLength]
+	}
+	c.GeographicalScope = int(octets[0] >> 6)
+	c.MessageCode = int(octets[0]&0x3F)<<4 | int(octets[1]>>4)
+	c.UpdateNumber = int(octets[1] & 0x0F)
+	c.MessageID = int(octets[2])<<8 | int(octets[3])
+	dcs := octets[4]
+	c.Page, c.Pages = int(octets[5]>>4), int(octets[5]&0x0F)
+	if c.Page == 0 || c.Pages == 0 {
+		// reserved, a single page
+		c.Page, c.Pages = 1, 1
+	}
+	content := octets[6:]
+
+	c.Encoding = Encodings.Gsm7Bit
+	switch {
+	case dcs&0xF0 == 0x00, dcs&0xF0 == 0x20, dcs&0xF0 == 0x30:
+		c.Language = cbsLanguages[dcs]
+	case dcs == 0x10:
+		// the language is given by the first characters of the text
+	case dcs == 0x11:
+		c.Encoding = Encodings.UCS2
+	case dcs&0xC0 == 0x40 || dcs&0xF0 == 0x90:
+		switch dcs & 0x0C {
+		case 0x04:
+			c.Encoding = Encodings.Data8Bit
+		case 0x08:
+			c.Encoding = Encodings.UCS2
+		}
+	case dcs&0xF0 == 0xF0:
+		if dcs&0x04 != 0 {
+			c.Encoding = Encodings.Data8Bit
+		}
+	default:
+		return len(octets), ErrUnknownEncoding
+	}
+
+	switch c.Encoding {
+	case Encodings.Data8Bit:
+		c.Data = append([]byte(nil), content...)
+		return len(octets), nil
+	case Encodings.UCS2:
+		if dcs == 0x11 {
+			if len(content) < 2 {
+				return len(octets), fmt.Errorf("%w: cell broadcast language", ErrTruncated)
+			}
+			lang, _ := pdu.Decode7Bit(content[:2])
+			c.Language, content = lang, content[2:]
+		}
+		if c.Text, err = pdu.DecodeUcs2(content[:len(content)&^1], false); err != nil {
+			return len(octets), err
+		}
+	default:
+		if c.Text, err = pdu.Decode7Bit(content); err != nil {
+			return len(octets), err
+		}
+		if dcs == 0x10 && len(c.Text) >= 3 {
+			c.Language, c.Text = c.Text[:2], c.Text[3:]
+		}
+	}
+	c.Text = strings.TrimRight(c.Text, "\r\n\x00")
+	return len(octets), nil
+}
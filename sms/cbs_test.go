@@ -0,0 +1,45 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/pdu"
+)
+
+func TestCellBroadcast(t *testing.T) {
+	t.Parallel()
+
+	text := "Emergency alert"
+	content := pdu.Encode7Bit(text + strings.Repeat("\r", 93-len(text)))
+	require.Len(t, content, 82)
+	octets := append([]byte{0x63, 0x40, 0x11, 0x12, 0x01, 0x12}, content...)
+
+	var cb CellBroadcast
+	n, err := cb.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, CellBroadcastLength, n)
+	assert.Equal(t, CellBroadcast{
+		GeographicalScope: 1,
+		MessageCode:       0x234,
+		MessageID:         4370,
+		Encoding:          Encodings.Gsm7Bit,
+		Language:          "en",
+		Page:              1,
+		Pages:             2,
+		Text:              text,
+	}, cb)
+
+	content = append(pdu.EncodeUcs2("Тревога"), 0x00, 0x0D)
+	octets = append([]byte{0x00, 0x10, 0x00, 0x32, 0x48, 0x11}, content...)
+	_, err = cb.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, Encodings.UCS2, cb.Encoding)
+	assert.Equal(t, "Тревога", cb.Text)
+	assert.Equal(t, 50, cb.MessageID)
+
+	_, err = cb.ReadFrom(octets[:5])
+	assert.ErrorIs(t, err, ErrTruncated)
+}
@@ -0,0 +1,128 @@
+package sms
+
+import (
+	"unicode/utf16"
+
+	"github.com/xlab/at/pdu"
+)
+
+// The capacity of the user data of a single message and of a part of the concatenated
+// message, the rest is taken by the concatenation header (3GPP TS 23.040). The header
+// of the application port addressing takes 6 octets more.
+const (
+	MaxSeptets       = 160
+	MaxPartSeptets   = 153
+	MaxUCS2Octets    = 140
+	MaxPartUCS2Units = 67
+	MaxDataOctets    = 140
+	MaxPartOctets    = 134
+)
+
+// Fits7Bit reports whether the text is encodable with the 7-bit default alphabet without
+// replacing characters and fits into a single message, see pdu.Convert7Bit for the rest.
+func Fits7Bit(text string) bool {
+	septets, ok := pdu.DefaultAlphabet.Cost(text)
+	return ok && septets <= MaxSeptets
+}
+
+// Split splits the message into the concatenated parts with the given reference number,
+// each part fits into a single message. The message is returned as is if it fits itself.
+// The extension characters of the 7-bit alphabet and the UTF-16 surrogate pairs are not
+// divided between the parts, the parts keep the application ports and the national
+// language shifts of the message.
+func (s Message) Split(ref byte) ([]Message, error) {
+	partHeader := s.UserDataHeader
+	partHeader.TotalNumber, partHeader.Sequence, partHeader.Tag = 1, 1, int(ref)
+	single, part := s.capacity(s.UserDataHeader, s.UserDataStartsWithHeader), s.capacity(partHeader, true)
+
+	var texts []string
+	var chunks [][]byte
+	switch s.Encoding {
+	case Encodings.Gsm7Bit, Encodings.Gsm7Bit_2:
+		tables := s.UserDataHeader.Tables()
+		if septets, _ := tables.Cost(s.Text); septets <= single {
+			return []Message{s}, nil
+		}
+		texts = splitText(s.Text, part, func(r rune) int {
+			if n := tables.CharCost(r); n > 0 {
+				return n
+			}
+			return 1 // replaced with "?"
+		})
+	case Encodings.UCS2:
+		if len(utf16.Encode([]rune(s.Text))) <= single {
+			return []Message{s}, nil
+		}
+		texts = splitText(s.Text, part, func(r rune) int {
+			return len(utf16.Encode([]rune{r}))
+		})
+	default:
+		if !s.Encoding.is8Bit() {
+			return nil, ErrUnknownEncoding
+		}
+		if len(s.Data) <= single {
+			return []Message{s}, nil
+		}
+		for data := s.Data; len(data) > 0; {
+			n := part
+			if n > len(data) {
+				n = len(data)
+			}
+			chunks, data = append(chunks, data[:n]), data[n:]
+		}
+	}
+	total := len(texts) + len(chunks)
+	if total > 255 {
+		return nil, ErrTooManyParts
+	}
+	parts := make([]Message, total)
+	for i := range parts {
+		parts[i] = s
+		if chunks != nil {
+			parts[i].Data = chunks[i]
+		} else {
+			parts[i].Text = texts[i]
+		}
+		parts[i].UserDataStartsWithHeader = true
+		parts[i].UserDataHeader = partHeader
+		parts[i].UserDataHeader.TotalNumber = total
+		parts[i].UserDataHeader.Sequence = i + 1
+	}
+	return parts, nil
+}
+
+// capacity returns the capacity of the user data left by the header in the units of the
+// encoding: the septets, the UTF-16 code units or the octets.
+func (s Message) capacity(udh UserDataHeader, withHeader bool) int {
+	var n int
+	if withHeader {
+		n = len(udh.Bytes())
+	}
+	switch s.Encoding {
+	case Encodings.Gsm7Bit, Encodings.Gsm7Bit_2:
+		return MaxSeptets - blocks(n*8, 7)
+	case Encodings.UCS2:
+		return (MaxUCS2Octets - n) / 2
+	default:
+		return MaxDataOctets - n
+	}
+}
+
+// splitText splits the text into the chunks of the given capacity, cost is the size of a rune.
+func splitText(text string, capacity int, cost func(r rune) int) (chunks []string) {
+	var chunk []rune
+	var size int
+	for _, r := range text {
+		n := cost(r)
+		if size+n > capacity {
+			chunks = append(chunks, string(chunk))
+			chunk, size = nil, 0
+		}
+		chunk = append(chunk, r)
+		size += n
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, string(chunk))
+	}
+	return
+}
@@ -0,0 +1,145 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	msg := Message{
+		Type:     MessageTypes.Submit,
+		Encoding: Encodings.Gsm7Bit,
+		Address:  "+79269965690",
+		Text:     strings.Repeat("a", 160),
+	}
+	parts, err := msg.Split(7)
+	require.NoError(t, err)
+	assert.Equal(t, []Message{msg}, parts)
+
+	// the escaped characters take two septets and are not divided
+	msg.Text = strings.Repeat("a", 152) + "[" + strings.Repeat("b", 10)
+	parts, err = msg.Split(7)
+	require.NoError(t, err)
+	if assert.Len(t, parts, 2) {
+		assert.Equal(t, strings.Repeat("a", 152), parts[0].Text)
+		assert.Equal(t, "["+strings.Repeat("b", 10), parts[1].Text)
+		assert.Equal(t, UserDataHeader{TotalNumber: 2, Sequence: 2, Tag: 7}, parts[1].UserDataHeader)
+		assert.True(t, parts[1].UserDataStartsWithHeader)
+	}
+
+	_, octets, err := parts[0].PDU()
+	require.NoError(t, err)
+	// SMSC, first octet, MR, address, PID and DCS precede the UDL
+	udl := 1 + 1 + 1 + 8 + 1 + 1
+	assert.EqualValues(t, 0x41, octets[1]&0x41, "UDHI should be set")
+	assert.EqualValues(t, 7+152, octets[udl])
+	assert.Equal(t, []byte{0x05, 0x00, 0x03, 0x07, 0x02, 0x01}, octets[udl+1:udl+7])
+	assert.Len(t, octets[udl+1:], 140, "the user data should be full")
+
+	msg.Encoding = Encodings.UCS2
+	msg.Text = strings.Repeat("ы", 100)
+	parts, err = msg.Split(8)
+	require.NoError(t, err)
+	if assert.Len(t, parts, 2) {
+		assert.Len(t, []rune(parts[0].Text), MaxPartUCS2Units)
+		_, octets, err = parts[1].PDU()
+		require.NoError(t, err)
+		var read Message
+		_, err = read.ReadFrom(octets)
+		require.NoError(t, err)
+		assert.Equal(t, parts[1].Text, read.Text)
+		assert.Equal(t, parts[1].UserDataHeader, read.UserDataHeader)
+	}
+
+	msg.Text = strings.Repeat("ы", 67*256)
+	_, err = msg.Split(9)
+	assert.ErrorIs(t, err, ErrTooManyParts)
+}
+
+func TestReadConcatenated(t *testing.T) {
+	t.Parallel()
+
+	msg := Message{
+		Type:     MessageTypes.Deliver,
+		Encoding: Encodings.Gsm7Bit,
+		Address:  "+79269965690",
+		Text:     strings.Repeat("long text ", 20),
+	}
+	parts, err := msg.Split(0x12)
+	require.NoError(t, err)
+	parts[1].UserDataHeader.Tag = 0x1234
+	for _, part := range parts {
+		_, octets, err := part.PDU()
+		require.NoError(t, err)
+		var read Message
+		_, err = read.ReadFrom(octets)
+		require.NoError(t, err)
+		assert.Equal(t, part.Text, read.Text)
+		assert.Equal(t, part.UserDataHeader, read.UserDataHeader)
+	}
+}
+
+func TestSplitData(t *testing.T) {
+	t.Parallel()
+
+	msg := Message{
+		Type:                     MessageTypes.Submit,
+		Encoding:                 Encodings.Data8Bit,
+		Address:                  "+79269965690",
+		Data:                     make([]byte, 200),
+		UserDataStartsWithHeader: true,
+		UserDataHeader:           UserDataHeader{DestinationPort: 2948, SourcePort: 9200},
+	}
+	for i := range msg.Data {
+		msg.Data[i] = byte(i)
+	}
+	parts, err := msg.Split(5)
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Len(t, parts[0].Data, 140-12)
+
+	var data []byte
+	for _, part := range parts {
+		_, octets, err := part.PDU()
+		require.NoError(t, err)
+		var read Message
+		_, err = read.ReadFrom(octets)
+		require.NoError(t, err)
+		assert.Equal(t, part.UserDataHeader, read.UserDataHeader)
+		data = append(data, read.Data...)
+	}
+	assert.Equal(t, msg.Data, data)
+
+	msg.Data = msg.Data[:133]
+	parts, err = msg.Split(5)
+	require.NoError(t, err)
+	assert.Equal(t, []Message{msg}, parts, "the data should fit with the ports header")
+}
+
+func TestFits7Bit(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Fits7Bit("hello[world]"))
+	assert.True(t, Fits7Bit(strings.Repeat("a", 160)))
+	assert.False(t, Fits7Bit(strings.Repeat("a", 159)+"€"))
+	assert.False(t, Fits7Bit("Zażółć"))
+
+	// the extension characters take two septets in the user data length
+	msg := Message{
+		Type:     MessageTypes.Submit,
+		Encoding: Encodings.Gsm7Bit,
+		Address:  "+79269965690",
+		Text:     "hello[world]",
+	}
+	_, octets, err := msg.PDU()
+	require.NoError(t, err)
+	var decoded Message
+	_, err = decoded.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, "hello[world]", decoded.Text)
+}
@@ -8,6 +8,28 @@ var Encodings = struct {
 	Gsm7Bit   Encoding
 	UCS2      Encoding
 	Gsm7Bit_2 Encoding
+	Data8Bit  Encoding // binary data, i.e. WAP Push, decoded into Message.Data
 }{
-	0x00, 0x08, 0x11,
+	0x00, 0x08, 0x11, 0x04,
+}
+
+// is8Bit reports whether the data coding scheme denotes the 8-bit data, either in
+// the general data coding group (uncompressed) or in the data coding/message class one.
+func (e Encoding) is8Bit() bool {
+	return e&0xEC == 0x04 || e&0xF4 == 0xF4
+}
+
+// MessageClass represents the class bits of the data coding scheme,
+// the zero value means that the message has no class.
+type MessageClass byte
+
+// MessageClasses represent the possible message classes (3GPP TS 23.038).
+var MessageClasses = struct {
+	None  MessageClass
+	Flash MessageClass // class 0, displayed immediately and not stored
+	ME    MessageClass // class 1, stored in the mobile equipment
+	SIM   MessageClass // class 2, stored in the SIM
+	TE    MessageClass // class 3, forwarded to the terminal equipment
+}{
+	0x00, 0x10, 0x11, 0x12, 0x13,
 }
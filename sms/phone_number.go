@@ -3,7 +3,6 @@ package sms
 import (
 	"bytes"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/xlab/at/pdu"
@@ -67,31 +66,51 @@ var NumberingPlans = struct {
 	Reserved:               0b1111,
 }
 
+// ShortCodeMaxDigits is the maximum length of a short code, the shorter numbers without
+// the international prefix are encoded as network-specific.
+var ShortCodeMaxDigits = 6
+
 // PDU returns the number of digits in address and octets of semi-octet encoded address.
 func (p PhoneNumber) PDU() (int, []byte, error) {
-	digitStr := strings.TrimPrefix(string(p), "+")
-	var str string
-	for _, r := range digitStr {
-		if r >= '0' && r <= '9' {
-			str = str + string(r)
-		}
-	}
-	n := len(str)
-	number, err := strconv.ParseUint(str, 10, 64)
-	if err != nil {
-		return 0, nil, err
+	digits := p.digits()
+	if len(digits) == 0 {
+		return 0, nil, fmt.Errorf("%w: %q", ErrInvalidAddress, string(p))
 	}
 	var buf bytes.Buffer
 	buf.WriteByte(p.Type())
-	buf.Write(pdu.EncodeSemi(number))
-	return n, buf.Bytes(), nil
+	buf.Write(pdu.EncodeSemiAddress(digits))
+	return len(digits), buf.Bytes(), nil
+}
+
+// digits returns the digits of the number, including '*' and '#'.
+func (p PhoneNumber) digits() string {
+	var digits []rune
+	for _, r := range strings.TrimPrefix(string(p), "+") {
+		if r >= '0' && r <= '9' || r == '*' || r == '#' {
+			digits = append(digits, r)
+		}
+	}
+	return string(digits)
+}
+
+// IsShortCode reports whether the number is a short code, i.e. a premium service
+// number: up to ShortCodeMaxDigits digits without the international prefix.
+func (p PhoneNumber) IsShortCode() bool {
+	if strings.HasPrefix(string(p), "+") {
+		return false
+	}
+	n := len(p.digits())
+	return n > 0 && n <= ShortCodeMaxDigits
 }
 
 // Type returns the type of address (a combination of type-of-number and
 // numbering-plan-identification). Currently, only national and
-// international E.164 numbers are understood. While ReadFrom() can
-// parse alphanumeric numbers, Type() doesn't recognize it.
+// international E.164 numbers and short codes are understood. While ReadFrom()
+// can parse alphanumeric numbers, Type() doesn't recognize it.
 func (p PhoneNumber) Type() byte {
+	if p.IsShortCode() {
+		return 0x80 | byte(PhoneNumberTypes.NetworkSpecific) | byte(NumberingPlans.Unknown)
+	}
 	typ := PhoneNumberTypes.National
 	if strings.HasPrefix(string(p), "+") {
 		typ = PhoneNumberTypes.International
@@ -116,7 +135,9 @@ func (p *PhoneNumber) ReadFrom(octets []byte) error {
 	case PhoneNumberTypes.International:
 		addr := pdu.DecodeSemiAddress(octets[1:])
 		*p = PhoneNumber("+" + addr)
-	case PhoneNumberTypes.National:
+	case PhoneNumberTypes.National, PhoneNumberTypes.Unknown, PhoneNumberTypes.NetworkSpecific,
+		PhoneNumberTypes.Subscriber, PhoneNumberTypes.Abbreviated:
+		// the short codes are usually network-specific or unknown
 		addr := pdu.DecodeSemiAddress(octets[1:])
 		*p = PhoneNumber(addr)
 	default:
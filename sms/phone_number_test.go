@@ -49,3 +49,23 @@ func TestPhoneNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestShortCode(t *testing.T) {
+	t.Parallel()
+
+	code := PhoneNumber("0611")
+	assert.True(t, code.IsShortCode())
+	n, octets, err := code.PDU()
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, util.MustBytes("B06011"), octets)
+
+	var subject PhoneNumber
+	require.NoError(t, subject.ReadFrom(octets))
+	assert.Equal(t, code, subject)
+
+	assert.False(t, PhoneNumber("+79261234567").IsShortCode())
+	assert.False(t, PhoneNumber("89261234567").IsShortCode())
+	_, _, err = PhoneNumber("abc").PDU()
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
@@ -0,0 +1,42 @@
+package sms
+
+// ProtocolIdentifier represents the TP-Protocol-Identifier (TP-PID) of the message.
+type ProtocolIdentifier byte
+
+// ProtocolIdentifiers represent the common protocol identifiers, 3GPP TS 23.040 section 9.2.3.9.
+var ProtocolIdentifiers = struct {
+	Default ProtocolIdentifier
+	// Type0 is the short message type 0, acknowledged but discarded by the receiver (a silent message).
+	Type0 ProtocolIdentifier
+	// Replace1 to Replace7 are the replace short message types, a message replaces
+	// the stored one of the same type from the same originator.
+	Replace1 ProtocolIdentifier
+	Replace2 ProtocolIdentifier
+	Replace3 ProtocolIdentifier
+	Replace4 ProtocolIdentifier
+	Replace5 ProtocolIdentifier
+	Replace6 ProtocolIdentifier
+	Replace7 ProtocolIdentifier
+	// ReturnCall indicates that the originator may be called back.
+	ReturnCall ProtocolIdentifier
+	// MEDataDownload and SIMDataDownload are handled by the ME and the SIM, i.e. the OTA updates.
+	MEDataDownload  ProtocolIdentifier
+	SIMDataDownload ProtocolIdentifier
+}{
+	0x00, 0x40,
+	0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47,
+	0x5F, 0x7D, 0x7F,
+}
+
+// Replace returns the replace short message type (1 to 7), zero if it's not a replace message.
+func (p ProtocolIdentifier) Replace() int {
+	if p >= ProtocolIdentifiers.Replace1 && p <= ProtocolIdentifiers.Replace7 {
+		return int(p - ProtocolIdentifiers.Type0)
+	}
+	return 0
+}
+
+// IsSIMDataDownload reports whether the message is addressed to the SIM, i.e. an OTA update.
+func (p ProtocolIdentifier) IsSIMDataDownload() bool {
+	return p == ProtocolIdentifiers.SIMDataDownload
+}
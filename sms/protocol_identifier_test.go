@@ -0,0 +1,43 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocolIdentifier(t *testing.T) {
+	t.Parallel()
+
+	submit := Message{
+		Type:               MessageTypes.Submit,
+		Encoding:           Encodings.Gsm7Bit,
+		Address:            "+79269965690",
+		Text:               "Your balance is 100",
+		ProtocolIdentifier: ProtocolIdentifiers.Replace3,
+	}
+	_, octets, err := submit.PDU()
+	require.NoError(t, err)
+	var msg Message
+	_, err = msg.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolIdentifiers.Replace3, msg.ProtocolIdentifier)
+	assert.Equal(t, 3, msg.ProtocolIdentifier.Replace())
+	assert.False(t, msg.ProtocolIdentifier.IsSIMDataDownload())
+
+	deliver := Message{
+		Type:               MessageTypes.Deliver,
+		Encoding:           0xF6, // 8-bit data, class 2 (SIM)
+		Address:            "+79269965690",
+		Data:               []byte{0x02, 0x70, 0x00},
+		ProtocolIdentifier: ProtocolIdentifiers.SIMDataDownload,
+	}
+	_, octets, err = deliver.PDU()
+	require.NoError(t, err)
+	_, err = msg.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.True(t, msg.ProtocolIdentifier.IsSIMDataDownload())
+	assert.Zero(t, msg.ProtocolIdentifier.Replace())
+	assert.Zero(t, ProtocolIdentifiers.Type0.Replace())
+}
@@ -0,0 +1,383 @@
+package sms
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WAPPushPort is the application port of the connectionless WAP Push (WAP-259-WDP).
+const WAPPushPort = 2948
+
+// ErrNotPush is returned when the message doesn't carry a WAP Push.
+var ErrNotPush = errors.New("sms: not a WAP Push message")
+
+// ErrInvalidPush is returned when the WAP Push is malformed.
+var ErrInvalidPush = errors.New("sms: invalid WAP Push")
+
+// The content types of the WAP Push, WAP-230-WSP appendix A.
+const (
+	ContentTypeSI  = "application/vnd.wap.sic"
+	ContentTypeSL  = "application/vnd.wap.slc"
+	ContentTypeMMS = "application/vnd.wap.mms-message"
+)
+
+var wellKnownContentTypes = map[byte]string{
+	0x2E: ContentTypeSI,
+	0x30: ContentTypeSL,
+	0x3E: ContentTypeMMS,
+}
+
+// PushNotification is a decoded WAP Push: a Service Indication, a Service Loading
+// or an MMS notification (m-notification.ind).
+type PushNotification struct {
+	ContentType string
+	// URL is the href of the SI and SL or the Content-Location of the MMS.
+	URL string
+	// Text is the text of the SI or the subject of the MMS.
+	Text string
+	// Action is the action attribute of the SI and SL, i.e. "signal-high".
+	Action string
+	// ID is the si-id of the SI or the transaction ID of the MMS.
+	ID string
+	// From is the sender of the MMS.
+	From string
+	// Size is the size of the MMS in octets.
+	Size int
+	// Body is the undecoded body of the push.
+	Body []byte
+}
+
+// Push decodes the WAP Push carried by the message, the parts of a concatenated
+// message should be reassembled first. ErrNotPush is returned if the message is not
+// addressed to the WAPPushPort. The body of the unknown content types is left undecoded.
+func (s *Message) Push() (*PushNotification, error) {
+	if !s.UserDataStartsWithHeader || s.UserDataHeader.DestinationPort != WAPPushPort || s.Data == nil {
+		return nil, ErrNotPush
+	}
+	r := &pushReader{data: s.Data}
+	r.byte() // transaction ID
+	if pduType := r.byte(); pduType != 0x06 && pduType != 0x07 {
+		return nil, fmt.Errorf("%w: WSP PDU type 0x%02X", ErrInvalidPush, pduType)
+	}
+	headersLen := r.uintvar()
+	if r.err != nil || headersLen > len(r.data)-r.pos {
+		return nil, fmt.Errorf("%w: headers", ErrInvalidPush)
+	}
+	headers := &pushReader{data: r.data[r.pos : r.pos+headersLen]}
+	push := &PushNotification{
+		ContentType: headers.contentType(),
+		Body:        r.data[r.pos+headersLen:],
+	}
+	if headers.err != nil {
+		return nil, headers.err
+	}
+	var err error
+	switch push.ContentType {
+	case ContentTypeSI:
+		err = push.decodeIndication(siTokens)
+	case ContentTypeSL:
+		err = push.decodeIndication(slTokens)
+	case ContentTypeMMS:
+		err = push.decodeMMS()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return push, nil
+}
+
+// pushReader reads the WSP and WBXML encoded values, the first error is kept.
+type pushReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *pushReader) more() bool {
+	return r.err == nil && r.pos < len(r.data)
+}
+
+func (r *pushReader) byte() byte {
+	if !r.more() {
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: unexpected end", ErrInvalidPush)
+		}
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *pushReader) bytes(n int) []byte {
+	if r.err != nil || n > len(r.data)-r.pos {
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: unexpected end", ErrInvalidPush)
+		}
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// uintvar reads a variable length unsigned integer, 7 bits per octet.
+func (r *pushReader) uintvar() int {
+	var v int
+	for i := 0; i < 5; i++ {
+		b := r.byte()
+		v = v<<7 | int(b&0x7F)
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+	r.err = fmt.Errorf("%w: uintvar is too long", ErrInvalidPush)
+	return 0
+}
+
+// text reads a null-terminated string, the quote prefix is dropped.
+func (r *pushReader) text() string {
+	start := r.pos
+	for r.more() && r.data[r.pos] != 0 {
+		r.pos++
+	}
+	str := string(r.data[start:r.pos])
+	r.byte()
+	return strings.TrimPrefix(strings.TrimPrefix(str, "\x7F"), `"`)
+}
+
+// valueLength reads the length of a general form value.
+func (r *pushReader) valueLength() int {
+	if n := r.byte(); n < 31 {
+		return int(n)
+	}
+	return r.uintvar()
+}
+
+// skip skips a header value of any form.
+func (r *pushReader) skip() {
+	if !r.more() {
+		r.byte()
+		return
+	}
+	switch b := r.data[r.pos]; {
+	case b >= 0x80:
+		r.pos++
+	case b <= 31:
+		r.bytes(r.valueLength())
+	default:
+		r.text()
+	}
+}
+
+// contentType reads the content type, either well-known, textual or of the general form.
+func (r *pushReader) contentType() string {
+	if !r.more() {
+		r.byte()
+		return ""
+	}
+	switch b := r.data[r.pos]; {
+	case b >= 0x80:
+		r.pos++
+		if ct, ok := wellKnownContentTypes[b&0x7F]; ok {
+			return ct
+		}
+		return fmt.Sprintf("0x%02X", b&0x7F)
+	case b <= 31:
+		value := &pushReader{data: r.bytes(r.valueLength())}
+		ct := value.contentType()
+		if r.err == nil {
+			r.err = value.err
+		}
+		return ct
+	default:
+		return r.text()
+	}
+}
+
+// longInteger reads a multi-octet integer preceded by its length, or a short integer.
+func (r *pushReader) longInteger() int {
+	if r.more() && r.data[r.pos] >= 0x80 {
+		return int(r.byte() & 0x7F)
+	}
+	var v int
+	for _, b := range r.bytes(int(r.byte())) {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// encodedString reads a text string, optionally preceded by the length and the charset.
+func (r *pushReader) encodedString() string {
+	if !r.more() || r.data[r.pos] > 31 {
+		return r.text()
+	}
+	value := &pushReader{data: r.bytes(r.valueLength())}
+	value.skip() // charset
+	return value.text()
+}
+
+// decodeMMS decodes the headers of an m-notification.ind, OMA-MMS-ENC section 7.
+func (p *PushNotification) decodeMMS() error {
+	r := &pushReader{data: p.Body}
+	for r.more() {
+		switch field := r.byte(); field {
+		case 0x8C: // X-Mms-Message-Type
+			if t := r.byte(); t != 0x82 && r.err == nil {
+				return fmt.Errorf("%w: MMS message type 0x%02X", ErrInvalidPush, t)
+			}
+		case 0x98: // X-Mms-Transaction-Id
+			p.ID = r.text()
+		case 0x83: // X-Mms-Content-Location
+			p.URL = r.text()
+		case 0x96: // Subject
+			p.Text = r.encodedString()
+		case 0x8E: // X-Mms-Message-Size
+			p.Size = r.longInteger()
+		case 0x89: // From
+			from := &pushReader{data: r.bytes(r.valueLength())}
+			if from.byte() == 0x80 { // address present
+				p.From = strings.TrimSuffix(from.encodedString(), "/TYPE=PLMN")
+			}
+		default:
+			if field < 0x80 {
+				return fmt.Errorf("%w: MMS header 0x%02X", ErrInvalidPush, field)
+			}
+			r.skip()
+		}
+	}
+	return r.err
+}
+
+// wbxmlTokens are the tokens of a WBXML document type (code page 0).
+type wbxmlTokens struct {
+	// attributes map the attribute start tokens to "name=value prefix".
+	attributes map[byte]string
+	// values map the attribute value tokens to their strings.
+	values map[byte]string
+}
+
+var urlValues = map[byte]string{0x85: ".com/", 0x86: ".edu/", 0x87: ".net/", 0x88: ".org/"}
+
+// siTokens are the tokens of the Service Indication, WAP-167-ServiceInd section 8.3.
+var siTokens = wbxmlTokens{
+	attributes: map[byte]string{
+		0x05: "action=signal-none", 0x06: "action=signal-low", 0x07: "action=signal-medium",
+		0x08: "action=signal-high", 0x09: "action=delete", 0x0A: "created=", 0x0B: "href=",
+		0x0C: "href=http://", 0x0D: "href=http://www.", 0x0E: "href=https://",
+		0x0F: "href=https://www.", 0x10: "si-expires=", 0x11: "si-id=", 0x12: "class=",
+	},
+	values: urlValues,
+}
+
+// slTokens are the tokens of the Service Loading, WAP-168-ServiceLoad section 9.3.
+var slTokens = wbxmlTokens{
+	attributes: map[byte]string{
+		0x05: "action=execute-low", 0x06: "action=execute-high", 0x07: "action=cache",
+		0x08: "href=", 0x09: "href=http://", 0x0A: "href=http://www.",
+		0x0B: "href=https://", 0x0C: "href=https://www.",
+	},
+	values: urlValues,
+}
+
+// decodeIndication decodes the SI or SL document: the attributes of all elements
+// are collected and the text content is joined.
+func (p *PushNotification) decodeIndication(tokens wbxmlTokens) error {
+	r := &pushReader{data: p.Body}
+	r.byte() // version
+	r.uintvar()
+	r.uintvar() // charset, UTF-8 is assumed
+	strtbl := r.bytes(r.uintvar())
+	str := func(offset int) string {
+		if offset > len(strtbl) {
+			r.err = fmt.Errorf("%w: string table offset %d", ErrInvalidPush, offset)
+			return ""
+		}
+		end := offset
+		for end < len(strtbl) && strtbl[end] != 0 {
+			end++
+		}
+		return string(strtbl[offset:end])
+	}
+
+	attrs := make(map[string]string)
+	var text strings.Builder
+	var depth int
+	for r.more() {
+		tag := r.byte()
+		switch tag {
+		case 0x01: // END
+			depth--
+			continue
+		case 0x03: // STR_I
+			text.WriteString(r.text())
+			continue
+		case 0x83: // STR_T
+			text.WriteString(str(r.uintvar()))
+			continue
+		case 0xC3: // OPAQUE
+			r.bytes(r.uintvar())
+			continue
+		}
+		if tag&0x80 != 0 {
+			var name string
+			var value strings.Builder
+			for r.more() {
+				token := r.byte()
+				switch {
+				case token == 0x01: // END
+				case token == 0x03:
+					value.WriteString(r.text())
+					continue
+				case token == 0x83:
+					value.WriteString(str(r.uintvar()))
+					continue
+				case token == 0xC3:
+					value.WriteString(hex.EncodeToString(r.bytes(r.uintvar())))
+					continue
+				case token >= 0x80:
+					value.WriteString(tokens.values[token])
+					continue
+				}
+				if name != "" {
+					attrs[name] = value.String()
+				}
+				if token == 0x01 {
+					break
+				}
+				start, ok := tokens.attributes[token]
+				if !ok {
+					return fmt.Errorf("%w: attribute 0x%02X", ErrInvalidPush, token)
+				}
+				var prefix string
+				name, prefix, _ = cut(start, "=")
+				value.Reset()
+				value.WriteString(prefix)
+			}
+		}
+		if tag&0x40 != 0 {
+			depth++
+		}
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if depth != 0 {
+		return fmt.Errorf("%w: unbalanced document", ErrInvalidPush)
+	}
+	p.URL = attrs["href"]
+	p.Action = attrs["action"]
+	p.ID = attrs["si-id"]
+	p.Text = strings.TrimSpace(text.String())
+	return nil
+}
+
+// cut is strings.Cut of the newer Go versions.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,94 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushDeliver returns the SMS-DELIVER PDU of the WAP Push with the given WSP PDU.
+func pushDeliver(wsp []byte) []byte {
+	udh := []byte{0x06, 0x05, 0x04, 0x0B, 0x84, 0x23, 0xF0}
+	octets := []byte{0x00, 0x40, 0x0B, 0x91, 0x97, 0x62, 0x99, 0x56, 0x96, 0xF0, 0x00, 0x04,
+		0x41, 0x60, 0x62, 0x91, 0x40, 0x15, 0x61, byte(len(udh) + len(wsp))}
+	return append(append(octets, udh...), wsp...)
+}
+
+func TestPushSI(t *testing.T) {
+	t.Parallel()
+
+	wsp := []byte{0x01, 0x06, 0x01, 0xAE,
+		0x02, 0x05, 0x6A, 0x00, 0x45, 0xC6,
+		0x0D, 0x03, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0x00, 0x85, 0x03, 'a', 0x00,
+		0x11, 0x03, '4', '2', 0x00, 0x08, 0x01,
+		0x03, 'H', 'e', 'l', 'l', 'o', 0x00, 0x01, 0x01}
+	var msg Message
+	_, err := msg.ReadFrom(pushDeliver(wsp))
+	require.NoError(t, err)
+	assert.Equal(t, WAPPushPort, msg.UserDataHeader.DestinationPort)
+	assert.Equal(t, 0x23F0, msg.UserDataHeader.SourcePort)
+	assert.Empty(t, msg.Text)
+	assert.Equal(t, wsp, msg.Data)
+
+	push, err := msg.Push()
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeSI, push.ContentType)
+	assert.Equal(t, "http://www.example.com/a", push.URL)
+	assert.Equal(t, "Hello", push.Text)
+	assert.Equal(t, "signal-high", push.Action)
+	assert.Equal(t, "42", push.ID)
+
+	msg.Data = wsp[:len(wsp)-1]
+	_, err = msg.Push()
+	assert.ErrorIs(t, err, ErrInvalidPush)
+	msg.UserDataHeader.DestinationPort = 0
+	_, err = msg.Push()
+	assert.ErrorIs(t, err, ErrNotPush)
+}
+
+func TestPushSL(t *testing.T) {
+	t.Parallel()
+
+	wsp := []byte{0x01, 0x06, 0x01, 0xB0,
+		0x02, 0x06, 0x6A, 0x04, 'a', 'p', 'p', 0x00, 0x85,
+		0x06, 0x0B, 0x83, 0x00, 0x87, 0x01}
+	msg := Message{
+		UserDataStartsWithHeader: true,
+		UserDataHeader:           UserDataHeader{DestinationPort: WAPPushPort},
+		Data:                     wsp,
+	}
+	push, err := msg.Push()
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeSL, push.ContentType)
+	assert.Equal(t, "https://app.net/", push.URL)
+	assert.Equal(t, "execute-high", push.Action)
+}
+
+func TestPushMMS(t *testing.T) {
+	t.Parallel()
+
+	from := "+79261234567/TYPE=PLMN"
+	body := []byte{0x8C, 0x82, 0x98, 'T', '1', 0x00, 0x8D, 0x92, 0x89, byte(len(from) + 2), 0x80}
+	body = append(body, from...)
+	body = append(body, 0x00, 0x96, 'H', 'i', 0x00, 0x8E, 0x02, 0x01, 0x00,
+		0x88, 0x05, 0x81, 0x03, 0x03, 0xF4, 0x80, 0x83)
+	body = append(body, "http://mms/x"...)
+	body = append(body, 0x00)
+	msg := Message{
+		UserDataStartsWithHeader: true,
+		UserDataHeader:           UserDataHeader{DestinationPort: WAPPushPort},
+		Data:                     append([]byte{0x01, 0x06, 0x03, 0xBE, 0xAF, 0x84}, body...),
+	}
+	push, err := msg.Push()
+	require.NoError(t, err)
+	assert.Equal(t, &PushNotification{
+		ContentType: ContentTypeMMS,
+		URL:         "http://mms/x",
+		Text:        "Hi",
+		ID:          "T1",
+		From:        "+79261234567",
+		Size:        256,
+		Body:        body,
+	}, push)
+}
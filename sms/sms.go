@@ -4,8 +4,8 @@ package sms
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
-	"unicode/utf8"
 
 	"github.com/xlab/at/pdu"
 )
@@ -15,9 +15,12 @@ var (
 	ErrUnknownEncoding               = errors.New("sms: unsupported encoding")
 	ErrUnknownMessageType            = errors.New("sms: unsupported message type")
 	ErrIncorrectSize                 = errors.New("sms: decoded incorrect size of field")
-	ErrNonRelative                   = errors.New("sms: non-relative validity period support is not implemented yet")
+	ErrNonRelative                   = errors.New("sms: enhanced validity period support is not implemented yet")
 	ErrIncorrectUserDataHeaderLength = errors.New("sms: incorrect user data header length ")
 	ErrUnsupportedTypeOfNumber       = errors.New("sms: unsupported type-of-number")
+	ErrInvalidAddress                = errors.New("sms: invalid address")
+	ErrTooManyParts                  = errors.New("sms: text doesn't fit into 255 concatenated messages")
+	ErrTruncated                     = errors.New("sms: PDU is shorter than its length fields declare")
 )
 
 // Message represents an SMS message, including some advanced fields. This
@@ -26,17 +29,22 @@ var (
 type Message struct {
 	Type                 MessageType
 	Encoding             Encoding
+	Class                MessageClass
 	VP                   ValidityPeriod
 	VPFormat             ValidityPeriodFormat
+	ValidUntil           Timestamp // the absolute validity period
 	ServiceCenterTime    Timestamp
 	DischargeTime        Timestamp
 	ServiceCenterAddress PhoneNumber
 	Address              PhoneNumber
 	Text                 string
-	UserDataHeader       UserDataHeader
+	// Data is the user data of the 8-bit messages without the header, the Text is empty then.
+	Data           []byte
+	UserDataHeader UserDataHeader
 
 	// Advanced
 	MessageReference         byte
+	ProtocolIdentifier       ProtocolIdentifier
 	Status                   Status
 	ReplyPathExists          bool
 	UserDataStartsWithHeader bool
@@ -117,8 +125,8 @@ func (s *Message) encodeDeliver(buf *bytes.Buffer) (n int, err error) {
 	addrBuf.Write(addr)
 	sms.OriginatingAddress = addrBuf.Bytes()
 
-	sms.ProtocolIdentifier = 0x00 // Short Message Type 0
-	sms.DataCodingScheme = byte(s.Encoding)
+	sms.ProtocolIdentifier = byte(s.ProtocolIdentifier)
+	sms.DataCodingScheme = byte(s.Encoding) | byte(s.Class)
 	sms.ServiceCentreTimestamp = s.ServiceCenterTime.PDU()
 	sms.UserData, sms.UserDataLength, err = s.encodedUserData()
 	if err != nil {
@@ -147,13 +155,15 @@ func (s *Message) encodeSubmit(buf *bytes.Buffer) (n int, err error) {
 	addrBuf.Write(addr)
 	sms.DestinationAddress = addrBuf.Bytes()
 
-	sms.ProtocolIdentifier = 0x00 // Short Message Type 0
-	sms.DataCodingScheme = byte(s.Encoding)
+	sms.ProtocolIdentifier = byte(s.ProtocolIdentifier)
+	sms.DataCodingScheme = byte(s.Encoding) | byte(s.Class)
 
 	switch s.VPFormat {
 	case ValidityPeriodFormats.Relative:
-		sms.ValidityPeriod = byte(s.VP.Octet())
-	case ValidityPeriodFormats.Absolute, ValidityPeriodFormats.Enhanced:
+		sms.ValidityPeriod = []byte{s.VP.Octet()}
+	case ValidityPeriodFormats.Absolute:
+		sms.ValidityPeriod = s.ValidUntil.PDU()
+	case ValidityPeriodFormats.Enhanced:
 		return 0, ErrNonRelative
 	}
 
@@ -206,6 +216,9 @@ func (s *Message) ReadFrom(octets []byte) (n int, err error) {
 	if scLen > 16 {
 		return 0, ErrIncorrectSize
 	}
+	if int(scLen) >= len(octets)-1 {
+		return n, fmt.Errorf("%w: SMSC length is %d octets, %d follow", ErrTruncated, scLen, len(octets)-1)
+	}
 	addr := make([]byte, scLen)
 	off, err := io.ReadFull(buf, addr)
 	n += off
@@ -237,15 +250,35 @@ func (s *Message) ReadFrom(octets []byte) (n int, err error) {
 	}
 
 	n += decBytes
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = fmt.Errorf("%w: %d octets", ErrTruncated, len(octets))
+	}
 	return n, err
 }
 
+// checkUserData validates the TP-UDL field against the octets of the user data,
+// which is counted in septets for the GSM 7-bit alphabet (bits 3-2 of the DCS).
+func checkUserData(dcs, udl byte, data []byte) error {
+	want := int(udl)
+	if dcs&0x0C == 0 {
+		want = (int(udl)*7 + 7) / 8
+	}
+	if len(data) < want {
+		return fmt.Errorf("%w: TP-UDL is %d, needs %d octets of user data, %d present",
+			ErrTruncated, udl, want, len(data))
+	}
+	return nil
+}
+
 func (s *Message) decodeDeliver(data []byte) (n int, err error) {
 	var sms smsDeliver
 	n, err = sms.FromBytes(data)
 	if err != nil {
 		return
 	}
+	if err = checkUserData(sms.DataCodingScheme, sms.UserDataLength, sms.UserData); err != nil {
+		return
+	}
 	s.MoreMessagesToSend = sms.MoreMessagesToSend
 	s.LoopPrevention = sms.LoopPrevention
 	s.ReplyPathExists = sms.ReplyPath
@@ -257,6 +290,7 @@ func (s *Message) decodeDeliver(data []byte) (n int, err error) {
 		}
 	}
 	s.StatusReportIndication = sms.StatusReportIndication
+	s.ProtocolIdentifier = ProtocolIdentifier(sms.ProtocolIdentifier)
 	s.Address.ReadFrom(sms.OriginatingAddress[1:])
 	s.Encoding = Encoding(sms.DataCodingScheme)
 	s.ServiceCenterTime.ReadFrom(sms.ServiceCentreTimestamp)
@@ -270,25 +304,35 @@ func (s *Message) decodeSubmit(data []byte) (n int, err error) {
 	if err != nil {
 		return
 	}
+	if err = checkUserData(sms.DataCodingScheme, sms.UserDataLength, sms.UserData); err != nil {
+		return
+	}
 	s.RejectDuplicates = sms.RejectDuplicates
 
+	s.VPFormat = ValidityPeriodFormat(sms.ValidityPeriodFormat)
 	switch s.VPFormat {
-	case ValidityPeriodFormats.Absolute, ValidityPeriodFormats.Enhanced:
+	case ValidityPeriodFormats.Relative:
+		s.VP.ReadFrom(sms.ValidityPeriod[0])
+	case ValidityPeriodFormats.Absolute:
+		s.ValidUntil.ReadFrom(sms.ValidityPeriod)
+	case ValidityPeriodFormats.Enhanced:
 		return n, ErrNonRelative
-	default:
-		s.VPFormat = ValidityPeriodFormat(sms.ValidityPeriodFormat)
 	}
 
 	s.MessageReference = sms.MessageReference
 	s.ReplyPathExists = sms.ReplyPath
 	s.UserDataStartsWithHeader = sms.UserDataHeaderIndicator
+	if sms.UserDataHeaderIndicator {
+		err = s.UserDataHeader.ReadFrom(sms.UserData)
+		if err != nil {
+			return
+		}
+	}
 	s.StatusReportRequest = sms.StatusReportRequest
+	s.ProtocolIdentifier = ProtocolIdentifier(sms.ProtocolIdentifier)
 	s.Address.ReadFrom(sms.DestinationAddress[1:])
 	s.Encoding = Encoding(sms.DataCodingScheme)
 
-	if s.VPFormat != ValidityPeriodFormats.FieldNotPresent {
-		s.VP.ReadFrom(sms.ValidityPeriod)
-	}
 	err = s.decodeUserData(sms.UserData, sms.UserDataLength)
 	return n, err
 }
@@ -299,6 +343,9 @@ func (s *Message) decodeStatusReport(data []byte) (n int, err error) {
 	if err != nil {
 		return
 	}
+	if err = checkUserData(sms.DataCodingScheme, sms.UserDataLength, sms.UserData); err != nil {
+		return
+	}
 	s.MessageReference = sms.MessageReference
 	s.MoreMessagesToSend = sms.MoreMessagesToSend
 	s.LoopPrevention = sms.LoopPrevention
@@ -311,6 +358,7 @@ func (s *Message) decodeStatusReport(data []byte) (n int, err error) {
 	}
 	s.StatusReportQualificator = sms.StatusReportQualificator
 	s.Status = Status(sms.Status)
+	s.ProtocolIdentifier = ProtocolIdentifier(sms.ProtocolIdentifier)
 	s.Address.ReadFrom(sms.DestinationAddress[1:])
 	s.Encoding = Encoding(sms.DataCodingScheme)
 	s.ServiceCenterTime.ReadFrom(sms.ServiceCentreTimestamp)
@@ -320,31 +368,76 @@ func (s *Message) decodeStatusReport(data []byte) (n int, err error) {
 }
 
 func (s *Message) encodedUserData() (userData []byte, length byte, err error) {
-	switch s.Encoding {
+	if s.UserDataStartsWithHeader {
+		return s.encodedUserDataHeader()
+	}
+	switch s.Encoding.alphabet() {
 	case Encodings.Gsm7Bit, Encodings.Gsm7Bit_2:
 		userData = pdu.Encode7Bit(s.Text)
-		length = byte(utf8.RuneCountInString(s.Text))
+		septets, _ := pdu.DefaultAlphabet.Cost(s.Text)
+		length = byte(septets)
 	case Encodings.UCS2:
 		userData = pdu.EncodeUcs2(s.Text)
 		length = byte(len(userData))
 	default:
-		err = ErrUnknownEncoding
+		if !s.Encoding.is8Bit() {
+			return nil, 0, ErrUnknownEncoding
+		}
+		userData = s.Data
+		length = byte(len(userData))
 	}
 
 	return
 }
 
+// encodedUserDataHeader encodes the user data preceded by the UserDataHeader.
+func (s *Message) encodedUserDataHeader() (userData []byte, length byte, err error) {
+	header := s.UserDataHeader.Bytes()
+	switch s.Encoding.alphabet() {
+	case Encodings.Gsm7Bit, Encodings.Gsm7Bit_2:
+		tables := s.UserDataHeader.Tables()
+		userData = tables.EncodeHeader(header, s.Text)
+		septets, _ := tables.Cost(s.Text)
+		length = byte(blocks(len(header)*8, 7) + septets)
+	case Encodings.UCS2:
+		userData = append(header, pdu.EncodeUcs2(s.Text)...)
+		length = byte(len(userData))
+	default:
+		if !s.Encoding.is8Bit() {
+			return nil, 0, ErrUnknownEncoding
+		}
+		userData = append(header, s.Data...)
+		length = byte(len(userData))
+	}
+	return
+}
+
 func (s *Message) decodeUserData(data []byte, dataLen byte) (err error) {
-	switch s.Encoding {
+	switch s.Encoding.alphabet() {
 	case Encodings.Gsm7Bit, Encodings.Gsm7Bit_2:
-		if s.Text, err = pdu.Decode7Bit(data); err != nil {
+		var headerSeptets int
+		if s.UserDataStartsWithHeader {
+			s.Text, headerSeptets, err = s.UserDataHeader.Tables().DecodeHeader(data)
+		} else {
+			s.Text, err = pdu.Decode7Bit(data)
+		}
+		if err != nil {
 			return
 		}
-		s.Text = cutStr(s.Text, int(dataLen))
+		if headerSeptets > int(dataLen) {
+			return ErrIncorrectUserDataHeaderLength
+		}
+		s.Text = cutStr(s.Text, int(dataLen)-headerSeptets)
 	case Encodings.UCS2:
 		s.Text, err = pdu.DecodeUcs2(data, s.UserDataStartsWithHeader)
 	default:
-		return ErrUnknownEncoding
+		if !s.Encoding.is8Bit() {
+			return ErrUnknownEncoding
+		}
+		if s.UserDataStartsWithHeader {
+			data = data[int(data[0])+1:]
+		}
+		s.Data = append([]byte(nil), data...)
 	}
 	return err
 }
@@ -32,13 +32,13 @@ func (s *smsDeliver) Bytes() []byte {
 		header |= 0x01 << 3 // 3 bit
 	}
 	if s.StatusReportIndication {
-		header |= 0x01 << 4 // 4 bit
+		header |= 0x01 << 5 // 5 bit
 	}
 	if s.UserDataHeaderIndicator {
-		header |= 0x01 << 5 // 5 bit
+		header |= 0x01 << 6 // 6 bit
 	}
 	if s.ReplyPath {
-		header |= 0x01 << 6 // 6 bit
+		header |= 0x01 << 7 // 7 bit
 	}
 	buf.WriteByte(header)
 	buf.Write(s.OriginatingAddress)
@@ -84,7 +84,7 @@ func (s *smsDeliver) FromBytes(octets []byte) (n int, err error) { //nolint:funl
 	if header>>3&0x01 == 0x01 {
 		s.LoopPrevention = true
 	}
-	if header>>4&0x01 == 0x01 {
+	if header>>5&0x01 == 0x01 {
 		s.StatusReportIndication = true
 	}
 
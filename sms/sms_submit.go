@@ -18,7 +18,7 @@ type smsSubmit struct {
 	DestinationAddress []byte
 	ProtocolIdentifier byte
 	DataCodingScheme   byte
-	ValidityPeriod     byte
+	ValidityPeriod     []byte
 	UserDataLength     byte
 	UserData           []byte
 }
@@ -44,9 +44,7 @@ func (s *smsSubmit) Bytes() []byte {
 	buf.Write(s.DestinationAddress)
 	buf.WriteByte(s.ProtocolIdentifier)
 	buf.WriteByte(s.DataCodingScheme)
-	if ValidityPeriodFormat(s.ValidityPeriodFormat) != ValidityPeriodFormats.FieldNotPresent {
-		buf.WriteByte(s.ValidityPeriod)
-	}
+	buf.Write(s.ValidityPeriod)
 	buf.WriteByte(s.UserDataLength)
 	buf.Write(s.UserData)
 	return buf.Bytes()
@@ -105,12 +103,17 @@ func (s *smsSubmit) FromBytes(octets []byte) (n int, err error) { //nolint:funle
 	if err != nil {
 		return
 	}
-	if ValidityPeriodFormat(s.ValidityPeriodFormat) != ValidityPeriodFormats.FieldNotPresent {
-		s.ValidityPeriod, err = buf.ReadByte()
-		n++
-		if err != nil {
-			return
-		}
+	// the relative format takes one octet, the absolute and enhanced ones take seven
+	switch ValidityPeriodFormat(s.ValidityPeriodFormat) {
+	case ValidityPeriodFormats.Relative:
+		s.ValidityPeriod = make([]byte, 1)
+	case ValidityPeriodFormats.Absolute, ValidityPeriodFormats.Enhanced:
+		s.ValidityPeriod = make([]byte, 7)
+	}
+	off, err = io.ReadFull(buf, s.ValidityPeriod)
+	n += off
+	if err != nil {
+		return
 	}
 	s.UserDataLength, err = buf.ReadByte()
 	n++
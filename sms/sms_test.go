@@ -1,6 +1,7 @@
 package sms
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -212,3 +213,84 @@ func TestSmsStatusReport(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, data, octets)
 }
+
+func TestReadFromTruncated(t *testing.T) {
+	t.Parallel()
+
+	var msg Message
+	for _, str := range []string{pduDeliverUCS2, pduDeliverGsm7, pduSubmitUCS2} {
+		data, err := util.Bytes(str)
+		require.NoError(t, err)
+		for _, n := range []int{1, 5, 12, len(data) - 1} {
+			_, err = msg.ReadFrom(data[:n])
+			assert.ErrorIs(t, err, ErrTruncated, "%d octets of %s", n, str)
+		}
+	}
+}
+
+func TestValidityPeriod(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		vp    ValidityPeriod
+		octet byte
+	}{
+		{ValidityPeriod(5 * time.Minute), 0},
+		{ValidityHours(1), 11},
+		{ValidityHours(18), 155},
+		{ValidityDays(4), 170},
+		{ValidityDays(35), 197},
+	} {
+		assert.Equal(t, tc.octet, tc.vp.Octet(), time.Duration(tc.vp).String())
+		var vp ValidityPeriod
+		vp.ReadFrom(tc.octet)
+		assert.Equal(t, tc.vp, vp)
+	}
+
+	until := time.Date(2014, 9, 8, 12, 0, 0, 0, time.UTC)
+	msg := Message{
+		Type:       MessageTypes.Submit,
+		Address:    "+79269965690",
+		Text:       "hello",
+		VPFormat:   ValidityPeriodFormats.Absolute,
+		ValidUntil: Timestamp(until),
+	}
+	_, octets, err := msg.PDU()
+	require.NoError(t, err)
+	var decoded Message
+	_, err = decoded.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, ValidityPeriodFormats.Absolute, decoded.VPFormat)
+	assert.True(t, until.Equal(time.Time(decoded.ValidUntil)))
+	assert.Equal(t, "hello", decoded.Text)
+}
+
+func TestNationalLanguageShift(t *testing.T) {
+	t.Parallel()
+
+	msg := Message{
+		Type:                     MessageTypes.Deliver,
+		Encoding:                 Encodings.Gsm7Bit,
+		Address:                  "+79269965690",
+		Text:                     "Şifreniz: ığüşöç",
+		UserDataStartsWithHeader: true,
+		UserDataHeader:           UserDataHeader{SingleShift: 0x01, LockingShift: 0x01},
+	}
+	assert.Equal(t, []byte{0x06, 0x24, 0x01, 0x01, 0x25, 0x01, 0x01}, msg.UserDataHeader.Bytes())
+	_, octets, err := msg.PDU()
+	require.NoError(t, err)
+
+	var decoded Message
+	_, err = decoded.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, msg.Text, decoded.Text)
+	assert.Equal(t, msg.UserDataHeader, decoded.UserDataHeader)
+
+	// the concatenated parts keep the shifts, the header takes 6 septets more
+	msg.Text = strings.Repeat("ı", 200)
+	parts, err := msg.Split(1)
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Len(t, []rune(parts[0].Text), 146)
+	assert.EqualValues(t, 0x01, parts[1].UserDataHeader.LockingShift)
+}
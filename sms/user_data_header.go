@@ -1,22 +1,119 @@
 package sms
 
+import "github.com/xlab/at/pdu"
+
+// UserDataHeader holds the concatenation information of a message part, the application
+// ports it's addressed to, the national language tables and the message waiting indications.
 type UserDataHeader struct {
 	TotalNumber int
 	Sequence    int
 	Tag         int
+	// DestinationPort and SourcePort of the application port addressing, i.e. WAPPushPort.
+	DestinationPort int
+	SourcePort      int
+	// SingleShift and LockingShift are the national language identifiers of the shift
+	// tables of the 7-bit text (3GPP TS 23.038 section 6.2.1.2.4), zero for the default ones.
+	SingleShift  byte
+	LockingShift byte
+	// Waiting are the special SMS message indications, they are not encoded by Bytes.
+	Waiting []MessageWaiting
 }
 
+// ReadFrom reads the concatenated short message information element of the header,
+// with either an 8-bit or a 16-bit reference, the application port addressing, the national
+// language shifts and the special SMS message indication (3GPP TS 23.040 section 9.2.3.24).
+// The other information elements are skipped.
 func (udh *UserDataHeader) ReadFrom(octets []byte) error {
 	octetsLng := len(octets)
+	if octetsLng == 0 {
+		return ErrIncorrectUserDataHeaderLength
+	}
 	headerLng := int(octets[0]) + 1
-	if (octetsLng-headerLng) <= 0 || headerLng <= 5 {
+	if octetsLng < headerLng || headerLng < 3 {
 		return ErrIncorrectUserDataHeaderLength
 	}
 
-	h := octets[:headerLng]
-	udh.Sequence = int(h[5])
-	udh.TotalNumber = int(h[4])
-	udh.Tag = int(h[3])
-
+	h := octets[1:headerLng]
+	for len(h) >= 2 {
+		iei, ieLng := h[0], int(h[1])
+		if 2+ieLng > len(h) {
+			return ErrIncorrectUserDataHeaderLength
+		}
+		ie := h[2 : 2+ieLng]
+		switch {
+		case iei == 0x00 && ieLng == 3:
+			udh.Tag = int(ie[0])
+			udh.TotalNumber = int(ie[1])
+			udh.Sequence = int(ie[2])
+		case iei == 0x08 && ieLng == 4:
+			udh.Tag = int(ie[0])<<8 | int(ie[1])
+			udh.TotalNumber = int(ie[2])
+			udh.Sequence = int(ie[3])
+		case iei == 0x04 && ieLng == 2:
+			udh.DestinationPort = int(ie[0])
+			udh.SourcePort = int(ie[1])
+		case iei == 0x05 && ieLng == 4:
+			udh.DestinationPort = int(ie[0])<<8 | int(ie[1])
+			udh.SourcePort = int(ie[2])<<8 | int(ie[3])
+		case iei == 0x24 && ieLng == 1:
+			udh.SingleShift = ie[0]
+		case iei == 0x25 && ieLng == 1:
+			udh.LockingShift = ie[0]
+		case iei == 0x01 && ieLng == 2:
+			udh.Waiting = append(udh.Waiting, readMessageWaiting(ie))
+		}
+		h = h[2+ieLng:]
+	}
 	return nil
 }
+
+// Bytes encodes the header, including the length octet: the concatenated short message
+// information element (3GPP TS 23.040 section 9.2.3.24.1) if the TotalNumber is set, the
+// 16-bit application port addressing (section 9.2.3.24.4) if any port is set and the national
+// language shifts (sections 9.2.3.24.15 and 9.2.3.24.16) if set. The 16-bit reference is used
+// if the Tag doesn't fit into an octet.
+func (udh *UserDataHeader) Bytes() []byte {
+	h := []byte{0x00}
+	switch {
+	case udh.TotalNumber == 0:
+	case udh.Tag > 0xFF:
+		h = append(h, 0x08, 0x04, byte(udh.Tag>>8), byte(udh.Tag), byte(udh.TotalNumber), byte(udh.Sequence))
+	default:
+		h = append(h, 0x00, 0x03, byte(udh.Tag), byte(udh.TotalNumber), byte(udh.Sequence))
+	}
+	if udh.DestinationPort != 0 || udh.SourcePort != 0 {
+		h = append(h, 0x05, 0x04, byte(udh.DestinationPort>>8), byte(udh.DestinationPort),
+			byte(udh.SourcePort>>8), byte(udh.SourcePort))
+	}
+	if udh.SingleShift != 0 {
+		h = append(h, 0x24, 0x01, udh.SingleShift)
+	}
+	if udh.LockingShift != 0 {
+		h = append(h, 0x25, 0x01, udh.LockingShift)
+	}
+	h[0] = byte(len(h) - 1)
+	return h
+}
+
+// Tables returns the 7-bit tables selected by the national language shifts.
+func (udh *UserDataHeader) Tables() pdu.Tables {
+	var t pdu.Tables
+	if udh.LockingShift != 0 {
+		t.Locking = pdu.LanguageAlphabet(udh.LockingShift)
+	}
+	if udh.SingleShift != 0 {
+		t.Single = pdu.LanguageAlphabet(udh.SingleShift)
+	}
+	return t
+}
+
+// SetTables selects the tables with the national language shifts.
+func (udh *UserDataHeader) SetTables(t pdu.Tables) {
+	udh.SingleShift, udh.LockingShift = 0, 0
+	if t.Single != nil {
+		udh.SingleShift = t.Single.Language()
+	}
+	if t.Locking != nil {
+		udh.LockingShift = t.Locking.Language()
+	}
+}
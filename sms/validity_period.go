@@ -19,15 +19,25 @@ var ValidityPeriodFormats = struct {
 // ValidityPeriod represents the validity period of message.
 type ValidityPeriod time.Duration
 
+// ValidityHours returns the relative validity period of the given number of hours.
+func ValidityHours(n int) ValidityPeriod {
+	return ValidityPeriod(time.Duration(n) * time.Hour)
+}
+
+// ValidityDays returns the relative validity period of the given number of days.
+func ValidityDays(n int) ValidityPeriod {
+	return ValidityPeriod(time.Duration(n) * 24 * time.Hour)
+}
+
 // Octet return a one-byte representation of the validity period.
 func (v ValidityPeriod) Octet() byte {
 	switch d := time.Duration(v); {
 	case d/time.Minute < 5:
 		return 0x00
 	case d/time.Hour < 12:
-		return byte(d / (time.Minute * 5))
+		return byte(d/(time.Minute*5) - 1)
 	case d/time.Hour < 24:
-		return byte((d-d/time.Hour*12)/(time.Minute*30) + 143)
+		return byte((d-12*time.Hour)/(time.Minute*30) + 143)
 	case d/time.Hour < 744:
 		days := d / (time.Hour * 24)
 		return byte(days + 166)
@@ -44,7 +54,7 @@ func (v ValidityPeriod) Octet() byte {
 func (v *ValidityPeriod) ReadFrom(oct byte) {
 	switch n := time.Duration(oct); {
 	case n >= 0 && n <= 143:
-		*v = ValidityPeriod(5 * time.Minute * n)
+		*v = ValidityPeriod(5 * time.Minute * (n + 1))
 	case n >= 144 && n <= 167:
 		*v = ValidityPeriod(12*time.Hour + 30*time.Minute*(n-143))
 	case n >= 168 && n <= 196:
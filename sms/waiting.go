@@ -0,0 +1,73 @@
+package sms
+
+// MessageWaitingType is the type of the waiting messages.
+type MessageWaitingType byte
+
+// MessageWaitingTypes represent the types of the message waiting indication,
+// 3GPP TS 23.038 section 4 and TS 23.040 section 9.2.3.24.2.
+var MessageWaitingTypes = struct {
+	Voicemail MessageWaitingType
+	Fax       MessageWaitingType
+	Email     MessageWaitingType
+	Other     MessageWaitingType
+}{
+	0x00, 0x01, 0x02, 0x03,
+}
+
+// MessageWaiting is an indication of the messages waiting in a mailbox, i.e. the voicemail.
+type MessageWaiting struct {
+	Type MessageWaitingType
+	// Active is set while there are waiting messages.
+	Active bool
+	// Count is the number of the waiting messages, zero if unknown: the data coding
+	// scheme indicates only whether there are any.
+	Count int
+	// Store is set if the message carrying the indication should be stored,
+	// otherwise it may be discarded once the indication is updated.
+	Store bool
+}
+
+// readMessageWaiting reads the special SMS message indication element of the header.
+func readMessageWaiting(ie []byte) MessageWaiting {
+	return MessageWaiting{
+		Type:   MessageWaitingType(ie[0] & 0x03),
+		Active: ie[1] > 0,
+		Count:  int(ie[1]),
+		Store:  ie[0]&0x80 != 0,
+	}
+}
+
+// isWaiting reports whether the data coding scheme belongs to the message waiting
+// indication groups: discard (1100), store with the GSM 7-bit (1101) or the UCS2 (1110) text.
+func (e Encoding) isWaiting() bool {
+	return e&0xF0 == 0xC0 || e&0xF0 == 0xD0 || e&0xF0 == 0xE0
+}
+
+// alphabet returns the alphabet of the text of the message waiting indication groups,
+// the other encodings are returned as is.
+func (e Encoding) alphabet() Encoding {
+	switch {
+	case e&0xF0 == 0xE0:
+		return Encodings.UCS2
+	case e.isWaiting():
+		return Encodings.Gsm7Bit
+	}
+	return e
+}
+
+// MessageWaiting returns the message waiting indications carried by the message,
+// either in the special SMS message indication elements of the header or in the
+// data coding scheme. The indications of the header are preferred, they have the count.
+func (s *Message) MessageWaiting() []MessageWaiting {
+	if s.UserDataStartsWithHeader && len(s.UserDataHeader.Waiting) > 0 {
+		return s.UserDataHeader.Waiting
+	}
+	if !s.Encoding.isWaiting() {
+		return nil
+	}
+	return []MessageWaiting{{
+		Type:   MessageWaitingType(s.Encoding & 0x03),
+		Active: s.Encoding&0x08 != 0,
+		Store:  s.Encoding&0xF0 != 0xC0,
+	}}
+}
@@ -0,0 +1,44 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/util"
+)
+
+func TestMessageWaiting(t *testing.T) {
+	t.Parallel()
+
+	// the voicemail is indicated by the data coding scheme of the discard group
+	msg := Message{
+		Type:     MessageTypes.Deliver,
+		Encoding: 0xC8,
+		Address:  "+79269965690",
+	}
+	_, octets, err := msg.PDU()
+	require.NoError(t, err)
+	var read Message
+	_, err = read.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Empty(t, read.Text)
+	assert.Equal(t, []MessageWaiting{{Type: MessageWaitingTypes.Voicemail, Active: true}}, read.MessageWaiting())
+
+	msg.Encoding, msg.Text = 0xE2, "Новое письмо"
+	_, octets, err = msg.PDU()
+	require.NoError(t, err)
+	_, err = read.ReadFrom(octets)
+	require.NoError(t, err)
+	assert.Equal(t, msg.Text, read.Text)
+	assert.Equal(t, []MessageWaiting{{Type: MessageWaitingTypes.Email, Store: true}}, read.MessageWaiting())
+
+	// 3 voicemails are indicated by the header of an empty 8-bit message
+	_, err = read.ReadFrom(util.MustBytes("00440B919762995696F0000441606291401561050401028003"))
+	require.NoError(t, err)
+	assert.Equal(t, []MessageWaiting{{Type: MessageWaitingTypes.Voicemail, Active: true, Count: 3, Store: true}},
+		read.MessageWaiting())
+
+	msg.Encoding, msg.Text = Encodings.Gsm7Bit, "hello"
+	assert.Nil(t, msg.MessageWaiting())
+}
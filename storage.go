@@ -0,0 +1,129 @@
+package at
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/xlab/at/sms"
+)
+
+// storageCommander is implemented by profiles that are able to write the messages
+// into the storage and send them from there.
+type storageCommander interface {
+	CMGW(length int, octets []byte, status Opt) (index uint16, err error)
+	CMSS(index uint16, address sms.PhoneNumber) (ref byte, err error)
+}
+
+// CMGW sends AT+CMGW with the given PDU and status to the device, the message is written
+// into the storage selected by CPMS and its index is returned. The status is left to the
+// device (stored unsent) if its Description is empty.
+func (p *DefaultProfile) CMGW(length int, octets []byte, status Opt) (uint16, error) {
+	i := interaction{
+		cmd: fmt.Sprintf("AT+CMGW=%d", length),
+		stages: []PromptStage{{
+			Prompt:     ">",
+			Payload:    fmt.Sprintf("%02X", octets),
+			Terminator: Sub,
+		}},
+	}
+	if status.Description != "" {
+		i.cmd += fmt.Sprintf(",%d", status.ID)
+	}
+	if p.textMode {
+		cmd, text, err := textSubmission(octets)
+		if err != nil {
+			return 0, err
+		}
+		i.cmd, i.stages[0].Payload = "AT+CMGW"+strings.TrimPrefix(cmd, "AT+CMGS"), text
+		if status.Description != "" {
+			i.cmd += fmt.Sprintf(`,,"%s"`, messageStatuses[status.ID])
+		}
+	}
+	reply, err := p.dev.sendInteractive(i)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(reply, "+CMGW: ") {
+		return 0, fmt.Errorf("unable to get storage index of reply '%s'", reply)
+	}
+	index, err := parseUint16(reply[7:])
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse storage index of reply '%s': %w", reply, err)
+	}
+	return index, nil
+}
+
+// CMSS sends AT+CMSS with the given index to the device, the stored message is sent
+// to the given address or to its own one if the address is empty. The message
+// reference is returned like by CMGS.
+func (p *DefaultProfile) CMSS(index uint16, address sms.PhoneNumber) (byte, error) {
+	req := fmt.Sprintf(`AT+CMSS=%d`, index)
+	if address != "" {
+		req += fmt.Sprintf(`,"%s"`, address)
+	}
+	reply, err := p.dev.Send(req)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(reply, "+CMSS: ") {
+		return 0, fmt.Errorf("unable to get sequence number of reply '%s'", reply)
+	}
+	number, err := parseUint8(reply[7:])
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse sequence number of reply '%s': %w", reply, err)
+	}
+	return byte(number), nil
+}
+
+// StoreSMS writes the message with the given text and address into the storage, so it may be
+// sent later with SendStored without encoding it again. The text that doesn't fit into a single
+// message is stored as the parts of a concatenated message, their indexes are returned in order.
+func (d *Device) StoreSMS(text string, address sms.PhoneNumber, opts ...SendOptions) ([]uint16, error) {
+	s, ok := d.Commands.(storageCommander)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	var o SendOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	parts, err := o.Message(text, address).Split(byte(atomic.AddUint32(&d.concatRef, 1)))
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]uint16, 0, len(parts))
+	for i, msg := range parts {
+		n, octets, err := msg.PDU()
+		if err != nil {
+			return indexes, err
+		}
+		index, err := s.CMGW(n, octets, MessageFlags.Unsent)
+		if err != nil {
+			if len(parts) > 1 {
+				return indexes, fmt.Errorf("at: part %d of %d: %w", i+1, len(parts), err)
+			}
+			return indexes, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// SendStored sends the stored messages with the given indexes, i.e. written by StoreSMS,
+// to the address of each message. The message references are returned in order.
+func (d *Device) SendStored(indexes ...uint16) (refs []byte, err error) {
+	s, ok := d.Commands.(storageCommander)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	defer func() { d.FailurePolicy.result(d, err) }()
+	for _, index := range indexes {
+		ref, err := s.CMSS(index, "")
+		if err != nil {
+			return refs, fmt.Errorf("at: stored message %d: %w", index, err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
@@ -0,0 +1,45 @@
+package at
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreAndSend(t *testing.T) {
+	t.Parallel()
+
+	msg := SendOptions{}.Message("hello", "+79261234567")
+	n, octets, err := msg.PDU()
+	require.NoError(t, err)
+	dev, _ := newTestDevice(t, map[string]string{
+		fmt.Sprintf("AT+CMGW=%d,2", n):    "> ",
+		fmt.Sprintf("%02X", octets) + Sub: "+CMGW: 5" + Sep + Sep + "OK",
+		"AT+CMSS=5":                       "+CMSS: 42" + Sep + Sep + "OK",
+		`AT+CMSS=5,"+79267654321"`:        "+CMSS: 43" + Sep + Sep + "OK",
+		"AT+CMSS=6":                       "+CMS ERROR: 321",
+	})
+
+	indexes, err := dev.StoreSMS("hello", "+79261234567")
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{5}, indexes)
+
+	refs, err := dev.SendStored(5)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{42}, refs)
+	ref, err := dev.Commands.(storageCommander).CMSS(5, "+79267654321")
+	require.NoError(t, err)
+	assert.EqualValues(t, 43, ref)
+
+	refs, err = dev.SendStored(5, 6)
+	assert.Equal(t, []byte{42}, refs)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "stored message 6")
+	}
+
+	dev.Commands = &smsOnlyProfile{}
+	_, err = dev.StoreSMS("hello", "+79261234567")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
@@ -0,0 +1,220 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TelitProfile is the DeviceProfile implementation for Telit LE910 and ME910 modules.
+// They lack the Huawei-specific AT^SYSINFO, so the system info is collected from
+// AT+CREG?, AT#QSS? and AT#MONI. The SIM status changes are reported with #QSS.
+//
+// Telit firmwares reject AT+CNMI as a whole if any of its parameters is out of the
+// range reported by AT+CNMI=?, so the notification settings are checked against
+// the ranges before being tried.
+type TelitProfile struct {
+	DefaultProfile
+}
+
+// telitNotificationConfigs are the AT+CNMI settings supported by Telit modules.
+var telitNotificationConfigs = []CNMIConfig{
+	{Mode: 2, MT: 1, BM: 0, DS: 2, BFR: 1},
+	{Mode: 2, MT: 1, BM: 0, DS: 0, BFR: 1},
+	{Mode: 1, MT: 1, BM: 0, DS: 0, BFR: 0},
+}
+
+// DeviceLE910 returns an instance of DeviceProfile implementation for Telit LE910-series.
+func DeviceLE910() DeviceProfile {
+	return newTelitProfile()
+}
+
+// DeviceME910 returns an instance of DeviceProfile implementation for Telit ME910-series.
+func DeviceME910() DeviceProfile {
+	return newTelitProfile()
+}
+
+func newTelitProfile() *TelitProfile {
+	p := &TelitProfile{}
+	p.NotificationConfigs = telitNotificationConfigs
+	return p
+}
+
+// Init drops the notification settings that are out of the supported ranges,
+// invokes the DefaultProfile's Init and turns on the SIM status notifications.
+func (p *TelitProfile) Init(d *Device) (err error) {
+	p.dev = d
+	p.dev.Send(NoopCmd) // kinda flush
+	if p.NotificationConfigs, err = p.validNotifications(); err != nil {
+		return fmt.Errorf("at init: unable to check message notification settings: %w", err)
+	}
+	if err = p.DefaultProfile.Init(d); err != nil {
+		return
+	}
+	if err = p.QSS(true); err != nil {
+		return fmt.Errorf("at init: unable to turn on SIM status notifications: %w", err)
+	}
+	return nil
+}
+
+// validNotifications returns the notification settings which parameters are within
+// the ranges reported by AT+CNMI=?. The settings are returned as is if the ranges
+// can't be read.
+func (p *TelitProfile) validNotifications() ([]CNMIConfig, error) {
+	candidates := p.NotificationConfigs
+	if len(candidates) == 0 {
+		candidates = DefaultNotificationConfigs
+	}
+	reply, err := p.dev.Send(`AT+CNMI=?`)
+	if err != nil {
+		return candidates, nil
+	}
+	ranges, err := parseRanges(strings.TrimPrefix(reply, "+CNMI:"))
+	if err != nil || len(ranges) < 5 {
+		return candidates, nil
+	}
+	var valid []CNMIConfig
+	for _, cfg := range candidates {
+		values := []int{cfg.Mode, cfg.MT, cfg.BM, cfg.DS, cfg.BFR}
+		supported := true
+		for i, v := range values {
+			supported = supported && ranges[i].Contains(v)
+		}
+		if supported {
+			valid = append(valid, cfg)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, errors.New("no settings within " + strings.TrimSpace(reply))
+	}
+	return valid, nil
+}
+
+// QSS sends AT#QSS to the device, it toggles the #QSS notifications of the SIM status.
+func (p *TelitProfile) QSS(enable bool) (err error) {
+	req := `AT#QSS=0`
+	if enable {
+		req = `AT#QSS=2`
+	}
+	_, err = p.dev.Send(req)
+	return
+}
+
+// qssReport represents the SIM status: #QSS: [<mode>,]<status>.
+type qssReport Opt
+
+func (q *qssReport) Parse(str string) error {
+	fields := strings.Split(strings.TrimPrefix(str, "#QSS:"), ",")
+	status, err := parseUint8(strings.TrimSpace(fields[len(fields)-1]))
+	if err != nil {
+		return err
+	}
+	switch status {
+	case 0:
+		*q = qssReport(SimStates.NoCard)
+	case 3:
+		// inserted and ready for SMS and phonebook
+		*q = qssReport(SimStates.Valid)
+	default:
+		// inserted, but locked or not ready yet
+		*q = qssReport(SimStates.Invalid)
+	}
+	return nil
+}
+
+// CellInfo represents the serving cell reported by AT#MONI.
+type CellInfo struct {
+	// Network is the name of the network operator.
+	Network string
+	// SystemMode is one of GsmGprs, WCDMA or LTE.
+	SystemMode Opt
+	// Area is the hexadecimal location area code or the tracking area code.
+	Area string
+	// CellID is the hexadecimal cell identifier.
+	CellID string
+	// Power is the received signal power in dBm.
+	Power int
+}
+
+// Parse scans the #MONI report of the serving cell, the set of the fields depends
+// on the access technology, i.e.
+//
+//	#MONI: I TIM BSIC:34 RxQual:0 LAC:B3C6 Id:5E05 ARFCN:26 PWR:-65dbm TA:0
+//	#MONI: I TIM RSRP:-99 RSRQ:-13 TAC:5032 Id:35D1E02 EARFCN:1850 PWR:-69dbm DRX:64
+func (c *CellInfo) Parse(str string) error {
+	words := strings.Fields(strings.TrimPrefix(str, "#MONI:"))
+	fields := make(map[string]string)
+	var network []string
+	for _, word := range words {
+		kv := strings.SplitN(word, ":", 2)
+		if len(kv) < 2 {
+			if len(fields) == 0 {
+				network = append(network, word)
+			}
+			continue
+		}
+		fields[kv[0]] = kv[1]
+	}
+	switch {
+	case fields["BSIC"] != "":
+		c.SystemMode = SystemModes.GsmGprs
+		c.Area = fields["LAC"]
+	case fields["PSC"] != "":
+		c.SystemMode = SystemModes.WCDMA
+		c.Area = fields["LAC"]
+	case fields["RSRP"] != "":
+		c.SystemMode = SystemModes.LTE
+		c.Area = fields["TAC"]
+	default:
+		return ErrParseReport
+	}
+	c.Network = strings.Join(network, " ")
+	c.CellID = fields["Id"]
+	power, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(fields["PWR"]), "dbm"))
+	if err != nil {
+		return ErrParseReport
+	}
+	c.Power = power
+	return nil
+}
+
+// MONI sends AT#MONI to the device and returns the info of the serving cell.
+func (p *TelitProfile) MONI() (info *CellInfo, err error) {
+	reply, err := p.dev.Send(`AT#MONI`)
+	if err != nil {
+		return nil, err
+	}
+	info = new(CellInfo)
+	if err = info.Parse(reply); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// SYSINFO collects the system info from AT+CREG?, AT#QSS? and AT#MONI.
+func (p *TelitProfile) SYSINFO() (info *SystemInfoReport, err error) {
+	if info, err = p.registration(); err != nil {
+		return nil, err
+	}
+	reply, err := p.dev.Send(`AT#QSS?`)
+	if err != nil {
+		return nil, err
+	}
+	var qss qssReport
+	if err = qss.Parse(reply); err != nil {
+		return nil, ErrParseReport
+	}
+	info.SimState = Opt(qss)
+
+	if cell, err := p.MONI(); err == nil {
+		info.SystemMode = cell.SystemMode
+	}
+	return info, nil
+}
+
+// SYSCFG is not supported by Telit modules, their access technology is configured
+// with AT+WS46 which values differ between the series.
+func (p *TelitProfile) SYSCFG(roaming, cellular bool) error {
+	return ErrUnsupported
+}
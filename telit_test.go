@@ -0,0 +1,64 @@
+package at
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelitInit(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := newProfileDevice(t, DeviceLE910(), map[string]string{
+		"AT+CNMI=?":              "+CNMI: (0-2),(0-3),(0,2),(0-2),(0)" + Sep + Sep + "OK",
+		"AT+COPS=0,0":            "OK",
+		"AT+CREG?":               "+CREG: 0,5" + Sep + Sep + "OK",
+		"AT#QSS?":                "#QSS: 0,3" + Sep + Sep + "OK",
+		"AT#MONI":                "#MONI: I TIM RSRP:-99 RSRQ:-13 TAC:5032 Id:35D1E02 EARFCN:1850 PWR:-69dbm DRX:64" + Sep + Sep + "OK",
+		"AT+COPS?":               `+COPS: 0,0,"I TIM",7` + Sep + Sep + "OK",
+		"AT+GMM":                 "LE910C1-EU" + Sep + Sep + "OK",
+		"AT+GSN":                 "351234567890123" + Sep + Sep + "OK",
+		"AT+CMGF=0":              "OK",
+		"AT+CPMS=?":              `+CPMS: ("SM","ME"),("SM","ME"),("SM","ME")` + Sep + Sep + "OK",
+		`AT+CPMS="SM","SM","SM"`: "+CPMS: 0,30,0,30,0,30" + Sep + Sep + "OK",
+		// AT+CNMI=2,1,0,2,1 would be rejected
+		"AT+CNMI=1,1,0,0,0": "OK",
+		"AT+CNMI?":          "+CNMI: 1,1,0,0,0" + Sep + Sep + "OK",
+		"AT+CLIP=1":         "OK",
+		"AT+CPMS?":          `+CPMS: "SM",0,20,"SM",0,20,"SM",0,20` + Sep + Sep + "OK",
+		"AT#QSS=2":          "OK",
+	})
+
+	assert.Equal(t, SystemModes.LTE, dev.State.SystemMode)
+	assert.Equal(t, RoamingStates.Roaming, dev.State.RoamingState)
+	assert.Equal(t, SimStates.Valid, dev.State.SimState)
+	assert.Equal(t, "I TIM", dev.State.OperatorName)
+
+	go dev.Watch()
+	_, err := io.WriteString(urc, Sep+"#QSS: 0"+Sep)
+	require.NoError(t, err)
+	select {
+	case <-dev.StateUpdate():
+		assert.Equal(t, SimStates.NoCard, dev.State.SimState)
+	case <-time.After(time.Second):
+		t.Fatal("no state update on #QSS")
+	}
+}
+
+func TestCellInfo(t *testing.T) {
+	t.Parallel()
+
+	var cell CellInfo
+	require.NoError(t, cell.Parse("#MONI: I TIM BSIC:34 RxQual:0 LAC:B3C6 Id:5E05 ARFCN:26 PWR:-65dbm TA:0"))
+	assert.Equal(t, CellInfo{
+		Network:    "I TIM",
+		SystemMode: SystemModes.GsmGprs,
+		Area:       "B3C6",
+		CellID:     "5E05",
+		Power:      -65,
+	}, cell)
+	assert.Error(t, cell.Parse("#MONI: Cc:222 Nc:01"))
+}
@@ -0,0 +1,32 @@
+package at
+
+import "strings"
+
+// Terminator is a non-standard final response that completes a command, i.e.
+// "COMMAND NO RESPONSE!" of some firmwares that don't reply with ERROR after it.
+type Terminator struct {
+	// Prefix of the reply line that completes the command.
+	Prefix string
+	// Err is returned for the command, nil if the response means success.
+	Err error
+}
+
+// terminatorProvider is implemented by profiles that register the non-standard
+// final responses.
+type terminatorProvider interface {
+	terminators() []Terminator
+}
+
+func (p *DefaultProfile) terminators() []Terminator {
+	return p.Terminators
+}
+
+// terminator returns the registered terminator matching the reply line.
+func (d *Device) terminator(text string) (Terminator, bool) {
+	for _, t := range d.terminators {
+		if strings.HasPrefix(text, t.Prefix) {
+			return t, true
+		}
+	}
+	return Terminator{}, false
+}
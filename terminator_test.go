@@ -0,0 +1,31 @@
+package at
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminators(t *testing.T) {
+	t.Parallel()
+
+	errNoResponse := errors.New("no response")
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CIPSTART": "COMMAND NO RESPONSE!",
+		"AT+QPOWD":    "POWERED DOWN",
+	})
+	p := dev.Commands.(*noopProfile)
+	WithTerminators(
+		Terminator{Prefix: "COMMAND NO RESPONSE!", Err: errNoResponse},
+		Terminator{Prefix: "POWERED DOWN"},
+	)(&p.DefaultProfile)
+	require.NoError(t, dev.Init(p))
+
+	_, err := dev.Send("AT+CIPSTART")
+	assert.ErrorIs(t, err, errNoResponse)
+	reply, err := dev.Send("AT+QPOWD")
+	assert.NoError(t, err)
+	assert.Empty(t, reply)
+}
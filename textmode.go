@@ -0,0 +1,238 @@
+package at
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xlab/at/pdu"
+	"github.com/xlab/at/sms"
+)
+
+// The text mode (AT+CMGF=1) is a fallback for the modems with broken PDU support,
+// see DefaultProfile.TextMode. The messages are sent and read as plain text there:
+// only the GSM 7-bit texts are sent, no status reports are requested and the parts
+// of the concatenated messages are received separately.
+
+// splitQuoted splits the comma-separated fields of a reply, the commas inside
+// the quotes are kept and the quotes are removed.
+func splitQuoted(str string) []string {
+	var fields []string
+	var field strings.Builder
+	var quoted bool
+	for _, r := range str {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			fields = append(fields, strings.TrimSpace(field.String()))
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	return append(fields, strings.TrimSpace(field.String()))
+}
+
+// textStatus resolves the status string of the text mode, i.e. "REC UNREAD".
+func textStatus(str string) Opt {
+	for id, status := range messageStatuses {
+		if status == str {
+			return MessageFlags.Resolve(id)
+		}
+	}
+	return UnknownOpt
+}
+
+// parseTextTimestamp parses the timestamp of the text mode: yy/MM/dd,hh:mm:ss±zz,
+// where the zone is given in quarters of an hour.
+func parseTextTimestamp(str string) (time.Time, error) {
+	if len(str) != 20 || (str[17] != '+' && str[17] != '-') {
+		return time.Time{}, ErrParseReport
+	}
+	date, err := time.Parse("06/01/02,15:04:05", str[:17])
+	if err != nil {
+		return time.Time{}, ErrParseReport
+	}
+	quarters, err := strconv.Atoi(str[18:])
+	if err != nil {
+		return time.Time{}, ErrParseReport
+	}
+	offset := quarters * 15 * 60
+	if str[17] == '-' {
+		offset = -offset
+	}
+	year, month, day := date.Date()
+	hour, minute, second := date.Clock()
+	return time.Date(year, month, day, hour, minute, second, 0, time.FixedZone("", offset)), nil
+}
+
+// newTextMessage constructs a received or a stored message of the text mode.
+func newTextMessage(status Opt, address, timestamp, text string) (*sms.Message, error) {
+	msg := &sms.Message{
+		Type:     sms.MessageTypes.Deliver,
+		Encoding: sms.Encodings.Gsm7Bit,
+		Address:  sms.PhoneNumber(address),
+		Text:     text,
+	}
+	if status == MessageFlags.Unsent || status == MessageFlags.Sent {
+		msg.Type = sms.MessageTypes.Submit
+	}
+	if !pdu.Is7BitEncodable(text) {
+		msg.Encoding = sms.Encodings.UCS2
+	}
+	if len(timestamp) > 0 {
+		date, err := parseTextTimestamp(timestamp)
+		if err != nil {
+			return nil, err
+		}
+		msg.ServiceCenterTime = sms.Timestamp(date)
+	}
+	return msg, nil
+}
+
+// splitTextReply splits the reply into the header with the given prefix and the text
+// that follows it. The lines of a multiline text are joined with "\n".
+func splitTextReply(reply, prefix string) (header, text string, err error) {
+	lines := strings.Split(strings.TrimSpace(reply), "\n")
+	if !strings.HasPrefix(lines[0], prefix) {
+		return "", "", ErrParseReport
+	}
+	return strings.TrimSpace(strings.TrimPrefix(lines[0], prefix)), strings.Join(lines[1:], "\n"), nil
+}
+
+// ParseText parses the AT+CMGR reply in the text mode:
+// +CMGR: <stat>,<oa/da>,[<alpha>],[<scts>] followed by the text. The message is
+// returned in Message instead of the Octets, the Length is left zero.
+func (m *StoredMessage) ParseText(reply string) error {
+	header, text, err := splitTextReply(reply, "+CMGR:")
+	if err != nil {
+		return err
+	}
+	fields := splitQuoted(header)
+	if len(fields) < 2 {
+		return ErrParseReport
+	}
+	if m.Status = textStatus(fields[0]); m.Status == UnknownOpt {
+		return ErrParseReport
+	}
+	var timestamp string
+	if len(fields) > 2 {
+		m.Alpha = fields[2]
+	}
+	if len(fields) > 3 && m.Status != MessageFlags.Unsent && m.Status != MessageFlags.Sent {
+		timestamp = fields[3]
+	}
+	m.Message, err = newTextMessage(m.Status, fields[1], timestamp, text)
+	return err
+}
+
+// ParseTextMessageList is the MessageListParser of the text mode. Each header
+// +CMGL: <index>,<stat>,<oa/da>,[<alpha>],[<scts>] is followed by the text, which
+// lasts until the next header. The messages are returned in MessageSlot.Message.
+func ParseTextMessageList(reply string) (result []MessageSlot, err error) {
+	var header string
+	var text []string
+	flush := func() error {
+		if len(header) == 0 {
+			return nil
+		}
+		fields := splitQuoted(header)
+		if len(fields) < 3 {
+			return ErrParseReport
+		}
+		index, err := parseUint16(fields[0])
+		if err != nil {
+			return ErrParseReport
+		}
+		status := textStatus(fields[1])
+		if status == UnknownOpt {
+			return ErrParseReport
+		}
+		var timestamp string
+		if len(fields) > 4 && status != MessageFlags.Unsent && status != MessageFlags.Sent {
+			timestamp = fields[4]
+		}
+		msg, err := newTextMessage(status, fields[2], timestamp, strings.Join(text, "\n"))
+		if err != nil {
+			return err
+		}
+		slot := MessageSlot{
+			Index:   index,
+			Status:  status,
+			Message: msg,
+		}
+		if len(fields) > 3 {
+			slot.Alpha = fields[3]
+		}
+		result = append(result, slot)
+		return nil
+	}
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "+CMGL:"):
+			if err = flush(); err != nil {
+				return nil, err
+			}
+			header, text = strings.TrimSpace(strings.TrimPrefix(line, "+CMGL:")), nil
+		case len(header) == 0:
+			if len(line) > 0 {
+				return nil, ErrParseReport
+			}
+		default:
+			text = append(text, line)
+		}
+	}
+	if err = flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseTextDelivery parses the message routed directly to the terminal in the text mode:
+// +CMT: <oa>,[<alpha>],<scts> followed by the text, the prefix is already removed.
+func parseTextDelivery(str string) (*sms.Message, error) {
+	header, text, err := splitTextReply(str, "")
+	if err != nil {
+		return nil, err
+	}
+	fields := splitQuoted(header)
+	if len(fields) < 3 {
+		return nil, ErrParseReport
+	}
+	return newTextMessage(MessageFlags.Unread, fields[0], fields[2], text)
+}
+
+// textSubmission returns the AT+CMGS command and the text of the text mode
+// for the SMS-SUBMIT PDU.
+func textSubmission(octets []byte) (cmd, text string, err error) {
+	var msg sms.Message
+	if _, err = msg.ReadFrom(octets); err != nil {
+		return "", "", err
+	}
+	switch {
+	case msg.Encoding != sms.Encodings.Gsm7Bit:
+		return "", "", fmt.Errorf("%w: only the GSM 7-bit texts are sent in the text mode", ErrUnknownEncoding)
+	case msg.UserDataStartsWithHeader:
+		return "", "", fmt.Errorf("%w: concatenated messages in the text mode", ErrUnsupported)
+	}
+	return fmt.Sprintf(`AT+CMGS="%s"`, msg.Address), msg.Text, nil
+}
+
+// textReader is implemented by profiles that may read the messages in the text mode.
+type textReader interface {
+	inTextMode() bool
+	readText(index uint16) (*sms.Message, error)
+}
+
+func (p *DefaultProfile) inTextMode() bool {
+	return p.textMode
+}
+
+// textMode reports whether the profile reads the messages in the text mode.
+func (d *Device) textMode() bool {
+	t, ok := d.Commands.(textReader)
+	return ok && t.inTextMode()
+}
@@ -0,0 +1,81 @@
+package at
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+)
+
+func TestParseTextTimestamp(t *testing.T) {
+	t.Parallel()
+
+	date, err := parseTextTimestamp("14/09/07,22:30:00+16")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2014, 9, 7, 18, 30, 0, 0, time.UTC).Equal(date))
+	_, err = parseTextTimestamp("14/09/07 22:30:00")
+	assert.ErrorIs(t, err, ErrParseReport)
+}
+
+func TestParseText(t *testing.T) {
+	t.Parallel()
+
+	var stored StoredMessage
+	require.NoError(t, stored.ParseText(`+CMGR: "REC UNREAD","+79261234567","Alice","14/09/07,22:30:00+16"`+
+		"\nhello,\nworld"))
+	assert.Equal(t, MessageFlags.Unread, stored.Status)
+	assert.Equal(t, "Alice", stored.Alpha)
+	if assert.NotNil(t, stored.Message) {
+		assert.Equal(t, sms.MessageTypes.Deliver, stored.Message.Type)
+		assert.Equal(t, sms.PhoneNumber("+79261234567"), stored.Message.Address)
+		assert.Equal(t, "hello,\nworld", stored.Message.Text)
+	}
+	assert.ErrorIs(t, stored.ParseText(`+CMGR: "DRAFT","+79261234567"`+"\nhello"), ErrParseReport)
+
+	slots, err := ParseTextMessageList(`+CMGL: 1,"REC READ","+79261234567",,"14/09/07,22:30:00+16"` + "\nhello\n" +
+		`+CMGL: 4,"STO UNSENT","+79261234567",` + "\nпривет")
+	require.NoError(t, err)
+	if assert.Len(t, slots, 2) {
+		assert.Equal(t, uint16(1), slots[0].Index)
+		assert.Equal(t, "hello", slots[0].Message.Text)
+		assert.Equal(t, MessageFlags.Read, slots[0].Status)
+		assert.Equal(t, uint16(4), slots[1].Index)
+		assert.Equal(t, MessageFlags.Unsent, slots[1].Status)
+		assert.Equal(t, sms.MessageTypes.Submit, slots[1].Message.Type)
+		assert.Equal(t, sms.Encodings.UCS2, slots[1].Message.Encoding)
+	}
+	_, err = ParseTextMessageList("hello\n" + `+CMGL: 1,"REC READ","+79261234567"`)
+	assert.ErrorIs(t, err, ErrParseReport)
+}
+
+func TestTextMode(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGF=1":              "OK",
+		`AT+CMGS="+79261234567"`: "> ",
+		"hello" + Sub:            "+CMGS: 42" + Sep + Sep + "OK",
+		"AT+CMGR=3":              `+CMGR: "REC UNREAD","+79261234567",,"14/09/07,22:30:00+16"` + Sep + "hi" + Sep + Sep + "OK",
+		"AT+CMGD=3,0":            "OK",
+		"AT+CPMS?":               `+CPMS: "SM",1,5,"SM",1,5,"SM",1,5` + Sep + Sep + "OK",
+		"AT+CMGR=1":              `+CMGR: "REC READ","Beeline",,"14/09/07,22:30:00+16"` + Sep + "balance" + Sep + Sep + "OK",
+		"AT+CMGD=1,0":            "OK",
+	})
+	p := dev.Commands.(*noopProfile)
+	require.NoError(t, p.CMGF(true))
+
+	require.NoError(t, dev.SendSMS("hello", "+79261234567"))
+	assert.ErrorIs(t, dev.SendSMS("привет", "+79261234567"), ErrUnknownEncoding)
+
+	require.NoError(t, dev.handleReport(`+CMTI: "SM",3`))
+	require.NoError(t, dev.handleReport("+CMT: \"+79261234567\",,\"14/09/07,22:30:00+16\"\nhey"))
+	require.NoError(t, dev.Commands.FetchInbox())
+	require.Len(t, dev.IncomingSms(), 3)
+	assert.Equal(t, "hi", (<-dev.IncomingSms()).Text)
+	assert.Equal(t, "hey", (<-dev.IncomingSms()).Text)
+	msg := <-dev.IncomingSms()
+	assert.Equal(t, sms.PhoneNumber("Beeline"), msg.Address)
+	assert.Equal(t, "balance", msg.Text)
+}
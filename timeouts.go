@@ -0,0 +1,58 @@
+package at
+
+import (
+	"strings"
+	"time"
+)
+
+// TimeoutPolicy maps commands to their reply timeouts. A key matches the command
+// either exactly or as its name, i.e. "AT+CMGS" matches "AT+CMGS=23" but "AT" matches
+// only the "AT" itself.
+type TimeoutPolicy map[string]time.Duration
+
+// DefaultCommandTimeouts are the timeouts of long-running commands used by DefaultProfile.
+var DefaultCommandTimeouts = TimeoutPolicy{
+	"AT+COPS=?": 3 * time.Minute,
+	"AT+CMGS":   2 * time.Minute,
+	"AT+CMSS":   2 * time.Minute,
+	"AT+CMGL":   2 * time.Minute,
+}
+
+// Lookup returns the timeout of the given command, the longest matching key wins.
+func (t TimeoutPolicy) Lookup(req string) (timeout time.Duration, ok bool) {
+	var matched int
+	for cmd, d := range t {
+		if len(cmd) <= matched || !strings.HasPrefix(req, cmd) {
+			continue
+		}
+		if len(req) > len(cmd) && !strings.ContainsAny(cmd[len(cmd)-1:], "=?") &&
+			!strings.ContainsAny(req[len(cmd):len(cmd)+1], "=?") {
+			continue
+		}
+		timeout, ok, matched = d, true, len(cmd)
+	}
+	return
+}
+
+// commandTimeouter is implemented by profiles that define per-command timeouts.
+type commandTimeouter interface {
+	CommandTimeout(req string) time.Duration
+}
+
+// CommandTimeout returns the timeout for the given command from the profile's
+// CommandTimeouts, or from DefaultCommandTimeouts. Zero means the device's timeout.
+func (p *DefaultProfile) CommandTimeout(req string) time.Duration {
+	if timeout, ok := p.CommandTimeouts.Lookup(req); ok {
+		return timeout
+	}
+	timeout, _ := DefaultCommandTimeouts.Lookup(req)
+	return timeout
+}
+
+// commandTimeout returns the timeout of the given command defined by the profile.
+func (d *Device) commandTimeout(req string) time.Duration {
+	if p, ok := d.Commands.(commandTimeouter); ok {
+		return p.CommandTimeout(req)
+	}
+	return 0
+}
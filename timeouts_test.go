@@ -0,0 +1,71 @@
+package at
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutPolicyLookup(t *testing.T) {
+	t.Parallel()
+
+	policy := TimeoutPolicy{
+		"AT":        time.Second,
+		"AT+CMGS":   time.Minute,
+		"AT+COPS=?": time.Hour,
+	}
+	for _, tc := range []struct {
+		req     string
+		timeout time.Duration
+		ok      bool
+	}{
+		{"AT", time.Second, true},
+		{"AT+CSQ", 0, false},
+		{"AT+CMGS=23", time.Minute, true},
+		{"AT+CMGSX", 0, false},
+		{"AT+COPS=?", time.Hour, true},
+		{"AT+COPS?", 0, false},
+	} {
+		timeout, ok := policy.Lookup(tc.req)
+		assert.Equal(t, tc.ok, ok, tc.req)
+		assert.Equal(t, tc.timeout, timeout, tc.req)
+	}
+}
+
+// slowConn delays the replies that contain the given text.
+type slowConn struct {
+	net.Conn
+	match string
+	delay time.Duration
+}
+
+func (c slowConn) Write(b []byte) (int, error) {
+	if strings.Contains(string(b), c.match) {
+		time.Sleep(c.delay)
+	}
+	return c.Conn.Write(b)
+}
+
+func TestInteractionTimeout(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := attachTestDevice(t, func(modem, urc net.Conn) {
+		serveModem(slowConn{modem, "+CMGS:", 200 * time.Millisecond}, map[string]string{
+			"AT+CMGS=1": "> ",
+			"01" + Sub:  "+CMGS: 5" + Sep + Sep + "OK",
+		})
+	})
+	dev.Timeout = 50 * time.Millisecond
+	p := &noopProfile{}
+	p.CommandTimeouts = TimeoutPolicy{"AT+CMGS": time.Second}
+	require.NoError(t, dev.Init(p))
+
+	// the reply to the payload is awaited within the timeout of AT+CMGS
+	ref, err := p.CMGS(1, []byte{0x01})
+	require.NoError(t, err)
+	assert.Equal(t, byte(5), ref)
+}
@@ -0,0 +1,168 @@
+package at
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultDialTimeout limits the time spent on establishing network connections.
+const DefaultDialTimeout = 10 * time.Second
+
+// Dialer opens a port by its name and returns a stream that could be used
+// to communicate with the device.
+type Dialer func(name string) (io.ReadWriteCloser, error)
+
+// DialSerial opens the serial port (i.e. a character device like /dev/ttyUSB0) by its path.
+// This is the default Dialer.
+func DialSerial(name string) (io.ReadWriteCloser, error) {
+	return os.OpenFile(name, os.O_RDWR, 0)
+}
+
+// DialTCP connects to a modem port exposed over raw TCP, i.e. by ser2net
+// or a similar remote serial server. The name is an address like "host:port".
+func DialTCP(addr string) (io.ReadWriteCloser, error) {
+	return net.DialTimeout("tcp", addr, DefaultDialTimeout)
+}
+
+// TCPConfig configures the connections to the remote modem ports, i.e. the ser2net
+// bridges in the field reached over a constrained backhaul.
+type TCPConfig struct {
+	// Timeout limits the connection time, DefaultDialTimeout if zero.
+	Timeout time.Duration
+	// KeepAlive is the period of the TCP keepalive probes, so a dead link is noticed
+	// while the port is idle. The system default is used if zero, negative disables them.
+	KeepAlive time.Duration
+	// TLS enables TLS with the given config, the client certificates are set in its
+	// Certificates. The ServerName is taken from the address if empty.
+	TLS *tls.Config
+}
+
+// Dialer returns a Dialer that connects to the address like "host:port" with the config applied.
+func (c TCPConfig) Dialer() Dialer {
+	return func(addr string) (io.ReadWriteCloser, error) {
+		d := &net.Dialer{
+			Timeout:   c.Timeout,
+			KeepAlive: c.KeepAlive,
+		}
+		if d.Timeout == 0 {
+			d.Timeout = DefaultDialTimeout
+		}
+		if c.TLS == nil {
+			return d.Dial("tcp", addr)
+		}
+		return tls.DialWithDialer(d, "tcp", addr, c.TLS)
+	}
+}
+
+// port is a stream that supports read deadlines.
+type port interface {
+	io.ReadWriteCloser
+	SetDeadline(t time.Time) error
+}
+
+// newPort returns the stream itself if it supports deadlines (like *os.File or net.Conn),
+// otherwise wraps it so the reads could be interrupted when the deadline exceeds.
+func newPort(rwc io.ReadWriteCloser) port {
+	if p, ok := rwc.(port); ok {
+		return p
+	}
+	return newDeadlinePort(rwc)
+}
+
+// newDeadlinePort wraps the stream so the reads could be interrupted by a deadline.
+func newDeadlinePort(rwc io.ReadWriteCloser) port {
+	p := &deadlinePort{
+		ReadWriteCloser: rwc,
+		results:         make(chan readResult),
+		done:            make(chan struct{}),
+	}
+	go p.pump()
+	return p
+}
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+// deadlinePort emulates read deadlines for streams that don't support them natively.
+// The reads from the underlying stream are done in background and handed over to Read.
+type deadlinePort struct {
+	io.ReadWriteCloser
+
+	results chan readResult
+	done    chan struct{}
+	once    sync.Once
+
+	mux      sync.Mutex
+	deadline time.Time
+
+	pending []byte
+	err     error
+}
+
+func (p *deadlinePort) pump() {
+	for {
+		buf := make([]byte, 4096)
+		n, err := p.ReadWriteCloser.Read(buf)
+		select {
+		case p.results <- readResult{buf[:n], err}:
+		case <-p.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (p *deadlinePort) Read(b []byte) (int, error) {
+	if len(p.pending) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+		var timeout <-chan time.Time
+		p.mux.Lock()
+		deadline := p.deadline
+		p.mux.Unlock()
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, os.ErrDeadlineExceeded
+			}
+			t := time.NewTimer(d)
+			defer t.Stop()
+			timeout = t.C
+		}
+		select {
+		case r := <-p.results:
+			p.pending, p.err = r.data, r.err
+			if len(p.pending) == 0 {
+				return 0, p.err
+			}
+		case <-timeout:
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+	n := copy(b, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+func (p *deadlinePort) SetDeadline(t time.Time) error {
+	p.mux.Lock()
+	p.deadline = t
+	p.mux.Unlock()
+	return nil
+}
+
+func (p *deadlinePort) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+	return p.ReadWriteCloser.Close()
+}
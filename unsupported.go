@@ -0,0 +1,78 @@
+package at
+
+// UnsupportedCommands implements the commands of DeviceProfile by returning ErrUnsupported.
+// It's meant to be embedded in the profiles of the limited devices, i.e. an SMS-only modem
+// implements Init and SMSCommander and embeds UnsupportedCommands for the rest.
+type UnsupportedCommands struct{}
+
+func (UnsupportedCommands) CMGS(length int, octets []byte) (byte, error) {
+	return 0, ErrUnsupported
+}
+
+func (UnsupportedCommands) CMGR(index uint16) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func (UnsupportedCommands) CMGD(index uint16, option Opt) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) CMGL(flag Opt) ([]MessageSlot, error) {
+	return nil, ErrUnsupported
+}
+
+func (UnsupportedCommands) CMGF(text bool) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) CNMI(mode, mt, bm, ds, bfr int) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) CPMS(mem1 StringOpt, mem2 StringOpt, mem3 StringOpt) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) FetchInbox() error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) CUSD(reporting Opt, octets []byte, enc Encoding) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) CLIP(text bool) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) CHUP() error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) BOOT(token uint64) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) SYSCFG(roaming, cellular bool) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) SYSINFO() (*SystemInfoReport, error) {
+	return nil, ErrUnsupported
+}
+
+func (UnsupportedCommands) COPS(auto bool, text bool) error {
+	return ErrUnsupported
+}
+
+func (UnsupportedCommands) OperatorName() (string, error) {
+	return "", ErrUnsupported
+}
+
+func (UnsupportedCommands) ModelName() (string, error) {
+	return "", ErrUnsupported
+}
+
+func (UnsupportedCommands) IMEI() (string, error) {
+	return "", ErrUnsupported
+}
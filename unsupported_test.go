@@ -0,0 +1,43 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smsOnlyProfile implements only Init and CMGF.
+type smsOnlyProfile struct {
+	UnsupportedCommands
+
+	dev *Device
+}
+
+func (p *smsOnlyProfile) CMGF(text bool) error {
+	if text {
+		return ErrUnsupported
+	}
+	_, err := p.dev.Send(`AT+CMGF=0`)
+	return err
+}
+
+func (p *smsOnlyProfile) Init(d *Device) error {
+	p.dev = d
+	_, err := d.Send(NoopCmd)
+	return err
+}
+
+func TestUnsupportedCommands(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CMGF=0": "OK",
+	})
+	require.NoError(t, dev.Init(&smsOnlyProfile{}))
+
+	assert.NoError(t, dev.Commands.CMGF(false))
+	assert.ErrorIs(t, dev.Commands.CUSD(UssdResultReporting.Enable, nil, Encodings.Gsm7Bit), ErrUnsupported)
+	_, err := dev.Commands.IMEI()
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
@@ -0,0 +1,46 @@
+package at
+
+import "strings"
+
+type subscription struct {
+	prefix string
+	ch     chan string
+}
+
+// Subscribe returns a channel that fires with every unsolicited report from the notification
+// port that starts with the given prefix (i.e. "^HCSQ:" or "+CGEV:"), including the ones
+// handled by the built-in parsers. The channel is buffered, the reports are dropped
+// while it's full, so it should be drained or Unsubscribe used.
+func (d *Device) Subscribe(prefix string) <-chan string {
+	ch := make(chan string, 100)
+	d.subsMux.Lock()
+	d.subs = append(d.subs, subscription{prefix: prefix, ch: ch})
+	d.subsMux.Unlock()
+	return ch
+}
+
+// Unsubscribe stops the reports delivery to the given channel obtained from Subscribe.
+func (d *Device) Unsubscribe(ch <-chan string) {
+	d.subsMux.Lock()
+	defer d.subsMux.Unlock()
+	for i, sub := range d.subs {
+		if sub.ch == ch {
+			d.subs = append(d.subs[:i], d.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// publish sends the report to all the matching subscriptions.
+func (d *Device) publish(report string) {
+	d.subsMux.Lock()
+	defer d.subsMux.Unlock()
+	for _, sub := range d.subs {
+		if strings.HasPrefix(report, sub.prefix) {
+			select {
+			case sub.ch <- report:
+			default:
+			}
+		}
+	}
+}
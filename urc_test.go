@@ -0,0 +1,42 @@
+package at
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := newTestDevice(t, nil)
+	hcsq := dev.Subscribe("^HCSQ:")
+	cgev := dev.Subscribe("+CGEV:")
+	dev.Unsubscribe(cgev)
+	go dev.Watch()
+
+	io.WriteString(urc, Sep+"+CGEV: ME DETACH"+Sep+"^HCSQ: \"LTE\",50,40,120,20"+Sep)
+	select {
+	case report := <-hcsq:
+		assert.Equal(t, `^HCSQ: "LTE",50,40,120,20`, report)
+	case <-time.After(time.Second):
+		t.Fatal("report was not delivered")
+	}
+	assert.Len(t, cgev, 0)
+}
+
+func TestSubscribeFull(t *testing.T) {
+	t.Parallel()
+
+	dev := &Device{}
+	ch := dev.Subscribe("+CGEV:")
+	for i := 0; i < cap(ch)+1; i++ {
+		dev.publish("+CGEV: ME DETACH")
+	}
+	assert.Len(t, ch, cap(ch))
+	dev.Unsubscribe(ch)
+	dev.publish("+CGEV: ME DETACH")
+	assert.Len(t, ch, cap(ch))
+}
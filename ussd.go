@@ -0,0 +1,152 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DefaultUssdTimeout is the wait for the reply of a UssdStep unless specified otherwise.
+const DefaultUssdTimeout = 30 * time.Second
+
+// maxUssdExchanges stops the flows that loop, i.e. repeating a menu forever.
+const maxUssdExchanges = 64
+
+// ErrUssdMismatch is returned when the USSD reply matches none of the branches of a step.
+var ErrUssdMismatch = errors.New("at: unexpected USSD reply")
+
+// UssdStep is a step of a UssdFlow: the request is sent and the reply is matched
+// against the branches in order, the first matching one selects the next step.
+type UssdStep struct {
+	// Name identifies the step for the branches.
+	Name string
+	// Request is the USSD code or the answer to the menu, i.e. "*100#" or "1".
+	Request string
+	// Timeout limits the wait for the reply, DefaultUssdTimeout if zero.
+	Timeout time.Duration
+	// Branches select the next step by the reply, the flow proceeds to the next step
+	// in order if there are no branches.
+	Branches []UssdBranch
+}
+
+// UssdBranch selects the next step of a UssdFlow if the reply matches the Pattern.
+type UssdBranch struct {
+	Pattern *regexp.Regexp
+	// Next is the name of the next step, the flow completes if empty.
+	Next string
+}
+
+// UssdFlow is a scripted USSD dialog, i.e. a purchase of a bundle through the menus.
+// It starts with the first step.
+type UssdFlow []UssdStep
+
+// UssdExchange is a request of a UssdFlow and its reply.
+type UssdExchange struct {
+	Step    string
+	Request string
+	Reply   Ussd
+}
+
+// RunUssd runs the flow on the device and returns the exchanges in order, the session
+// is cancelled with AT+CUSD=2 if the flow fails. The replies are read from UssdReply,
+// so it shouldn't be consumed by anyone else meanwhile.
+func (d *Device) RunUssd(ctx context.Context, flow UssdFlow) (exchanges []UssdExchange, err error) {
+	if len(flow) == 0 {
+		return nil, nil
+	}
+	steps := make(map[string]int, len(flow))
+	for i, step := range flow {
+		steps[step.Name] = i
+	}
+	defer func() {
+		if err != nil {
+			d.Send(`AT+CUSD=2`)
+		}
+	}()
+	for i := 0; ; {
+		if len(exchanges) == maxUssdExchanges {
+			return exchanges, fmt.Errorf("at: USSD flow exceeds %d exchanges", maxUssdExchanges)
+		}
+		step := flow[i]
+		reply, err := d.ussdExchange(ctx, step)
+		if err != nil {
+			return exchanges, fmt.Errorf("at: USSD step %q: %w", step.Name, err)
+		}
+		exchanges = append(exchanges, UssdExchange{Step: step.Name, Request: step.Request, Reply: reply})
+
+		if len(step.Branches) == 0 {
+			if i++; i == len(flow) {
+				return exchanges, nil
+			}
+			continue
+		}
+		next, ok := "", false
+		for _, b := range step.Branches {
+			if b.Pattern.MatchString(string(reply)) {
+				next, ok = b.Next, true
+				break
+			}
+		}
+		switch {
+		case !ok:
+			return exchanges, fmt.Errorf("at: USSD step %q: %w: %s", step.Name, ErrUssdMismatch, reply)
+		case next == "":
+			return exchanges, nil
+		}
+		if i, ok = steps[next]; !ok {
+			return exchanges, fmt.Errorf("at: USSD step %q: unknown next step %q", step.Name, next)
+		}
+	}
+}
+
+// ussdExchange sends the request of the step and waits for the reply.
+func (d *Device) ussdExchange(ctx context.Context, step UssdStep) (Ussd, error) {
+	timeout := step.Timeout
+	if timeout == 0 {
+		timeout = DefaultUssdTimeout
+	}
+	// the replies left from the previous sessions are dropped
+	for len(d.ussd) > 0 {
+		<-d.ussd
+	}
+	if err := d.SendUSSD(step.Request); err != nil {
+		return "", err
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case reply := <-d.ussd:
+		return reply, nil
+	case <-t.C:
+		return "", ErrTimeout
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// UssdResult is the outcome of a UssdFlow run on a device by RunUssdBatch.
+type UssdResult struct {
+	Device    *Device
+	Exchanges []UssdExchange
+	Err       error
+}
+
+// RunUssdBatch runs the flow on each of the devices concurrently, i.e. to activate
+// a bundle across a fleet. The results are returned in the order of the devices.
+func RunUssdBatch(ctx context.Context, devices []*Device, flow UssdFlow) []UssdResult {
+	results := make([]UssdResult, len(devices))
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		wg.Add(1)
+		go func(i int, d *Device) {
+			defer wg.Done()
+			exchanges, err := d.RunUssd(ctx, flow)
+			results[i] = UssdResult{Device: d, Exchanges: exchanges, Err: err}
+		}(i, d)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,132 @@
+package at
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xlab/at/pdu"
+)
+
+// ussdNetwork answers the USSD requests sent to the emulated modem with the +CUSD
+// reports of the menus, a request with an empty menu is left unanswered.
+type ussdNetwork struct {
+	io.ReadWriter
+	urc   io.Writer
+	menus map[string]string
+	req   string
+}
+
+func (n *ussdNetwork) Read(p []byte) (int, error) {
+	c, err := n.ReadWriter.Read(p)
+	n.req += string(p[:c])
+	return c, err
+}
+
+func (n *ussdNetwork) Write(p []byte) (int, error) {
+	c, err := n.ReadWriter.Write(p)
+	req := strings.TrimSpace(n.req)
+	n.req = ""
+	for code, menu := range n.menus {
+		if menu != "" && req == fmt.Sprintf(`AT+CUSD=1,%02X,15`, pdu.Encode7Bit(code)) {
+			go io.WriteString(n.urc, fmt.Sprintf(`%s+CUSD: 1,"%02X",15%s`, Sep, pdu.Encode7Bit(menu), Sep))
+		}
+	}
+	return c, err
+}
+
+func newUssdTestDevice(t *testing.T, menus map[string]string) *Device {
+	t.Helper()
+	replies := map[string]string{`AT+CUSD=2`: "OK"}
+	for code := range menus {
+		replies[fmt.Sprintf(`AT+CUSD=1,%02X,15`, pdu.Encode7Bit(code))] = "OK"
+	}
+	dev, _ := attachTestDevice(t, func(modem, urc net.Conn) {
+		serveModem(&ussdNetwork{ReadWriter: modem, urc: urc, menus: menus}, replies)
+	})
+	require.NoError(t, dev.Init(&noopProfile{}))
+	go dev.Watch()
+	return dev
+}
+
+var bundleFlow = UssdFlow{
+	{Name: "menu", Request: "*100#", Branches: []UssdBranch{
+		{Pattern: regexp.MustCompile(`1\. Bundles`), Next: "bundles"},
+	}},
+	{Name: "bundles", Request: "1", Branches: []UssdBranch{
+		{Pattern: regexp.MustCompile(`Insufficient`)},
+		{Pattern: regexp.MustCompile(`2\. 1GB`), Next: "buy"},
+	}},
+	{Name: "buy", Request: "2"},
+}
+
+func TestRunUssd(t *testing.T) {
+	t.Parallel()
+
+	dev := newUssdTestDevice(t, map[string]string{
+		"*100#": "1. Bundles 2. Balance",
+		"1":     "1. 100MB 2. 1GB",
+		"2":     "The 1GB bundle is activated",
+	})
+	exchanges, err := dev.RunUssd(context.Background(), bundleFlow)
+	require.NoError(t, err)
+	assert.Equal(t, []UssdExchange{
+		{Step: "menu", Request: "*100#", Reply: "1. Bundles 2. Balance"},
+		{Step: "bundles", Request: "1", Reply: "1. 100MB 2. 1GB"},
+		{Step: "buy", Request: "2", Reply: "The 1GB bundle is activated"},
+	}, exchanges)
+
+	dev = newUssdTestDevice(t, map[string]string{
+		"*100#": "1. Bundles 2. Balance",
+		"1":     "Insufficient balance",
+	})
+	exchanges, err = dev.RunUssd(context.Background(), bundleFlow)
+	require.NoError(t, err)
+	assert.Len(t, exchanges, 2)
+}
+
+func TestRunUssdFailure(t *testing.T) {
+	t.Parallel()
+
+	dev := newUssdTestDevice(t, map[string]string{
+		"*100#": "Service unavailable",
+	})
+	exchanges, err := dev.RunUssd(context.Background(), bundleFlow)
+	assert.ErrorIs(t, err, ErrUssdMismatch)
+	assert.Len(t, exchanges, 1)
+
+	dev = newUssdTestDevice(t, map[string]string{
+		"*100#": "1. Bundles 2. Balance",
+		"1":     "",
+	})
+	flow := append(UssdFlow(nil), bundleFlow...)
+	flow[1].Timeout = 50 * time.Millisecond
+	_, err = dev.RunUssd(context.Background(), flow)
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestRunUssdBatch(t *testing.T) {
+	t.Parallel()
+
+	menus := map[string]string{
+		"*100#": "1. Bundles 2. Balance",
+		"1":     "1. 100MB 2. 1GB",
+		"2":     "The 1GB bundle is activated",
+	}
+	devices := []*Device{newUssdTestDevice(t, menus), newUssdTestDevice(t, menus)}
+	results := RunUssdBatch(context.Background(), devices, bundleFlow)
+	require.Len(t, results, 2)
+	for i, result := range results {
+		assert.Equal(t, devices[i], result.Device)
+		assert.NoError(t, result.Err)
+		assert.Len(t, result.Exchanges, 3)
+	}
+}
@@ -0,0 +1,122 @@
+package at
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// VirtualReserved are the commands that are not forwarded from a virtual port to the modem,
+// because they change the settings the package relies on (echo, message format, storage
+// and notifications). They are answered with OK as if they have been applied.
+var VirtualReserved = []string{"ATE", "AT+CMGF=", "AT+CPMS=", "AT+CNMI=", "AT+CMGD=", "AT+CSCS="}
+
+// ServeVirtual serves a virtual command port over the stream for the legacy applications:
+// the commands are forwarded to the modem through the device's command queue and the
+// replies are written back, while the reports stay owned by the device. Interactive commands
+// (i.e. AT+CMGS) are not supported. Blocks until the stream is closed, or a command fails
+// because the device is closed or lost.
+func (d *Device) ServeVirtual(rw io.ReadWriter) error {
+	buf := bufio.NewReader(rw)
+	echo := true
+	for {
+		line, err := buf.ReadString('\r')
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if echo {
+			if _, err = io.WriteString(rw, line); err != nil {
+				return err
+			}
+		}
+		cmd := strings.TrimSpace(line)
+		if len(cmd) == 0 {
+			continue
+		}
+
+		var reply string
+		upper := strings.ToUpper(cmd)
+		switch {
+		case upper == "ATE0" || upper == "ATE1":
+			echo = upper == "ATE1"
+		case reserved(upper):
+			// pretend it's applied
+		default:
+			reply, err = d.Send(cmd)
+			if errors.Is(err, ErrClosed) || errors.Is(err, ErrDeviceLost) {
+				return err
+			}
+		}
+
+		var out strings.Builder
+		if len(reply) > 0 {
+			out.WriteString(Sep + strings.ReplaceAll(reply, "\n", Sep) + Sep)
+		}
+		switch {
+		case err == nil:
+			out.WriteString(Sep + FinalResults.Ok.ID + Sep)
+		case strings.HasPrefix(err.Error(), FinalResults.CmeError.ID),
+			strings.HasPrefix(err.Error(), FinalResults.CmsError.ID):
+			out.WriteString(Sep + err.Error() + Sep)
+		default:
+			out.WriteString(Sep + FinalResults.Error.ID + Sep)
+		}
+		if _, err = io.WriteString(rw, out.String()); err != nil {
+			return err
+		}
+	}
+}
+
+func reserved(cmd string) bool {
+	for _, prefix := range VirtualReserved {
+		if strings.HasPrefix(cmd, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// VirtualPort is a pseudo-terminal served by ServeVirtual, the legacy applications
+// should open it by the Name instead of the modem's port.
+type VirtualPort struct {
+	// Name is the path of the pseudo-terminal, i.e. /dev/pts/3.
+	Name string
+
+	master *os.File
+	done   chan struct{}
+	err    error
+}
+
+// OpenVirtual creates a pseudo-terminal and serves it with ServeVirtual in background.
+// Only supported on Linux.
+func (d *Device) OpenVirtual() (*VirtualPort, error) {
+	master, name, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	v := &VirtualPort{
+		Name:   name,
+		master: master,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		v.err = d.ServeVirtual(master)
+		close(v.done)
+	}()
+	return v, nil
+}
+
+// Close closes the pseudo-terminal and returns the error that stopped the serving, if any.
+func (v *VirtualPort) Close() error {
+	v.master.Close()
+	<-v.done
+	if errors.Is(v.err, os.ErrClosed) {
+		return nil
+	}
+	return v.err
+}
@@ -0,0 +1,62 @@
+package at
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingConn counts the commands read by the emulated modem.
+type countingConn struct {
+	net.Conn
+	commands int32
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	atomic.AddInt32(&c.commands, int32(bytes.Count(p[:n], []byte("\r"))))
+	return n, err
+}
+
+func TestOpenVirtual(t *testing.T) {
+	t.Parallel()
+
+	counted := &countingConn{}
+	dev, _ := attachTestDevice(t, func(modem, urc net.Conn) {
+		counted.Conn = modem
+		serveModem(counted, map[string]string{"AT+GMM": "E173" + Sep + Sep + "OK"})
+	})
+	require.NoError(t, dev.Init(&noopProfile{}))
+
+	v, err := dev.OpenVirtual()
+	require.NoError(t, err)
+	defer v.Close()
+	app, err := os.OpenFile(v.Name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	require.NoError(t, err)
+	defer app.Close()
+
+	before := atomic.LoadInt32(&counted.commands)
+	_, err = io.WriteString(app, "AT+GMM\r")
+	require.NoError(t, err)
+	expected := "AT+GMM\r" + Sep + "E173" + Sep + Sep + "OK" + Sep
+	reply := make([]byte, len(expected))
+	require.NoError(t, app.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = io.ReadFull(app, reply)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(reply))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&counted.commands)-before, "one write should produce exactly one command")
+
+	// nothing else is written back, i.e. the echo of the terminal
+	require.NoError(t, app.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	n, err := app.Read(reply)
+	assert.Zero(t, n)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
@@ -0,0 +1,35 @@
+package at
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeVirtual(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+GMM": "E173" + Sep + Sep + "OK",
+		"AT+GSN": "ERROR",
+	})
+	app, virtual := net.Pipe()
+	defer app.Close()
+	go dev.ServeVirtual(virtual)
+
+	exchange := func(cmd, expected string) {
+		t.Helper()
+		io.WriteString(app, cmd)
+		reply := make([]byte, len(expected))
+		_, err := io.ReadFull(app, reply)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(reply))
+	}
+	exchange("AT+GMM\r", "AT+GMM\r"+Sep+"E173"+Sep+Sep+"OK"+Sep)
+	exchange("ATE0\r", "ATE0\r"+Sep+"OK"+Sep)
+	exchange("AT+CNMI=2,1\r", Sep+"OK"+Sep)
+	exchange("AT+GSN\r", Sep+"ERROR"+Sep)
+}
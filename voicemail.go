@@ -0,0 +1,120 @@
+package at
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xlab/at/sms"
+)
+
+// VoicemailWaiting is reported when the network indicates the messages waiting in a
+// mailbox, either with a message waiting indication SMS or with the +CIEV indicator.
+type VoicemailWaiting struct {
+	Type sms.MessageWaitingType
+	// Active is set while there are waiting messages.
+	Active bool
+	// Count is the number of the waiting messages, zero if unknown.
+	Count int
+	// Address is the sender of the indication SMS, it's empty for +CIEV.
+	Address sms.PhoneNumber
+	// Time when the indication has been received.
+	Time time.Time
+}
+
+// VoicemailWaiting fires when the network indicates the waiting voicemail, fax, email or
+// other messages. The +CIEV indicators are reported only if enabled with AT+CMER.
+// The indications are dropped if the channel is not consumed.
+func (d *Device) VoicemailWaiting() <-chan VoicemailWaiting {
+	return d.voicemail
+}
+
+// messageWaiting reports the indications of the message, it returns whether the message
+// should be delivered too: the empty ones and the ones to be discarded are not.
+func (d *Device) messageWaiting(msg *sms.Message, indications []sms.MessageWaiting) (deliver bool) {
+	now := time.Now()
+	for _, mwi := range indications {
+		select {
+		case d.voicemail <- VoicemailWaiting{
+			Type:    mwi.Type,
+			Active:  mwi.Active,
+			Count:   mwi.Count,
+			Address: msg.Address,
+			Time:    now,
+		}:
+		default:
+		}
+		deliver = deliver || mwi.Store
+	}
+	return deliver && (msg.Text != "" || len(msg.Data) > 0)
+}
+
+// indicatorReport represents +CIEV: <ind>,<value>, the indicator is either the index
+// in the list of AT+CIND=? or its quoted name.
+type indicatorReport struct {
+	Index int
+	Name  string
+	Value int
+}
+
+func (r *indicatorReport) Parse(str string) (err error) {
+	fields := strings.Split(str, ",")
+	if len(fields) < 2 {
+		return ErrParseReport
+	}
+	ind := strings.TrimSpace(fields[0])
+	if strings.HasPrefix(ind, `"`) {
+		r.Name = strings.ToLower(strings.Trim(ind, `"`))
+	} else if r.Index, err = strconv.Atoi(ind); err != nil {
+		return ErrParseReport
+	}
+	if r.Value, err = strconv.Atoi(strings.TrimSpace(fields[1])); err != nil {
+		return ErrParseReport
+	}
+	return nil
+}
+
+var indicatorNameRx = regexp.MustCompile(`\("([^"]*)"`)
+
+// indicatorName returns the name of the indicator by its index, the names are read
+// with AT+CIND=? once.
+func (d *Device) indicatorName(index int) (string, error) {
+	if d.indicators == nil {
+		reply, err := d.Send("AT+CIND=?")
+		if err != nil {
+			return "", err
+		}
+		for _, m := range indicatorNameRx.FindAllStringSubmatch(reply, -1) {
+			d.indicators = append(d.indicators, strings.ToLower(m[1]))
+		}
+	}
+	if index < 1 || index > len(d.indicators) {
+		return "", ErrParseReport
+	}
+	return d.indicators[index-1], nil
+}
+
+// indicator handles the +CIEV report, the voicemail indicators ("vmwait", "vmwait1" and
+// "vmwait2" for the lines) are reported, the others are ignored.
+func (d *Device) indicator(report indicatorReport) error {
+	name := report.Name
+	if name == "" {
+		var err error
+		if name, err = d.indicatorName(report.Index); err != nil {
+			return err
+		}
+	}
+	if !strings.HasPrefix(name, "vmwait") {
+		return nil
+	}
+	select {
+	case d.voicemail <- VoicemailWaiting{
+		Type:   sms.MessageWaitingTypes.Voicemail,
+		Active: report.Value > 0,
+		Time:   time.Now(),
+	}:
+	default:
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package at
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab/at/sms"
+)
+
+func TestVoicemailWaiting(t *testing.T) {
+	t.Parallel()
+
+	dev, _ := newTestDevice(t, map[string]string{
+		"AT+CIND=?": `+CIND: ("battchg",(0-5)),("signal",(0-5)),("vmwait1",(0,1))` + Sep + Sep + "OK",
+	})
+
+	// 3 voicemails are indicated by the header of an empty message, it's not delivered
+	require.NoError(t, dev.handleReport("+CMT: ,25\n00440B919762995696F0000441606291401561050401028003"))
+	vm := <-dev.VoicemailWaiting()
+	assert.Equal(t, sms.MessageWaitingTypes.Voicemail, vm.Type)
+	assert.True(t, vm.Active)
+	assert.Equal(t, 3, vm.Count)
+	assert.Equal(t, sms.PhoneNumber("+79269965690"), vm.Address)
+	assert.Empty(t, dev.IncomingSms())
+
+	require.NoError(t, dev.handleReport(`+CIEV: "VMWAIT1",0`))
+	assert.False(t, (<-dev.VoicemailWaiting()).Active)
+	require.NoError(t, dev.handleReport("+CIEV: 3,1"))
+	assert.True(t, (<-dev.VoicemailWaiting()).Active)
+	require.NoError(t, dev.handleReport("+CIEV: 2,4"), "the signal is ignored")
+	assert.Empty(t, dev.VoicemailWaiting())
+	assert.ErrorIs(t, dev.handleReport("+CIEV: 4,1"), ErrParseReport)
+}
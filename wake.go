@@ -0,0 +1,95 @@
+package at
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// DefaultRingInterval is the default polling interval of the RI line, the modems
+// pulse it for 50-120ms on an incoming SMS.
+const DefaultRingInterval = 20 * time.Millisecond
+
+// WakeSource signals the modem's activity while the device sleeps with its ports closed,
+// see SleepConfig.WakeOn.
+type WakeSource interface {
+	// Wait blocks until the modem signals its activity or the stop channel is closed.
+	Wait(stop <-chan struct{}) error
+}
+
+// WakeFunc adapts a function to the WakeSource, i.e. to wait for an edge on a GPIO
+// that is wired to the modem's RI pin.
+type WakeFunc func(stop <-chan struct{}) error
+
+// Wait calls f(stop).
+func (f WakeFunc) Wait(stop <-chan struct{}) error {
+	return f(stop)
+}
+
+// RingIndicator is the WakeSource that polls the RI (ring indicator) line of the serial port,
+// the modems assert it on an incoming call or SMS.
+type RingIndicator struct {
+	// Port is the path of the serial port, usually the command port.
+	Port string
+	// Interval of polling, DefaultRingInterval if zero.
+	Interval time.Duration
+}
+
+// Wait polls the RI line until it's asserted. Works only with serial ports on Linux.
+func (r *RingIndicator) Wait(stop <-chan struct{}) error {
+	// the port is opened without becoming the controlling terminal and without waiting for DCD
+	f, err := os.OpenFile(r.Port, os.O_RDONLY|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultRingInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		lines, err := modemLines(f)
+		if err != nil {
+			return err
+		}
+		if lines&lineRI != 0 {
+			return nil
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// watchWake wakes up the device when the source signals, unless the device
+// is woken up by other means before. The failures are reported to the FailurePolicy.
+func (d *Device) watchWake(source WakeSource, awake <-chan struct{}) {
+	err := source.Wait(awake)
+	select {
+	case <-awake:
+		return
+	case <-d.closed:
+		return
+	default:
+	}
+	if err == nil {
+		err = d.wakeIfAsleep()
+	}
+	if err != nil {
+		d.FailurePolicy.result(d, err)
+	}
+}
+
+// wakeIfAsleep is like Wake, but does nothing if the device is already awake.
+func (d *Device) wakeIfAsleep() error {
+	d.wakeMux.Lock()
+	defer d.wakeMux.Unlock()
+	if d.asleep() == nil {
+		return nil
+	}
+	return d.Wake()
+}
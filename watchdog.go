@@ -0,0 +1,139 @@
+package at
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRetryInterval is the default period between attempts to reopen a failed device.
+const DefaultRetryInterval = 5 * time.Second
+
+// syncCmd is an artificial command tagged with a sequence number, see resync.
+const syncCmd = "AT_SYNC"
+
+// Watchdog configures the automatic recovery of a device. When the ports fail
+// (i.e. a USB modem was re-enumerated) or the device stops responding, the ports
+// are reopened using the device's Dial function and the profile's Init is invoked again.
+// The event channels stay the same, so the consumers don't need to resubscribe.
+//
+// The recovery is possible only if the device was opened with Open, because the
+// streams passed to Attach cannot be reopened.
+type Watchdog struct {
+	// Silence is the maximum period without any reports from the notification port,
+	// the device is checked with NoopCmd after that. Zero disables the check.
+	Silence time.Duration
+	// RetryInterval overrides the default period between reopen attempts (5s).
+	RetryInterval time.Duration
+}
+
+// portError signals the watchdog that the connection has failed. Timeouts are not
+// considered as failures. The passed error is returned as is, or wrapped with ErrDeviceLost
+// if the port has gone.
+func (d *Device) portError(err error) error {
+	if err != nil && !os.IsTimeout(err) {
+		select {
+		case d.failures <- err:
+		default:
+		}
+		return d.deviceLost(err)
+	}
+	return err
+}
+
+// recover reopens the ports and initializes the device again, retrying until
+// succeeded or the device was closed. Returns false if the recovery is not possible.
+func (d *Device) recover() bool {
+	if d.CommandPort == "" || d.Commands == nil {
+		return false
+	}
+	interval := d.Watchdog.RetryInterval
+	if interval == 0 {
+		interval = DefaultRetryInterval
+	}
+	d.transition(LifecycleStates.Reconnecting, nil)
+	d.cmdMux.Lock()
+	d.closePorts()
+	d.cmdMux.Unlock()
+
+	t := time.NewTimer(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-d.closed:
+			return false
+		case <-t.C:
+		}
+		if d.reopen() {
+			break
+		}
+		t.Reset(interval)
+	}
+	d.resetFailures()
+	select {
+	case d.reconnected <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// reopen opens the ports and runs the profile's Init, reports whether it succeeded.
+func (d *Device) reopen() bool {
+	d.cmdMux.Lock()
+	err := d.open()
+	if err == nil {
+		if err = d.resync(); err != nil {
+			d.closePorts()
+		}
+	}
+	d.cmdMux.Unlock()
+	if err != nil {
+		return false
+	}
+	if err = d.initProfile(d.Commands); err != nil {
+		d.cmdMux.Lock()
+		d.closePorts()
+		d.cmdMux.Unlock()
+		return false
+	}
+	return true
+}
+
+// resync discards the stale replies to the commands sent before the reconnect, i.e. buffered
+// by a network bridge, so they are not taken for the replies to the next commands. A command
+// tagged with a sequence number is sent and the lines are skipped up to its echo and its
+// final result. If the echo is off, the lines are skipped up to the ERROR it's replied with,
+// so only a stale ERROR could be left. The caller must hold the command port lock.
+func (d *Device) resync() error {
+	tag := fmt.Sprintf("%s%d", syncCmd, atomic.AddUint32(&d.syncSeq, 1))
+	return d.withTimeout(0, func() error {
+		if _, err := d.cmdPort.Write([]byte(tag + Sep)); err != nil {
+			return err
+		}
+		var echoed bool
+		for {
+			line, err := d.cmdReader.ReadString('\r')
+			if err != nil {
+				return err
+			}
+			text := strings.TrimSpace(line)
+			switch {
+			case text == tag:
+				echoed = true
+			case echoed && FinalResults.Resolve(text) != UnknownStringOpt,
+				!echoed && FinalResults.Resolve(text) == FinalResults.Error:
+				return nil
+			}
+		}
+	})
+}
+
+// resetFailures drops the failures caused by the broken connection.
+func (d *Device) resetFailures() {
+	select {
+	case <-d.failures:
+	default:
+	}
+}
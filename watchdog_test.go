@@ -0,0 +1,74 @@
+package at
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdogRecover(t *testing.T) {
+	t.Parallel()
+
+	td := &testDialer{}
+	dev := &Device{
+		CommandPort: "cmd",
+		NotifyPort:  "notify",
+		Dial:        td.Dial,
+		Timeout:     time.Second,
+		Watchdog:    &Watchdog{RetryInterval: time.Millisecond},
+	}
+	require.NoError(t, dev.Open())
+	require.NoError(t, dev.Init(&noopProfile{}))
+	defer dev.Close()
+	go dev.Watch()
+
+	td.urc(0).Close()
+	select {
+	case <-dev.Reconnected():
+	case <-time.After(time.Second):
+		t.Fatal("device was not reconnected")
+	}
+	_, err := dev.Send(NoopCmd)
+	assert.NoError(t, err)
+}
+
+func TestResync(t *testing.T) {
+	t.Parallel()
+
+	for name, echo := range map[string]bool{"echo": true, "no echo": false} {
+		echo := echo
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, modem := net.Pipe()
+			defer modem.Close()
+			go func() {
+				line, err := bufio.NewReader(modem).ReadString('\r')
+				if err != nil {
+					return
+				}
+				// the replies to the commands sent before the reconnect precede the echo
+				reply := "+CSQ: 20,99" + Sep + Sep + "OK" + Sep
+				if echo {
+					reply += strings.TrimSpace(line) + Sep
+				}
+				io.WriteString(modem, reply+"ERROR"+Sep)
+				serveModem(modem, map[string]string{"AT+GMM": "E173" + Sep + Sep + "OK"})
+			}()
+			dev := &Device{Timeout: time.Second}
+			require.NoError(t, dev.Attach(cmd, nil))
+			defer cmd.Close()
+
+			require.NoError(t, dev.resync())
+			reply, err := dev.send("AT+GMM")
+			require.NoError(t, err)
+			assert.Equal(t, "E173", reply)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWebSocket is returned when the WebSocket handshake fails or the peer breaks the protocol.
+var ErrWebSocket = errors.New("at: websocket error")
+
+// wsMaxPayload limits the frames read from the peer, the AT traffic comes in short chunks.
+const wsMaxPayload = 1 << 20
+
+// wsGUID is appended to the key of the handshake, see RFC 6455 section 1.3.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes, see RFC 6455 section 5.2.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+// WebSocketConfig configures the connections to the modem ports exposed over WebSocket,
+// i.e. by a gateway serving the web consoles. The port name is a URL like
+// "wss://gateway/modems/1/cmd", the port's stream is carried in the binary frames.
+type WebSocketConfig struct {
+	// Timeout limits the connection time and the handshake, DefaultDialTimeout if zero.
+	Timeout time.Duration
+	// TLS configures the wss:// connections, i.e. with the client certificates.
+	TLS *tls.Config
+	// Header is sent with the handshake request, i.e. the Authorization.
+	Header http.Header
+}
+
+// Dialer returns a Dialer that connects to the WebSocket URL with the config applied.
+func (c WebSocketConfig) Dialer() Dialer {
+	return func(name string) (io.ReadWriteCloser, error) {
+		u, err := url.Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		timeout := c.Timeout
+		if timeout == 0 {
+			timeout = DefaultDialTimeout
+		}
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), map[string]string{"ws": "80", "wss": "443"}[u.Scheme])
+		}
+		d := &net.Dialer{Timeout: timeout}
+		var conn net.Conn
+		switch u.Scheme {
+		case "ws":
+			conn, err = d.Dial("tcp", host)
+		case "wss":
+			conn, err = tls.DialWithDialer(d, "tcp", host, c.TLS)
+		default:
+			return nil, fmt.Errorf("%w: unsupported scheme %q", ErrWebSocket, u.Scheme)
+		}
+		if err != nil {
+			return nil, err
+		}
+		conn.SetDeadline(time.Now().Add(timeout))
+		ws, err := wsHandshake(conn, u, c.Header)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.SetDeadline(time.Time{})
+		// the frames are read in background, so the read deadlines don't break them
+		return newDeadlinePort(ws), nil
+	}
+}
+
+// wsHandshake upgrades the connection to WebSocket as a client.
+func wsHandshake(conn net.Conn, u *url.URL, header http.Header) (*wsConn, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: u.RawQuery},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("%w: handshake status %s", ErrWebSocket, resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		return nil, fmt.Errorf("%w: handshake is not accepted", ErrWebSocket)
+	}
+	return &wsConn{conn: conn, r: br, client: true}, nil
+}
+
+// UpgradeWebSocket upgrades the request to WebSocket on the server side, i.e. by a gateway
+// exposing the modem ports to the WebSocketConfig's Dialer. The stream is carried in the
+// binary frames. A failed handshake is answered with 400 Bad Request.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (io.ReadWriteCloser, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || len(key) == 0 {
+		http.Error(w, "websocket handshake expected", http.StatusBadRequest)
+		return nil, fmt.Errorf("%w: not a handshake request", ErrWebSocket)
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket is not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("%w: connection can't be hijacked", ErrWebSocket)
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAccept(key) + "\r\n\r\n")
+	if err = rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, r: rw.Reader}, nil
+}
+
+// wsAccept returns the Sec-WebSocket-Accept value of the key.
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// wsConn carries a stream in the binary frames of a WebSocket connection.
+// The fragmented and the text frames are read as the parts of the stream.
+type wsConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	client bool
+
+	pending  []byte
+	writeMux sync.Mutex
+}
+
+func (c *wsConn) Read(b []byte) (int, error) {
+	for len(c.pending) == 0 {
+		opcode, payload, err := readWSFrame(c.r)
+		if err != nil {
+			return 0, err
+		}
+		switch opcode {
+		case wsContinuation, wsText, wsBinary:
+			c.pending = payload
+		case wsPing:
+			if err = c.writeFrame(wsPong, payload); err != nil {
+				return 0, err
+			}
+		case wsClose:
+			c.writeFrame(wsClose, nil)
+			return 0, io.EOF
+		}
+	}
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *wsConn) Write(b []byte) (int, error) {
+	if err := c.writeFrame(wsBinary, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *wsConn) Close() error {
+	c.writeFrame(wsClose, nil)
+	return c.conn.Close()
+}
+
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMux.Lock()
+	defer c.writeMux.Unlock()
+	return writeWSFrame(c.conn, opcode, payload, c.client)
+}
+
+// writeWSFrame writes a final frame, the clients mask their frames.
+func writeWSFrame(w io.Writer, opcode byte, payload []byte, masked bool) error {
+	header := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = append(header, byte(n>>8), byte(n))
+	default:
+		header[1] = 127
+		header = append(header, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if masked {
+		header[1] |= 0x80
+		mask := make([]byte, 4)
+		if _, err := rand.Read(mask); err != nil {
+			return err
+		}
+		header = append(header, mask...)
+		data := make([]byte, len(payload))
+		for i := range payload {
+			data[i] = payload[i] ^ mask[i%4]
+		}
+		payload = data
+	}
+	_, err := w.Write(append(header, payload...))
+	return err
+}
+
+// readWSFrame reads a frame and unmasks its payload.
+func readWSFrame(r io.Reader) (opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	opcode = header[0] & 0x0F
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err = io.ReadFull(r, ext); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err = io.ReadFull(r, ext); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if length > wsMaxPayload {
+		return 0, nil, fmt.Errorf("%w: frame of %d bytes", ErrWebSocket, length)
+	}
+	var mask []byte
+	if header[1]&0x80 != 0 {
+		mask = make([]byte, 4)
+		if _, err = io.ReadFull(r, mask); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if mask != nil {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
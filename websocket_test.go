@@ -0,0 +1,68 @@
+//go:build !at_minimal
+// +build !at_minimal
+
+package at
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveWebSocket upgrades the request and serves the emulated modem over it.
+func serveWebSocket(replies map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ws, err := UpgradeWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		ws.(*wsConn).writeFrame(wsPing, []byte("ping"))
+		serveModem(ws, replies)
+	}
+}
+
+func TestWebSocketDialer(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(serveWebSocket(map[string]string{
+		"AT+GMM": strings.Repeat("E173", 100) + Sep + Sep + "OK",
+	}))
+	defer srv.Close()
+	addr := "ws" + strings.TrimPrefix(srv.URL, "http") + "/modems/1/"
+
+	dev := &Device{
+		CommandPort: addr + "cmd",
+		NotifyPort:  addr + "notify",
+		Dial:        WebSocketConfig{Header: http.Header{"Authorization": {"Bearer token"}}}.Dialer(),
+		Timeout:     time.Second,
+	}
+	require.NoError(t, dev.Open())
+	defer dev.Close()
+	require.NoError(t, dev.Init(&noopProfile{}))
+	reply, err := dev.Send("AT+GMM")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("E173", 100), reply)
+
+	_, err = WebSocketConfig{}.Dialer()(addr)
+	assert.ErrorIs(t, err, ErrWebSocket)
+	_, err = WebSocketConfig{}.Dialer()(srv.URL)
+	assert.ErrorIs(t, err, ErrWebSocket)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "not a handshake")
+}
@@ -0,0 +1,109 @@
+package at
+
+import (
+	"strings"
+)
+
+// ZTEProfile is the DeviceProfile implementation for ZTE MF-series dongles, they don't
+// support the Huawei-specific AT^SYSINFO and AT^SYSCFG, so the system info is collected
+// from AT+CREG?, AT+CPIN? and AT+ZPAS?, the mode is selected with AT+ZSNT.
+// The network status changes are reported with +ZPAS.
+type ZTEProfile struct {
+	DefaultProfile
+}
+
+// DeviceMF190 returns an instance of DeviceProfile implementation for ZTE MF190.
+func DeviceMF190() DeviceProfile {
+	return &ZTEProfile{}
+}
+
+// DeviceMF823 returns an instance of DeviceProfile implementation for ZTE MF823.
+func DeviceMF823() DeviceProfile {
+	return &ZTEProfile{}
+}
+
+// zpasReport represents the network status: +ZPAS: "<network>","<domain>".
+type zpasReport struct {
+	ServiceState  Opt
+	ServiceDomain Opt
+	SystemMode    Opt
+	SystemSubmode Opt
+}
+
+// zpasNetworks maps the +ZPAS network names to the system mode and submode.
+var zpasNetworks = map[string][2]Opt{
+	"NO SERVICE":      {SystemModes.NoService, SystemSubmodes.NoService},
+	"LIMITED SERVICE": {SystemModes.NoService, SystemSubmodes.NoService},
+	"GSM":             {SystemModes.GsmGprs, SystemSubmodes.GSM},
+	"GPRS":            {SystemModes.GsmGprs, SystemSubmodes.GPRS},
+	"EDGE":            {SystemModes.GsmGprs, SystemSubmodes.EDGE},
+	"UMTS":            {SystemModes.WCDMA, SystemSubmodes.WCDMA},
+	"HSDPA":           {SystemModes.WCDMA, SystemSubmodes.HSDPA},
+	"HSUPA":           {SystemModes.WCDMA, SystemSubmodes.HSUPA},
+	"HSPA":            {SystemModes.WCDMA, SystemSubmodes.HsdpaHsupa},
+	"HSPA+":           {SystemModes.WCDMA, SystemSubmodes.HspaPlus},
+	"LTE":             {SystemModes.LTE, UnknownOpt},
+}
+
+var zpasDomains = map[string]Opt{
+	"CS_ONLY": ServiceDomains.Restricted,
+	"PS_ONLY": ServiceDomains.Valid,
+	"CS_PS":   ServiceDomains.RestrictedRegional,
+	"CAMPED":  ServiceDomains.None,
+}
+
+func (z *zpasReport) Parse(str string) error {
+	fields := strings.Split(strings.TrimPrefix(str, "+ZPAS:"), ",")
+	network := strings.ToUpper(strings.Trim(strings.TrimSpace(fields[0]), `"`))
+	modes, ok := zpasNetworks[network]
+	if !ok {
+		return ErrParseReport
+	}
+	z.SystemMode, z.SystemSubmode = modes[0], modes[1]
+	switch network {
+	case "NO SERVICE":
+		z.ServiceState = ServiceStates.None
+	case "LIMITED SERVICE":
+		z.ServiceState = ServiceStates.Restricted
+	default:
+		z.ServiceState = ServiceStates.Valid
+	}
+	z.ServiceDomain = ServiceDomains.None
+	if len(fields) > 1 {
+		domain, ok := zpasDomains[strings.Trim(strings.TrimSpace(fields[1]), `"`)]
+		if !ok {
+			return ErrParseReport
+		}
+		z.ServiceDomain = domain
+	}
+	return nil
+}
+
+// SYSINFO collects the system info from AT+CREG?, AT+CPIN? and AT+ZPAS?.
+func (p *ZTEProfile) SYSINFO() (info *SystemInfoReport, err error) {
+	if info, err = p.registration(); err != nil {
+		return nil, err
+	}
+	info.SimState = p.simState()
+
+	reply, err := p.dev.Send(`AT+ZPAS?`)
+	if err != nil {
+		return nil, err
+	}
+	var zpas zpasReport
+	if err = zpas.Parse(reply); err != nil {
+		return nil, err
+	}
+	info.ServiceState = zpas.ServiceState
+	info.ServiceDomain = zpas.ServiceDomain
+	info.SystemMode = zpas.SystemMode
+	info.SystemSubmode = zpas.SystemSubmode
+	return info, nil
+}
+
+// SYSCFG sends AT+ZSNT=0,0,0 to the device, that enables the automatic mode selection.
+// The roaming and domain are not configurable on ZTE dongles.
+func (p *ZTEProfile) SYSCFG(roaming, cellular bool) (err error) {
+	_, err = p.dev.Send(`AT+ZSNT=0,0,0`)
+	return
+}
@@ -0,0 +1,47 @@
+package at
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZTEInit(t *testing.T) {
+	t.Parallel()
+
+	dev, urc := newProfileDevice(t, DeviceMF190(), map[string]string{
+		"AT+COPS=0,0":            "OK",
+		"AT+CREG?":               "+CREG: 0,1" + Sep + Sep + "OK",
+		"AT+CPIN?":               "+CPIN: READY" + Sep + Sep + "OK",
+		"AT+ZPAS?":               `+ZPAS: "HSDPA","CS_PS"` + Sep + Sep + "OK",
+		"AT+COPS?":               `+COPS: 0,0,"Beeline",2` + Sep + Sep + "OK",
+		"AT+GMM":                 "MF190" + Sep + Sep + "OK",
+		"AT+GSN":                 "351234567890123" + Sep + Sep + "OK",
+		"AT+CMGF=0":              "OK",
+		"AT+CPMS=?":              `+CPMS: ("SM","ME"),("SM","ME"),("SM","ME")` + Sep + Sep + "OK",
+		`AT+CPMS="SM","SM","SM"`: "+CPMS: 0,30,0,30,0,30" + Sep + Sep + "OK",
+		"AT+CNMI=1,1,0,2,0":      "OK",
+		"AT+CNMI?":               "+CNMI: 1,1,0,2,0" + Sep + Sep + "OK",
+		"AT+CLIP=1":              "OK",
+		"AT+CPMS?":               `+CPMS: "SM",0,20,"SM",0,20,"SM",0,20` + Sep + Sep + "OK",
+	})
+
+	assert.Equal(t, SystemModes.WCDMA, dev.State.SystemMode)
+	assert.Equal(t, SystemSubmodes.HSDPA, dev.State.SystemSubmode)
+	assert.Equal(t, ServiceDomains.RestrictedRegional, dev.State.ServiceDomain)
+	assert.Equal(t, SimStates.Valid, dev.State.SimState)
+
+	go dev.Watch()
+	_, err := io.WriteString(urc, Sep+`+ZPAS: "LTE","PS_ONLY"`+Sep)
+	require.NoError(t, err)
+	select {
+	case <-dev.StateUpdate():
+		assert.Equal(t, SystemModes.LTE, dev.State.SystemMode)
+		assert.Equal(t, ServiceDomains.Valid, dev.State.ServiceDomain)
+	case <-time.After(time.Second):
+		t.Fatal("no state update on +ZPAS")
+	}
+}